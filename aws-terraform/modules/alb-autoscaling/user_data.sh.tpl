@@ -111,7 +111,8 @@ SQS_QUEUE_URL=${sqs_queue_url}
 
 # JWT Configuration
 JWT_SECRET=${jwt_secret}
-JWT_EXPIRATION=24h
+JWT_EXPIRATION=15m
+REFRESH_TOKEN_EXPIRATION=720h
 
 # Application Configuration
 STORAGE_TYPE=s3
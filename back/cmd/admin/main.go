@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database"
+	"back/internal/database/models"
+	"back/internal/services"
+	"back/internal/workers"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Uso: admin <comando> [argumentos]
+
+Comandos:
+  dlq list [-limit N]    Lista las tareas de la dead-letter queue
+  dlq inspect <id>       Muestra una tarea de la dead-letter queue (payload y último error)
+  dlq redrive <id>       Vuelve a encolar la tarea con los intentos reiniciados
+  rankings reconcile     Reconstruye el caché de rankings de Redis desde la tabla votes
+  competitions create -name <nombre> [-description <texto>]
+                         Crea una competencia
+  rounds create -competition <id> -name <nombre> -phase <submissions|regional|national>
+                -starts <RFC 3339> -ends <RFC 3339> [-cities Bogotá,Cali] [-qualifying-round <id>] [-winners-per-city 3]
+                [-votes-per-user <n>]
+                         Agrega una ronda a la competencia
+  rounds close <id>      Cierra la ronda y guarda sus ganadores
+  users set-role <email> <player|voter|jury|admin>
+                         Cambia el rol del usuario y cierra sus sesiones
+`
+
+func main() {
+	// Intentar cargar .env si existe
+	_ = godotenv.Load()
+
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "dlq":
+		err = withQueue(cfg, func(queue workers.QueueClient) error {
+			return runDLQ(ctx, queue, os.Args[2], os.Args[3:])
+		})
+	case "rankings":
+		err = runRankings(ctx, cfg, os.Args[2])
+	case "users":
+		err = withDB(cfg, func(db *sql.DB) error {
+			return runUsers(db, cfg, os.Args[2], os.Args[3:])
+		})
+	case "competitions", "rounds":
+		err = withDB(cfg, func(db *sql.DB) error {
+			return runRounds(services.NewRoundService(db), os.Args[1]+" "+os.Args[2], os.Args[3:])
+		})
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func withQueue(cfg *config.Config, fn func(workers.QueueClient) error) error {
+	queue, err := workers.NewQueueClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer queue.Close()
+	return fn(queue)
+}
+
+func runDLQ(ctx context.Context, queue workers.QueueClient, command string, args []string) error {
+	switch command {
+	case "list":
+		fs := flag.NewFlagSet("dlq list", flag.ExitOnError)
+		limit := fs.Int("limit", 50, "cantidad máxima de tareas")
+		_ = fs.Parse(args)
+
+		deadLetters, err := queue.ListDeadLetters(ctx, *limit)
+		if err != nil {
+			return err
+		}
+		if len(deadLetters) == 0 {
+			fmt.Println("La dead-letter queue está vacía")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTIPO\tINTENTOS\tFALLÓ\tERROR")
+		for _, dl := range deadLetters {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", dl.ID, dl.TaskType, dl.Attempts, formatTime(dl.FailedAt), truncate(dl.LastError, 80))
+		}
+		return w.Flush()
+
+	case "inspect":
+		if len(args) != 1 {
+			return errors.New("dlq inspect requires a task id")
+		}
+		deadLetter, err := queue.GetDeadLetter(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(deadLetter, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+
+	case "redrive":
+		if len(args) != 1 {
+			return errors.New("dlq redrive requires a task id")
+		}
+		if err := queue.RedriveDeadLetter(ctx, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Tarea %s encolada nuevamente\n", args[0])
+		return nil
+	}
+
+	return fmt.Errorf("unknown dlq command %q\n\n%s", command, usage)
+}
+
+func withDB(cfg *config.Config, fn func(*sql.DB) error) error {
+	db, err := database.Connect(cfg.GetDatabaseDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(db)
+}
+
+func runRankings(ctx context.Context, cfg *config.Config, command string) error {
+	if command != "reconcile" {
+		return fmt.Errorf("unknown rankings command %q\n\n%s", command, usage)
+	}
+
+	return withDB(cfg, func(db *sql.DB) error {
+		cache := services.NewRankingCache(db, cfg)
+		defer cache.Close()
+
+		if err := cache.Reconcile(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Caché de rankings reconstruido")
+		return nil
+	})
+}
+
+func runUsers(db *sql.DB, cfg *config.Config, command string, args []string) error {
+	if command != "set-role" {
+		return fmt.Errorf("unknown users command %q\n\n%s", command, usage)
+	}
+	if len(args) != 2 {
+		return errors.New("users set-role requires an email and a role")
+	}
+	email, role := args[0], args[1]
+	switch role {
+	case models.RolePlayer, models.RoleVoter, models.RoleJury, models.RoleAdmin:
+	default:
+		return fmt.Errorf("invalid role %q", role)
+	}
+
+	authService := services.NewAuthService(db, cfg)
+	user, err := authService.GetUserByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %s not found", email)
+	}
+	if err != nil {
+		return err
+	}
+	if err := authService.UpdateUserRole(user.ID, role); err != nil {
+		return err
+	}
+	// Los access tokens llevan el rol: se cierran las sesiones para que no conserven el anterior
+	if _, err := services.NewSessionService(db, cfg).RevokeAll(user.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Usuario %d (%s) ahora tiene el rol %s\n", user.ID, user.Email, role)
+	return nil
+}
+
+func runRounds(roundService *services.RoundService, command string, args []string) error {
+	switch command {
+	case "competitions create":
+		fs := flag.NewFlagSet(command, flag.ExitOnError)
+		name := fs.String("name", "", "nombre de la competencia")
+		description := fs.String("description", "", "descripción")
+		_ = fs.Parse(args)
+
+		competition, err := roundService.CreateCompetition(models.CompetitionCreate{Name: *name, Description: *description})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Competencia %d creada\n", competition.ID)
+		return nil
+
+	case "rounds create":
+		fs := flag.NewFlagSet(command, flag.ExitOnError)
+		competitionID := fs.Int("competition", 0, "ID de la competencia")
+		name := fs.String("name", "", "nombre de la ronda")
+		phase := fs.String("phase", "", "submissions, regional o national")
+		starts := fs.String("starts", "", "inicio (RFC 3339)")
+		ends := fs.String("ends", "", "fin (RFC 3339)")
+		cities := fs.String("cities", "", "ciudades habilitadas separadas por coma (vacío = todas)")
+		qualifyingRound := fs.Int("qualifying-round", 0, "solo compiten los ganadores de esta ronda")
+		winnersPerCity := fs.Int("winners-per-city", 3, "ganadores por ciudad al cerrar la ronda")
+		votesPerUser := fs.Int("votes-per-user", 0, "votos por usuario en la ronda (0 = sin límite)")
+		_ = fs.Parse(args)
+
+		req := models.RoundCreate{Name: *name, Phase: *phase, WinnersPerCity: *winnersPerCity}
+		var err error
+		if req.StartsAt, err = time.Parse(time.RFC3339, *starts); err != nil {
+			return fmt.Errorf("invalid -starts: %w", err)
+		}
+		if req.EndsAt, err = time.Parse(time.RFC3339, *ends); err != nil {
+			return fmt.Errorf("invalid -ends: %w", err)
+		}
+		if *cities != "" {
+			req.EligibleCities = strings.Split(*cities, ",")
+		}
+		if *qualifyingRound != 0 {
+			req.QualifyingRoundID = qualifyingRound
+		}
+		if *votesPerUser != 0 {
+			req.VotesPerUser = votesPerUser
+		}
+
+		round, err := roundService.CreateRound(*competitionID, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Ronda %d creada (%s)\n", round.ID, round.Status)
+		return nil
+
+	case "rounds close":
+		if len(args) != 1 {
+			return errors.New("rounds close requires a round id")
+		}
+		roundID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid round id %q", args[0])
+		}
+		if err := roundService.CloseRound(roundID); err != nil {
+			return err
+		}
+		winners, err := roundService.GetWinners(roundID)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "POSICIÓN\tCIUDAD\tPOS. CIUDAD\tVOTOS\tVIDEO\tJUGADOR")
+		for _, winner := range winners {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%s\n", winner.GlobalPosition, winner.City, winner.CityPosition, winner.Votes, winner.VideoID, winner.Username)
+		}
+		return w.Flush()
+	}
+
+	return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"back/internal/api"
 	"back/internal/config"
 	"back/internal/database"
+	"back/internal/pipeline"
 	"back/internal/services"
+	"back/internal/services/mailer"
 	"back/internal/services/storage"
 	"back/internal/workers"
 
@@ -38,6 +42,11 @@ import (
 // @name Authorization
 // @description Ingresa 'Bearer ' seguido de tu JWT token
 
+// @securityDefinitions.apikey AdminKey
+// @in header
+// @name X-Admin-Key
+// @description Clave de administración (ADMIN_API_KEY)
+
 func main() {
 	// Intentar cargar .env si existe
 	_ = godotenv.Load()
@@ -59,7 +68,7 @@ func main() {
 	videoService := services.NewVideoService(db, cfg, fileStorage)
 
 	// Crear TaskQueue con soporte dual Redis/SQS
-	taskQueue, err := workers.NewTaskQueue(cfg)
+	taskQueue, err := workers.NewTaskQueue(cfg, db)
 	if err != nil {
 		log.Fatal("Failed to create task queue:", err)
 	}
@@ -69,15 +78,77 @@ func main() {
 	if os.Getenv("WORKER_MODE") == "true" {
 		log.Println("Starting in worker mode...")
 		log.Println("WARNING: WORKER_MODE in API is deprecated. Use cmd/worker/main.go instead")
-		worker := workers.NewVideoProcessor(taskQueue, db, videoService, fileStorage)
-		if err := worker.Start(context.Background()); err != nil {
+		videoPipeline, err := pipeline.Load(cfg)
+		if err != nil {
+			log.Fatal("Invalid processing pipeline:", err)
+		}
+		worker := workers.NewVideoProcessor(taskQueue, db, videoService, fileStorage, videoPipeline)
+		// Drenar las tareas en curso al recibir SIGINT/SIGTERM
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := worker.Start(ctx); err != nil {
 			log.Fatal("Worker error:", err)
 		}
 		return
 	}
 
+	// Eventos de video publicados por los workers y las demás réplicas (LISTEN/NOTIFY)
+	videoEvents, err := services.NewVideoEventBroker(cfg)
+	if err != nil {
+		log.Fatal("Failed to listen for video events:", err)
+	}
+	defer videoEvents.Close()
+
+	// Actualización periódica de la vista materializada de rankings
+	rankingRefresher := services.NewRankingRefresher(db, cfg)
+	rankingRefresher.Start()
+	defer rankingRefresher.Stop()
+
+	// Caché de rankings en Redis, reconstruido periódicamente desde la tabla votes
+	var rankingCache *services.RankingCache
+	if cfg.RankingCacheEnabled {
+		rankingCache = services.NewRankingCache(db, cfg)
+		rankingCache.Start()
+		defer rankingCache.Close()
+		defer rankingCache.Stop()
+	}
+	rankingService := services.NewRankingService(db, cfg, rankingCache)
+
+	// Ranking en vivo: los votos se avisan por LISTEN/NOTIFY y se agrupan por intervalo
+	rankingEvents, err := services.NewRankingEventBroker(cfg, rankingService)
+	if err != nil {
+		log.Fatal("Failed to listen for ranking events:", err)
+	}
+	defer rankingEvents.Close()
+
+	// Rondas de la competencia: las que terminan se cierran y guardan sus ganadores
+	roundService := services.NewRoundService(db)
+	roundService.Start()
+	defer roundService.Stop()
+
+	// Límites de votos y análisis periódico de votos sospechosos
+	voteFraud := services.NewVoteFraudService(db, cfg, rankingService)
+	voteFraud.Start()
+	defer voteFraud.Stop()
+
+	voteService := services.NewVoteService(db, rankingService, roundService, voteFraud)
+
+	// Sesiones con refresh token rotativo
+	sessionService := services.NewSessionService(db, cfg)
+
+	// Correos de verificación de cuenta y restablecimiento de contraseña (SMTP o archivos)
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+	}
+	accountService := services.NewAccountService(db, cfg, mail, sessionService, fileStorage, rankingService)
+
+	// Cola de moderación de videos (VIDEO_MODERATION_ENABLED) y denuncias de usuarios
+	moderationService := services.NewModerationService(db, videoService, rankingService)
+	reportService := services.NewReportService(db, cfg, moderationService, rankingService)
+
 	// Configurar rutas de la API
-	router := api.SetupRoutes(db, cfg, taskQueue, videoService)
+	router := api.SetupRoutes(db, cfg, taskQueue, videoService, fileStorage, videoEvents, rankingService, roundService, voteFraud, voteService, sessionService, accountService, moderationService, reportService, rankingEvents)
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := router.Run(":" + cfg.Port); err != nil {
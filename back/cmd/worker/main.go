@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 
 	"back/internal/config"
 	"back/internal/database"
+	"back/internal/pipeline"
 	"back/internal/services"
 	"back/internal/services/storage"
 	"back/internal/workers"
@@ -27,6 +29,8 @@ func main() {
 	log.Printf("  - Queue Type: %s", cfg.QueueType)
 	log.Printf("  - Storage Type: %s", cfg.StorageType)
 	log.Printf("  - Worker Concurrency: %d", cfg.WorkerConcurrency)
+	log.Printf("  - Drain Timeout: %s", cfg.WorkerDrainTimeout)
+	log.Printf("  - Task Timeout: %s", cfg.WorkerTaskTimeout)
 
 	// Conectar a la base de datos
 	db, err := database.Connect(cfg.GetDatabaseDSN())
@@ -44,14 +48,21 @@ func main() {
 	videoService := services.NewVideoService(db, cfg, fileStorage)
 
 	// Crear TaskQueue con soporte dual Redis/SQS
-	taskQueue, err := workers.NewTaskQueue(cfg)
+	taskQueue, err := workers.NewTaskQueue(cfg, db)
 	if err != nil {
 		log.Fatal("Failed to create task queue:", err)
 	}
 	defer taskQueue.Close()
 
+	// Cargar y validar el perfil de procesamiento antes de consumir tareas
+	videoPipeline, err := pipeline.Load(cfg)
+	if err != nil {
+		log.Fatal("Invalid processing pipeline:", err)
+	}
+	log.Printf("  - Pipeline: %s (%s)", videoPipeline.Name(), videoPipeline.Describe())
+
 	// Crear el procesador de videos
-	worker := workers.NewVideoProcessor(taskQueue, db, videoService, fileStorage)
+	worker := workers.NewVideoProcessor(taskQueue, db, videoService, fileStorage, videoPipeline)
 
 	// Context con cancelación para shutdown graceful
 	ctx, cancel := context.WithCancel(context.Background())
@@ -63,17 +74,14 @@ func main() {
 
 	go func() {
 		<-sigChan
-		log.Println("Received shutdown signal, stopping worker...")
+		log.Println("Received shutdown signal, draining in-flight videos...")
 		cancel()
 	}()
 
-	// Iniciar el worker (bloqueante)
+	// Iniciar el worker (bloqueante hasta terminar de drenar)
 	log.Println("Starting video processing worker...")
-	if err := worker.Start(ctx); err != nil {
-		if err == context.Canceled {
-			log.Println("Worker stopped gracefully")
-		} else {
-			log.Fatal("Worker error:", err)
-		}
+	if err := worker.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal("Worker error:", err)
 	}
+	log.Println("Worker stopped gracefully")
 }
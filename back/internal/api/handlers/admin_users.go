@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"back/internal/config"
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// AdminUsersHandler permite consultar los usuarios y cambiar su rol
+type AdminUsersHandler struct {
+	validator   *validator.Validate
+	authService *services.AuthService
+	sessions    *services.SessionService
+}
+
+// NewAdminUsersHandler crea el handler de administración de usuarios
+func NewAdminUsersHandler(db *sql.DB, cfg *config.Config, sessions *services.SessionService) *AdminUsersHandler {
+	return &AdminUsersHandler{
+		validator:   validator.New(),
+		authService: services.NewAuthService(db, cfg),
+		sessions:    sessions,
+	}
+}
+
+// ListUsers devuelve los usuarios registrados
+// @Summary Listar usuarios
+// @Description Lista los usuarios, los más recientes primero, con filtros opcionales por rol y correo
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param role query string false "Solo los usuarios con este rol (player, voter, jury o admin)"
+// @Param email query string false "Solo los usuarios cuyo correo contiene este texto"
+// @Param page query int false "Número de página" default(1)
+// @Param limit query int false "Elementos por página" default(50)
+// @Success 200 {array} models.User
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/users [get]
+func (h *AdminUsersHandler) ListUsers(c *gin.Context) {
+	page := getRankingIntParam(c, "page", 1)
+	limit := getRankingIntParam(c, "limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	users, err := h.authService.ListUsers(c.Query("role"), c.Query("email"), limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// UpdateUserRole cambia el rol de un usuario
+// @Summary Cambiar rol de usuario
+// @Description Asigna el rol player, voter, jury o admin a un usuario. Cierra sus sesiones para que el nuevo rol aplique de inmediato
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param user_id path int true "ID del usuario"
+// @Param role body models.RoleUpdate true "Nuevo rol"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/users/{user_id}/role [put]
+func (h *AdminUsersHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid user ID",
+		})
+		return
+	}
+
+	var req models.RoleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.UpdateUserRole(userID, req.Role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to update role",
+		})
+		return
+	}
+
+	// Los access tokens llevan el rol: se cierran las sesiones para que no conserven el anterior
+	if _, err := h.sessions.RevokeAll(userID); err != nil {
+		log.Printf("Warning: failed to revoke sessions of user %d after role change: %v", userID, err)
+	}
+
+	user, err := h.authService.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// AdminVideosHandler permite revisar los videos que esperan moderación y los denunciados
+type AdminVideosHandler struct {
+	validator  *validator.Validate
+	moderation *services.ModerationService
+	reports    *services.ReportService
+}
+
+// NewAdminVideosHandler crea el handler de moderación de videos
+func NewAdminVideosHandler(moderation *services.ModerationService, reports *services.ReportService) *AdminVideosHandler {
+	return &AdminVideosHandler{
+		validator:  validator.New(),
+		moderation: moderation,
+		reports:    reports,
+	}
+}
+
+// ListPendingVideos devuelve la cola de moderación
+// @Summary Videos pendientes de revisión
+// @Description Lista los videos procesados que esperan aprobación (VIDEO_MODERATION_ENABLED), primero los que llevan más tiempo esperando. Incluye URLs firmadas del video, la miniatura y la vista previa
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param page query int false "Número de página" default(1)
+// @Param limit query int false "Elementos por página" default(20)
+// @Success 200 {array} models.PendingVideo
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/videos/pending [get]
+func (h *AdminVideosHandler) ListPendingVideos(c *gin.Context) {
+	page := getRankingIntParam(c, "page", 1)
+	limit := getRankingIntParam(c, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	videos, err := h.moderation.ListPending(limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve pending videos",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, videos)
+}
+
+// ApproveVideo publica un video
+// @Summary Aprobar video
+// @Description Aprueba un video pendiente (o uno rechazado antes): queda publicado si se subió como público y puede recibir votos
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param video_id path string true "ID del video"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/videos/{video_id}/approve [post]
+func (h *AdminVideosHandler) ApproveVideo(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	if err := h.moderation.Approve(videoID, reviewerID(c)); err != nil {
+		h.reviewError(c, err, "Failed to approve video")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Video approved",
+	})
+}
+
+// RejectVideo rechaza un video
+// @Summary Rechazar video
+// @Description Rechaza un video pendiente o ya publicado: deja de verse en el listado público, los rankings y las votaciones. El motivo se muestra a quien lo subió
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param video_id path string true "ID del video"
+// @Param rejection body models.VideoRejection true "Motivo del rechazo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/videos/{video_id}/reject [post]
+func (h *AdminVideosHandler) RejectVideo(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	var req models.VideoRejection
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.moderation.Reject(videoID, reviewerID(c), req.Reason); err != nil {
+		h.reviewError(c, err, "Failed to reject video")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Video rejected",
+	})
+}
+
+// ListReportedVideos devuelve los videos con denuncias sin resolver
+// @Summary Videos denunciados
+// @Description Lista los videos con denuncias sin resolver, primero los más denunciados, con cada denuncia, el conteo por motivo y una URL firmada del video. hidden_at indica que el video se ocultó al llegar a VIDEO_REPORT_THRESHOLD
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param page query int false "Número de página" default(1)
+// @Param limit query int false "Elementos por página" default(20)
+// @Success 200 {array} models.ReportedVideo
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/reports [get]
+func (h *AdminVideosHandler) ListReportedVideos(c *gin.Context) {
+	page := getRankingIntParam(c, "page", 1)
+	limit := getRankingIntParam(c, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	videos, err := h.reports.ListReported(limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve reported videos",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, videos)
+}
+
+// ResolveReports resuelve las denuncias de un video
+// @Summary Resolver denuncias
+// @Description Resuelve las denuncias sin resolver de un video. dismiss las descarta y vuelve a publicar el video si se había ocultado; remove rechaza el video con el motivo indicado, que se muestra a quien lo subió
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param video_id path string true "ID del video"
+// @Param resolution body models.ReportResolution true "Acción (dismiss o remove) y motivo del rechazo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/reports/{video_id}/resolve [post]
+func (h *AdminVideosHandler) ResolveReports(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	var req models.ReportResolution
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.reports.Resolve(videoID, reviewerID(c), req); err != nil {
+		if errors.Is(err, services.ErrNoOpenReports) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Video has no open reports",
+			})
+			return
+		}
+		h.reviewError(c, err, "Failed to resolve reports")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Reports resolved",
+	})
+}
+
+func (h *AdminVideosHandler) reviewError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrVideoNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "Video not found",
+		})
+	case errors.Is(err, services.ErrVideoNotReviewable):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Error: "Video cannot be reviewed in its current status",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: message,
+		})
+	}
+}
+
+// reviewerID retorna el usuario que revisa; nil si se autenticó con el header X-Admin-Key
+func reviewerID(c *gin.Context) *int {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+	id := int(userID.(int64))
+	return &id
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminVotesHandler permite revisar los votos marcados como sospechosos
+type AdminVotesHandler struct {
+	voteFraud *services.VoteFraudService
+}
+
+// NewAdminVotesHandler crea el handler de revisión de votos
+func NewAdminVotesHandler(voteFraud *services.VoteFraudService) *AdminVotesHandler {
+	return &AdminVotesHandler{voteFraud: voteFraud}
+}
+
+// ListFlaggedVotes devuelve los votos marcados pendientes de revisión
+// @Summary Votos sospechosos
+// @Description Lista los votos marcados por el análisis de fraude (cuentas recientes o IP compartida) que aún no se revisaron
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param video_id query string false "Solo los votos de este video"
+// @Param limit query int false "Cantidad máxima de votos" default(100)
+// @Success 200 {array} models.FlaggedVote
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/votes/flagged [get]
+func (h *AdminVotesHandler) ListFlaggedVotes(c *gin.Context) {
+	var videoID *uuid.UUID
+	if value := c.Query("video_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "Invalid video ID format",
+			})
+			return
+		}
+		videoID = &id
+	}
+
+	limit := getRankingIntParam(c, "limit", 100)
+	if limit < 1 || limit > 1000 {
+		limit = 100
+	}
+
+	votes, err := h.voteFraud.ListFlagged(videoID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve flagged votes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, votes)
+}
+
+// VoidVotes anula votos marcados y recalcula los votos de los videos afectados
+// @Summary Anular votos sospechosos
+// @Description Anula los votos marcados indicados por ID, o todos los pendientes de un video. Los votos anulados dejan de contar en el ranking general y en el de la ronda
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param review body models.VoteReview true "Votos a anular"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/votes/void [post]
+func (h *AdminVotesHandler) VoidVotes(c *gin.Context) {
+	var req models.VoteReview
+	if err := c.ShouldBindJSON(&req); err != nil || (len(req.VoteIDs) == 0 && req.VideoID == nil) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "vote_ids or video_id is required",
+		})
+		return
+	}
+
+	voided, err := h.voteFraud.VoidVotes(req.VoteIDs, req.VideoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to void votes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Votes voided",
+		Data:    gin.H{"voided": voided},
+	})
+}
+
+// DismissVotes da por legítimos votos marcados
+// @Summary Descartar votos sospechosos
+// @Description Marca como revisados votos sospechosos que resultaron legítimos: salen de la lista y el análisis no vuelve a marcarlos
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param review body models.VoteReview true "Votos a descartar (vote_ids)"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/votes/dismiss [post]
+func (h *AdminVotesHandler) DismissVotes(c *gin.Context) {
+	var req models.VoteReview
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.VoteIDs) == 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "vote_ids is required",
+		})
+		return
+	}
+
+	dismissed, err := h.voteFraud.DismissFlags(req.VoteIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to dismiss votes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Votes dismissed",
+		Data:    gin.H{"dismissed": dismissed},
+	})
+}
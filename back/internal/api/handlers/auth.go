@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 
 	"back/internal/config"
@@ -14,24 +17,30 @@ import (
 )
 
 type AuthHandler struct {
-	db          *sql.DB
-	config      *config.Config
-	validator   *validator.Validate
-	authService *services.AuthService
+	db             *sql.DB
+	config         *config.Config
+	validator      *validator.Validate
+	authService    *services.AuthService
+	sessions       *services.SessionService
+	accounts       *services.AccountService
+	rankingService *services.RankingService
 }
 
-func NewAuthHandler(db *sql.DB, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(db *sql.DB, cfg *config.Config, sessions *services.SessionService, accounts *services.AccountService, rankingService *services.RankingService) *AuthHandler {
 	return &AuthHandler{
-		db:          db,
-		config:      cfg,
-		validator:   validator.New(),
-		authService: services.NewAuthService(db, cfg),
+		db:             db,
+		config:         cfg,
+		validator:      validator.New(),
+		authService:    services.NewAuthService(db, cfg),
+		sessions:       sessions,
+		accounts:       accounts,
+		rankingService: rankingService,
 	}
 }
 
 // Signup maneja el registro de nuevos usuarios
 // @Summary Registrar nuevo usuario
-// @Description Registra un nuevo usuario en la plataforma ANB Rising Stars
+// @Description Registra un nuevo usuario en la plataforma ANB Rising Stars y le envía el enlace para verificar su correo
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -96,6 +105,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		PasswordHash: hashedPassword,
 		City:         req.City,
 		Country:      req.Country,
+		Role:         req.Role,
 		//CreatedAt:    time.Now(), fecha tomada por BD
 		//UpdatedAt:    time.Now(),
 	}
@@ -107,6 +117,11 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
+	// El registro no falla si el correo no sale: el usuario puede pedir el reenvío
+	if err := h.accounts.SendVerification(c.Request.Context(), user); err != nil {
+		log.Printf("Warning: failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Message: "User created successfully",
 	})
@@ -114,7 +129,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 
 // Login maneja la autenticación de usuarios
 // @Summary Autenticar usuario
-// @Description Autentica un usuario existente y devuelve un token JWT
+// @Description Autentica un usuario existente e inicia una sesión: devuelve un access token JWT de corta duración y un refresh token para renovarlo
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -159,8 +174,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generar JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, h.config.JWTSecret, h.config.JWTExpiration)
+	// Iniciar sesión y generar los tokens
+	response, err := h.sessions.Create(user, sessionOrigin(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to generate token",
@@ -168,10 +183,48 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response := models.LoginResponse{
-		AccessToken: token,
-		TokenType:   "Bearer",
-		ExpiresIn:   int(h.config.JWTExpiration.Seconds()),
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh rota el refresh token y emite un access token nuevo
+// @Summary Renovar tokens
+// @Description Canjea el refresh token por un access token nuevo y un refresh token nuevo; el anterior deja de servir. Reutilizar un refresh token ya rotado revoca la sesión
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshRequest true "Refresh token vigente"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "refresh_token is required",
+		})
+		return
+	}
+
+	response, err := h.sessions.Refresh(req.RefreshToken, sessionOrigin(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Error: "Refresh token already used, session revoked",
+			})
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Error: "Invalid or expired refresh token",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to refresh token",
+			})
+		}
+		return
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -208,19 +261,553 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// UpdateProfile actualiza el perfil del usuario autenticado
+// @Summary Actualizar perfil de usuario
+// @Description Actualiza el nombre, apellido, ciudad y país del usuario autenticado. El correo no se puede cambiar
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body models.ProfileUpdate true "Datos del perfil"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/profile [put]
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ProfileUpdate
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.authService.GetUserByID(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "User not found",
+		})
+		return
+	}
+
+	// Los rankings muestran el nombre y agrupan por la ciudad del autor
+	rankingChanged := user.FirstName != req.FirstName || user.LastName != req.LastName || user.City != req.City
+
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.City = req.City
+	user.Country = req.Country
+
+	if err := h.authService.UpdateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to update profile",
+		})
+		return
+	}
+
+	if rankingChanged {
+		if err := h.rankingService.RefreshUserVideos(c.Request.Context(), user.ID); err != nil {
+			log.Printf("Warning: failed to refresh rankings of user %d: %v", user.ID, err)
+		}
+	}
+
+	// Retornar el perfil como quedó guardado (updated_at)
+	if updated, err := h.authService.GetUserByID(user.ID); err == nil {
+		user = updated
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword cambia la contraseña del usuario autenticado
+// @Summary Cambiar contraseña
+// @Description Cambia la contraseña del usuario autenticado. Requiere la contraseña actual; la nueva debe tener mayúsculas, minúsculas, dígitos y símbolos y no ser una contraseña común. Cierra las demás sesiones del usuario
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body models.PasswordChange true "Contraseña actual y nueva contraseña"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/change-password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.PasswordChange
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Password1 != req.Password2 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Passwords do not match",
+		})
+		return
+	}
+
+	// Validar la fortaleza de la nueva contraseña
+	if err := utils.ValidatePassword(req.Password1); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Weak password: " + err.Error(),
+		})
+		return
+	}
+	if utils.IsCommonPassword(req.Password1) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Password is too common",
+		})
+		return
+	}
+
+	user, err := h.authService.GetUserByID(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "User not found",
+		})
+		return
+	}
+
+	// Verificar la contraseña actual (403: un 401 haría que el cliente intente renovar la sesión)
+	if !utils.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Error: "Current password is incorrect",
+		})
+		return
+	}
+	if req.Password1 == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "New password must be different from the current one",
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Internal server error",
+		})
+		return
+	}
+
+	if err := h.authService.UpdateUserPassword(user.ID, hashedPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to change password",
+		})
+		return
+	}
+
+	// La sesión actual sigue abierta; las de otros dispositivos se cierran
+	revoked, err := h.sessions.RevokeOthers(user.ID, c.GetString("session_id"))
+	if err != nil {
+		log.Printf("Warning: failed to revoke other sessions of user %d after password change: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Password changed successfully",
+		Data:    gin.H{"revoked_sessions": revoked},
+	})
+}
+
+// DeleteAccount elimina la cuenta del usuario autenticado
+// @Summary Eliminar cuenta
+// @Description Elimina la cuenta del usuario autenticado y todos sus datos: borra del storage los archivos de sus videos y subidas, y luego sus videos, votos y sesiones. Requiere la contraseña. No se puede deshacer
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param confirmation body models.AccountDeletion true "Contraseña del usuario"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/account [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.AccountDeletion
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.Password == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "password is required",
+		})
+		return
+	}
+
+	user, err := h.authService.GetUserByID(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "User not found",
+		})
+		return
+	}
+
+	if !utils.CheckPassword(req.Password, user.PasswordHash) {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Error: "Incorrect password",
+		})
+		return
+	}
+
+	// Los archivos primero: el borrado en cascada de las filas pierde sus rutas
+	if err := h.accounts.PurgeUserFiles(c.Request.Context(), user.ID); err != nil {
+		log.Printf("Error purging files of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to delete account",
+		})
+		return
+	}
+
+	if err := h.accounts.DeleteAccount(c.Request.Context(), user.ID); err != nil {
+		log.Printf("Error deleting user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to delete account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Account deleted successfully",
+	})
+}
+
+// GetUserStats retorna las estadísticas del usuario autenticado
+// @Summary Obtener estadísticas del usuario
+// @Description Obtiene los videos subidos y procesados, los votos recibidos y los votos dados por el usuario autenticado
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /user/stats [get]
+func (h *AuthHandler) GetUserStats(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	stats, err := h.authService.GetUserStats(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to get user stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // Logout maneja el cierre de sesión
 // @Summary Cerrar sesión
-// @Description Cierra la sesión del usuario autenticado
+// @Description Cierra la sesión del usuario autenticado: su access token y su refresh token dejan de ser válidos
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.sessions.Revoke(c.GetString("session_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to log out",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Message: "Logged out successfully",
 	})
 }
+
+// LogoutAll cierra todas las sesiones del usuario
+// @Summary Cerrar sesión en todos los dispositivos
+// @Description Cierra todas las sesiones del usuario autenticado, incluida la actual
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	revoked, err := h.sessions.RevokeAll(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to log out",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Logged out from all devices",
+		Data:    gin.H{"revoked_sessions": revoked},
+	})
+}
+
+// SendVerificationEmail reenvía el enlace de verificación del correo
+// @Summary Reenviar verificación de correo
+// @Description Envía al correo del usuario autenticado un enlace de un solo uso para verificarlo. Se puede pedir como máximo uno por minuto
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/verify-email/send [post]
+func (h *AuthHandler) SendVerificationEmail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	user, err := h.authService.GetUserByID(int(userID.(int64)))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "User not found",
+		})
+		return
+	}
+
+	if err := h.accounts.SendVerification(c.Request.Context(), user); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Error: "Email already verified",
+			})
+		case errors.Is(err, services.ErrAccountTokenThrottled):
+			c.JSON(http.StatusTooManyRequests, models.APIResponse{
+				Error: "Verification email requested too recently, try again in a minute",
+			})
+		default:
+			log.Printf("Error sending verification email to user %d: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to send verification email",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// VerifyEmail verifica el correo con el token del enlace
+// @Summary Verificar correo
+// @Description Canjea el token del enlace de verificación. El token es de un solo uso y vence según EMAIL_VERIFICATION_TTL
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body models.TokenRequest true "Token recibido por correo"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.TokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "token is required",
+		})
+		return
+	}
+
+	if err := h.accounts.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "Invalid, expired or already used token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to verify email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// ForgotPassword envía el enlace para restablecer la contraseña
+// @Summary Solicitar restablecimiento de contraseña
+// @Description Envía al correo un enlace de un solo uso para elegir una nueva contraseña. Responde igual exista o no la cuenta
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body models.EmailRequest true "Correo de la cuenta"
+// @Success 202 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.EmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	// El envío va en segundo plano: el tiempo de respuesta tampoco debe revelar si la cuenta existe
+	go func(email string) {
+		if err := h.accounts.RequestPasswordReset(context.Background(), email); err != nil {
+			log.Printf("Error sending password reset email: %v", err)
+		}
+	}(req.Email)
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Message: "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword cambia la contraseña con el token del enlace
+// @Summary Restablecer contraseña
+// @Description Canjea el token del enlace por una nueva contraseña y cierra todas las sesiones del usuario. La nueva contraseña debe tener mayúsculas, minúsculas, dígitos y símbolos y no ser una contraseña común. El token es de un solo uso y vence según PASSWORD_RESET_TTL
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param reset body models.PasswordReset true "Token recibido por correo y nueva contraseña"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.PasswordReset
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Password1 != req.Password2 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Passwords do not match",
+		})
+		return
+	}
+
+	// Misma política de contraseñas que al cambiarla con la sesión iniciada
+	if err := utils.ValidatePassword(req.Password1); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Weak password: " + err.Error(),
+		})
+		return
+	}
+	if utils.IsCommonPassword(req.Password1) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Password is too common",
+		})
+		return
+	}
+
+	if err := h.accounts.ResetPassword(c.Request.Context(), req.Token, req.Password1); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "Invalid, expired or already used token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to reset password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Password reset successfully, please log in again",
+	})
+}
+
+func sessionOrigin(c *gin.Context) services.SessionOrigin {
+	return services.SessionOrigin{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
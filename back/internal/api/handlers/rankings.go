@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"back/internal/config"
 	"back/internal/database/models"
@@ -19,15 +23,21 @@ type RankingHandler struct {
 	config         *config.Config
 	videoService   services.VideoServiceInterface
 	rankingService *services.RankingService
+	roundService   *services.RoundService
+	voteService    *services.VoteService
+	rankingEvents  *services.RankingEventBroker
 }
 
 // NewRankingHandler crea una instancia del handler para inyectar dependencias
-func NewRankingHandler(db *sql.DB, cfg *config.Config, videoService services.VideoServiceInterface) *RankingHandler {
+func NewRankingHandler(db *sql.DB, cfg *config.Config, videoService services.VideoServiceInterface, rankingService *services.RankingService, roundService *services.RoundService, voteService *services.VoteService, rankingEvents *services.RankingEventBroker) *RankingHandler {
 	return &RankingHandler{
 		db:             db,
 		config:         cfg,
 		videoService:   videoService,
-		rankingService: services.NewRankingService(db, cfg),
+		rankingService: rankingService,
+		roundService:   roundService,
+		voteService:    voteService,
+		rankingEvents:  rankingEvents,
 	}
 }
 
@@ -49,6 +59,8 @@ func (h *RankingHandler) ListPublicVideos(c *gin.Context) {
 			v.original_filename,
 			v.original_url,
 			v.processed_url,
+			v.thumbnail_url,
+			v.preview_url,
 			v.status,
 			v.uploaded_at,
 			v.processed_at,
@@ -83,6 +95,8 @@ func (h *RankingHandler) ListPublicVideos(c *gin.Context) {
 			&video.OriginalFilename,
 			&video.OriginalURL,
 			&video.ProcessedURL,
+			&video.ThumbnailURL,
+			&video.PreviewURL,
 			&video.Status,
 			&video.UploadedAt,
 			&video.ProcessedAt,
@@ -101,9 +115,12 @@ func (h *RankingHandler) ListPublicVideos(c *gin.Context) {
 
 		// Generar URL pública (presignada para S3, relativa para local)
 		if video.ProcessedURL != nil {
+			video.FallbackURL = h.videoService.GenerateFallbackURL(video.ProcessedURL)
 			publicURL := h.videoService.GeneratePublicURL(video.ProcessedURL)
 			video.ProcessedURL = publicURL
 		}
+		video.ThumbnailURL = h.videoService.GenerateMediaURL(video.ThumbnailURL)
+		video.PreviewURL = h.videoService.GenerateMediaURL(video.PreviewURL)
 
 		videos = append(videos, video)
 	}
@@ -111,23 +128,78 @@ func (h *RankingHandler) ListPublicVideos(c *gin.Context) {
 	c.JSON(http.StatusOK, videos)
 }
 
+// GetHLSPlaylist sirve los playlists HLS de un video procesado con los segmentos firmados
+// @Summary Playlist HLS del video
+// @Description Retorna el playlist maestro o el de una variante ("<variante>/index.m3u8"). Las URLs de los segmentos vienen firmadas (S3) o servidas por Nginx (local). Los videos privados requieren el token de la URL que entrega la API a su dueño (vence en una hora) y responden 404 sin él
+// @Tags public
+// @Produce application/vnd.apple.mpegurl
+// @Param video_id path string true "ID del video"
+// @Param file path string true "Playlist (master.m3u8 o <variante>/index.m3u8)"
+// @Param token query string false "Token de acceso firmado (videos privados)"
+// @Success 200 {string} string "Playlist M3U8"
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /public/videos/{video_id}/hls/{file} [get]
+func (h *RankingHandler) GetHLSPlaylist(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	// Solo playlists: los segmentos se descargan directo del storage
+	name := strings.TrimPrefix(c.Param("file"), "/")
+	if !strings.HasSuffix(name, ".m3u8") || strings.Contains(name, "..") {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid playlist name",
+		})
+		return
+	}
+
+	playlist, err := h.videoService.GetHLSPlaylist(videoID.String(), name, c.Query("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrPlaylistNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Playlist not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to load playlist",
+		})
+		return
+	}
+
+	// Los segmentos firmados expiran, así que el playlist solo se cachea brevemente
+	c.Header("Cache-Control", "private, max-age=60")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", playlist)
+}
+
 // VoteVideo permite a un usuario votar por un video público
 // @Summary Votar por video
-// @Description Permite a un usuario autenticado votar por un video público
+// @Description Permite a un usuario autenticado votar por un video público. Si hay rondas de votación configuradas, el voto cuenta para la ronda abierta en la que compite el video. Con Idempotency-Key, un reintento del mismo voto responde 201 con el header Idempotent-Replayed en lugar de 409
 // @Tags public
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param video_id path string true "ID del video"
+// @Param X-Device-ID header string false "Identificador del dispositivo generado por el cliente"
+// @Param Idempotency-Key header string false "Clave única del voto generada por el cliente (máximo 255 caracteres)"
 // @Success 201 {object} models.APIResponse
+// @Header 201 {string} Idempotent-Replayed "true si el voto ya estaba registrado con la misma Idempotency-Key"
 // @Failure 400 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "No hay una ronda de votación abierta para el video o no quedan votos en la ronda"
 // @Failure 404 {object} models.APIResponse
 // @Failure 409 {object} models.APIResponse "Usuario ya votó por este video"
+// @Failure 422 {object} models.APIResponse "La Idempotency-Key ya se usó para votar por otro video"
+// @Failure 429 {object} models.APIResponse "Se superó el límite de votos del usuario, la IP o el dispositivo"
 // @Failure 500 {object} models.APIResponse
 // @Router /public/videos/{video_id}/vote [post]
 func (h *RankingHandler) VoteVideo(c *gin.Context) {
-	videoIDStr := c.Param("video_id")
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.APIResponse{
@@ -136,10 +208,8 @@ func (h *RankingHandler) VoteVideo(c *gin.Context) {
 		return
 	}
 
-	userIDInt := int(userID.(int64))
-
 	// Validar que el video_id sea un UUID válido
-	videoID, err := uuid.Parse(videoIDStr)
+	videoID, err := uuid.Parse(c.Param("video_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Error: "Invalid video ID format",
@@ -147,105 +217,151 @@ func (h *RankingHandler) VoteVideo(c *gin.Context) {
 		return
 	}
 
-	// Verificar que el video existe, está procesado y es público
-	var videoExists bool
-	var videoOwnerID int
-	checkVideoQuery := `
-		SELECT EXISTS(
-			SELECT 1 FROM videos 
-			WHERE id = $1 
-			  AND status = 'processed' 
-			  AND is_public = true
-		), COALESCE((SELECT user_id FROM videos WHERE id = $1), 0)`
-
-	err = h.db.QueryRow(checkVideoQuery, videoID).Scan(&videoExists, &videoOwnerID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: "Failed to verify video",
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > 255 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Idempotency-Key must be at most 255 characters",
 		})
 		return
 	}
 
-	if !videoExists {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Error: "Video not found or not available for voting",
-		})
-		return
+	// Origen del voto para los límites por usuario, IP y dispositivo
+	origin := services.VoteOrigin{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		DeviceID:  c.GetHeader("X-Device-ID"),
 	}
-
-	// Prevenir que un usuario vote por su propio video
-	if videoOwnerID == userIDInt {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Error: "You cannot vote for your own video",
-		})
-		return
+	if len(origin.DeviceID) > 64 {
+		origin.DeviceID = origin.DeviceID[:64]
 	}
 
-	// Verificar si el usuario ya votó por este video
-	var alreadyVoted bool
-	checkVoteQuery := `SELECT EXISTS(SELECT 1 FROM votes WHERE user_id = $1 AND video_id = $2)`
-	err = h.db.QueryRow(checkVoteQuery, userIDInt, videoID).Scan(&alreadyVoted)
+	result, err := h.voteService.Cast(c.Request.Context(), services.VoteRequest{
+		UserID:         int(userID.(int64)),
+		VideoID:        videoID,
+		Origin:         origin,
+		IdempotencyKey: idempotencyKey,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: "Failed to check existing vote",
-		})
+		var limitErr *services.VoteLimitError
+		switch {
+		case errors.Is(err, services.ErrVideoNotVotable):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Video not found or not available for voting",
+			})
+		case errors.Is(err, services.ErrOwnVideo):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "You cannot vote for your own video",
+			})
+		case errors.Is(err, services.ErrVotingClosed):
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Error: "Voting is closed",
+			})
+		case errors.Is(err, services.ErrVideoNotInRound):
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Error: "Video is not competing in the current round",
+			})
+		case errors.Is(err, services.ErrVoteBudgetExhausted):
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Error: "No votes left in this round, retract a vote to vote for another video",
+			})
+		case errors.Is(err, services.ErrAlreadyVoted):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Error: "You have already voted for this video",
+			})
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+				Error: "Idempotency-Key was already used to vote for another video",
+			})
+		case errors.As(err, &limitErr):
+			c.Header("Retry-After", strconv.Itoa(int(limitErr.RetryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, models.APIResponse{
+				Error: "Too many votes, try again later",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to register vote",
+			})
+		}
 		return
 	}
 
-	if alreadyVoted {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Error: "You have already voted for this video",
-		})
-		return
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
 	}
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Message: "Vote registered successfully",
+	})
+}
 
-	// Iniciar transacción para insertar voto y actualizar contador
-	tx, err := h.db.Begin()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: "Failed to start transaction",
+// RetractVote retira el voto del usuario por un video
+// @Summary Retirar voto
+// @Description Retira el voto del usuario autenticado por el video en la ronda abierta (o su voto libre si no hay rondas de votación), liberando el cupo para votar por otro video. Los votos de rondas cerradas y los anulados no se pueden retirar
+// @Tags public
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param video_id path string true "ID del video"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /public/videos/{video_id}/vote [delete]
+func (h *RankingHandler) RetractVote(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
 		})
 		return
 	}
-	defer tx.Rollback()
 
-	// Insertar el voto
-	insertVoteQuery := `INSERT INTO votes (user_id, video_id, created_at) VALUES ($1, $2, NOW())`
-	_, err = tx.Exec(insertVoteQuery, userIDInt, videoID)
+	videoID, err := uuid.Parse(c.Param("video_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: "Failed to register vote",
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
 		})
 		return
 	}
 
-	// El contador de votos se actualiza automáticamente por el trigger de la base de datos
-	// No necesitamos actualizarlo manualmente aquí para evitar doble conteo
-
-	// Confirmar transacción
-	err = tx.Commit()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: "Failed to commit vote",
-		})
+	if err := h.voteService.Retract(c.Request.Context(), int(userID.(int64)), videoID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrVotingClosed):
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Error: "Voting is closed",
+			})
+		case errors.Is(err, services.ErrVoteNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Vote not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to retract vote",
+			})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
-		Message: "Vote registered successfully",
+		Message: "Vote retracted successfully",
 	})
 }
 
 // GetRankings obtiene el ranking de jugadores
 // @Summary Obtener rankings
-// @Description Obtiene el ranking de videos con paginación y filtro opcional por ciudad
+// @Description Obtiene el ranking de videos con paginación y filtro opcional por ciudad. Con round, el ranking es el de los votos de esa ronda
 // @Tags public
 // @Accept json
 // @Produce json
 // @Param page query int false "Número de página" default(1)
 // @Param limit query int false "Límite de resultados por página" default(50)
-// @Param city query string false "Filtrar por ciudad"
+// @Param city query string false "Filtrar por ciudad (nombre exacto); las posiciones son dentro de la ciudad"
+// @Param round query int false "ID de la ronda"
 // @Success 200 {array} models.Video
+// @Header 200 {string} X-Rankings-Refreshed-At "Fecha (RFC 3339) de la última actualización del ranking"
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /public/rankings [get]
 func (h *RankingHandler) GetRankings(c *gin.Context) {
@@ -260,29 +376,57 @@ func (h *RankingHandler) GetRankings(c *gin.Context) {
 		limit = 50
 	}
 
-	rankings, err := h.rankingService.GetRankings(page, limit, city)
+	var rankings []models.RankingEntry
+	var refreshedAt time.Time
+	var err error
+	if round := c.Query("round"); round != "" {
+		roundID, convErr := strconv.Atoi(round)
+		if convErr != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "Invalid round ID",
+			})
+			return
+		}
+		// Los votos de la ronda se cuentan al registrarse: el ranking está al día
+		rankings, err = h.roundService.GetRoundRankings(roundID, city, limit, (page-1)*limit)
+		refreshedAt = time.Now()
+		if errors.Is(err, services.ErrRoundNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Round not found",
+			})
+			return
+		}
+	} else {
+		rankings, refreshedAt, err = h.rankingService.GetRankings(page, limit, city)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to retrieve rankings",
 		})
 		return
 	}
+	setRefreshedAtHeader(c, refreshedAt)
 
 	// Generar URLs públicas para todos los videos en el ranking
 	for i := range rankings {
-		if rankings[i].VideoURL != "" {
-			videoURL := rankings[i].VideoURL
-			publicURL := h.videoService.GeneratePublicURL(&videoURL)
-			if publicURL != nil {
-				rankings[i].VideoURL = *publicURL
-			}
-		}
+		h.setRankingURLs(&rankings[i])
 	}
 
 	c.JSON(http.StatusOK, rankings)
 }
 
 // GetTopRankings obtiene el top de rankings (más eficiente, con caché)
+// @Summary Top del ranking
+// @Description Obtiene las primeras posiciones del ranking, global o de una ciudad. Se sirve desde el caché de Redis y, si no está disponible, desde la vista video_rankings
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param limit query int false "Cantidad de posiciones (máximo 50)" default(10)
+// @Param city query string false "Filtrar por ciudad (nombre exacto); las posiciones son dentro de la ciudad"
+// @Success 200 {array} models.RankingEntry
+// @Header 200 {string} X-Rankings-Refreshed-At "Fecha (RFC 3339) de la última actualización del ranking"
+// @Failure 500 {object} models.APIResponse
+// @Router /public/rankings/top [get]
 func (h *RankingHandler) GetTopRankings(c *gin.Context) {
 	limit := getRankingIntParam(c, "limit", 10)
 	city := c.Query("city")
@@ -291,36 +435,116 @@ func (h *RankingHandler) GetTopRankings(c *gin.Context) {
 		limit = 10
 	}
 
-	rankings, err := h.rankingService.GetTopRankings(limit, city)
+	rankings, refreshedAt, err := h.rankingService.GetTopRankings(limit, city)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to retrieve top rankings",
 		})
 		return
 	}
+	setRefreshedAtHeader(c, refreshedAt)
 
 	// Generar URLs públicas para todos los videos en el ranking
 	for i := range rankings {
-		if rankings[i].VideoURL != "" {
-			videoURL := rankings[i].VideoURL
-			publicURL := h.videoService.GeneratePublicURL(&videoURL)
-			if publicURL != nil {
-				rankings[i].VideoURL = *publicURL
+		h.setRankingURLs(&rankings[i])
+	}
+
+	c.JSON(http.StatusOK, rankings)
+}
+
+// StreamRankings envía por Server-Sent Events el ranking y sus cambios a medida que llegan votos
+// @Summary Ranking en vivo
+// @Description Stream SSE del ranking (top 50). Primero envía un evento "snapshot" con el ranking completo y luego eventos "update" con las entradas que cambiaron de posición o de votos y las que salieron. Los votos se agrupan: como mucho un "update" por RANKING_UPDATE_INTERVAL.
+// @Tags public
+// @Produce text/event-stream
+// @Param city query string false "Filtrar por ciudad"
+// @Success 200 {object} models.RankingUpdate
+// @Failure 500 {object} models.APIResponse
+// @Router /public/rankings/stream [get]
+func (h *RankingHandler) StreamRankings(c *gin.Context) {
+	snapshot, updates, unsubscribe, err := h.rankingEvents.Subscribe(c.Query("city"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve rankings",
+		})
+		return
+	}
+	defer unsubscribe()
+
+	// El ranking compartido no se modifica: las URLs firmadas van en una copia
+	rankings := make([]models.RankingEntry, len(snapshot))
+	copy(rankings, snapshot)
+	for i := range rankings {
+		h.setRankingURLs(&rankings[i])
+	}
+
+	startSSE(c)
+	c.SSEvent("snapshot", rankings)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case update, ok := <-updates:
+			// Canal cerrado: el cliente no alcanzó a leer las actualizaciones y debe reconectar
+			if !ok {
+				return false
+			}
+			changes := make([]models.RankingChange, len(update.Changes))
+			copy(changes, update.Changes)
+			for i := range changes {
+				h.setRankingURLs(&changes[i].RankingEntry)
 			}
+			update.Changes = changes
+			c.SSEvent("update", update)
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": ping\n\n")
 		}
+		return true
+	})
+}
+
+// setRefreshedAtHeader informa qué tan actualizado está el ranking (el caché, a la hora de su último
+// cambio o reconciliación; la vista materializada, a la fecha de su última actualización)
+func setRefreshedAtHeader(c *gin.Context, refreshedAt time.Time) {
+	if refreshedAt.IsZero() {
+		return
 	}
+	c.Header("X-Rankings-Refreshed-At", refreshedAt.UTC().Format(time.RFC3339))
+}
 
-	c.JSON(http.StatusOK, rankings)
+// setRankingURLs reemplaza las rutas de storage de la entrada por URLs públicas o firmadas
+func (h *RankingHandler) setRankingURLs(entry *models.RankingEntry) {
+	if entry.VideoURL != "" {
+		videoURL := entry.VideoURL
+		if fallbackURL := h.videoService.GenerateFallbackURL(&videoURL); fallbackURL != nil {
+			entry.FallbackURL = *fallbackURL
+		}
+		publicURL := h.videoService.GeneratePublicURL(&videoURL)
+		if publicURL != nil {
+			entry.VideoURL = *publicURL
+		}
+	}
+	if thumbnailURL := h.videoService.GenerateMediaURL(&entry.ThumbnailURL); thumbnailURL != nil {
+		entry.ThumbnailURL = *thumbnailURL
+	}
+	if previewURL := h.videoService.GenerateMediaURL(&entry.PreviewURL); previewURL != nil {
+		entry.PreviewURL = *previewURL
+	}
 }
 
-// GetUserVotes obtiene los IDs de videos por los que el usuario ya votó
+// GetUserVotes obtiene los votos del usuario en las rondas abiertas y los votos que le quedan
 // @Summary Obtener votos del usuario
-// @Description Obtiene la lista de IDs de videos por los que el usuario autenticado ya ha votado
+// @Description Obtiene los votos (video, ronda y fecha) del usuario autenticado en las rondas abiertas (o fuera de rondas, si no hay rondas de votación configuradas) y los votos que le quedan en las rondas abiertas con cupo de votos
 // @Tags user
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} string "Lista de IDs de videos votados"
+// @Success 200 {object} models.UserVotes
 // @Failure 401 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /user/votes [get]
@@ -335,29 +559,15 @@ func (h *RankingHandler) GetUserVotes(c *gin.Context) {
 
 	userIDInt := int(userID.(int64))
 
-	query := `SELECT video_id FROM votes WHERE user_id = $1`
-	rows, err := h.db.Query(query, userIDInt)
+	votes, err := h.roundService.UserVotes(userIDInt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to retrieve user votes",
 		})
 		return
 	}
-	defer rows.Close()
-
-	var votedVideoIDs []string
-	for rows.Next() {
-		var videoID string
-		if err := rows.Scan(&videoID); err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Error: "Failed to scan vote data",
-			})
-			return
-		}
-		votedVideoIDs = append(votedVideoIDs, videoID)
-	}
 
-	c.JSON(http.StatusOK, votedVideoIDs)
+	c.JSON(http.StatusOK, votes)
 }
 
 func getRankingIntParam(c *gin.Context, key string, defaultValue int) int {
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"back/internal/config"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// concurrentRequests es la cantidad de peticiones simultáneas de cada prueba
+const concurrentRequests = 20
+
+// openTestDB abre la base de TEST_DATABASE_URL en un schema propio de la prueba con las
+// migraciones de db/ aplicadas. La prueba se omite si la variable no está definida.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping Postgres test")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	// lib/pq envía los parámetros desconocidos del DSN como parámetros de la sesión
+	searchPath := "search_path=" + schema + ",public"
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " " + searchPath
+	case strings.Contains(dsn, "?"):
+		dsn += "&" + searchPath
+	default:
+		dsn += "?" + searchPath
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	db.SetMaxOpenConns(concurrentRequests + 5)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "db", "*.up.sql"))
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("find migrations: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, migration := range migrations {
+		script, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("read %s: %v", migration, err)
+		}
+		if _, err := db.Exec(string(script)); err != nil {
+			t.Fatalf("apply %s: %v", filepath.Base(migration), err)
+		}
+	}
+	return db
+}
+
+// newVoteRouter registra la ruta de votos como en SetupRoutes; en lugar del AuthMiddleware, el
+// usuario autenticado es voterID
+func newVoteRouter(db *sql.DB, cfg *config.Config, voterID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	rankingService := services.NewRankingService(db, cfg, nil)
+	roundService := services.NewRoundService(db)
+	voteService := services.NewVoteService(db, rankingService, roundService, services.NewVoteFraudService(db, cfg, rankingService))
+	handler := NewRankingHandler(db, cfg, nil, rankingService, roundService, voteService, nil)
+
+	router := gin.New()
+	router.POST("/api/public/videos/:video_id/vote", func(c *gin.Context) {
+		c.Set("user_id", int64(voterID))
+	}, handler.VoteVideo)
+	return router
+}
+
+func createTestUser(t *testing.T, db *sql.DB, name string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO users (first_name, last_name, email, password_hash, city, country)
+		VALUES ($1, 'Test', $1 || '@example.com', 'x', 'Bogotá', 'Colombia')
+		RETURNING id`, name).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	return id
+}
+
+func createTestVideo(t *testing.T, db *sql.DB, ownerID int) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	err := db.QueryRow(`
+		INSERT INTO videos (user_id, title, original_filename, status, is_public, processed_at)
+		VALUES ($1, 'Video de prueba', 'video.mp4', 'processed', true, NOW())
+		RETURNING id`, ownerID).Scan(&id)
+	if err != nil {
+		t.Fatalf("create video: %v", err)
+	}
+	return id
+}
+
+func voteRequest(videoID uuid.UUID, idempotencyKey string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/public/videos/"+videoID.String()+"/vote", nil)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	return req
+}
+
+// serveConcurrently envía las peticiones a la vez y retorna la respuesta de cada una
+func serveConcurrently(router http.Handler, reqs []*http.Request) []*httptest.ResponseRecorder {
+	recorders := make([]*httptest.ResponseRecorder, len(reqs))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder, req *http.Request) {
+			defer wg.Done()
+			<-start
+			router.ServeHTTP(w, req)
+		}(recorders[i], req)
+	}
+	close(start)
+	wg.Wait()
+	return recorders
+}
+
+func TestVoteVideoConcurrentDuplicateVotes(t *testing.T) {
+	db := openTestDB(t)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+	video := createTestVideo(t, db, owner)
+	router := newVoteRouter(db, &config.Config{}, voter)
+
+	reqs := make([]*http.Request, concurrentRequests)
+	for i := range reqs {
+		reqs[i] = voteRequest(video, "")
+	}
+
+	statuses := map[int]int{}
+	for _, w := range serveConcurrently(router, reqs) {
+		statuses[w.Code]++
+	}
+	if statuses[http.StatusCreated] != 1 || statuses[http.StatusConflict] != concurrentRequests-1 {
+		t.Errorf("statuses = %v, want one %d and %d %d", statuses,
+			http.StatusCreated, concurrentRequests-1, http.StatusConflict)
+	}
+}
+
+func TestVoteVideoConcurrentIdempotencyKey(t *testing.T) {
+	db := openTestDB(t)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+	video := createTestVideo(t, db, owner)
+	other := createTestVideo(t, db, owner)
+	router := newVoteRouter(db, &config.Config{}, voter)
+
+	reqs := make([]*http.Request, concurrentRequests)
+	for i := range reqs {
+		reqs[i] = voteRequest(video, "key-1")
+	}
+
+	var replayed int
+	for _, w := range serveConcurrently(router, reqs) {
+		if w.Code != http.StatusCreated {
+			t.Errorf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+			continue
+		}
+		if w.Header().Get("Idempotent-Replayed") == "true" {
+			replayed++
+		}
+	}
+	if replayed != concurrentRequests-1 {
+		t.Errorf("replayed responses = %d, want %d", replayed, concurrentRequests-1)
+	}
+
+	// La misma clave para otro video se rechaza aunque lleguen varias a la vez
+	for i := range reqs {
+		reqs[i] = voteRequest(other, "key-1")
+	}
+	for _, w := range serveConcurrently(router, reqs) {
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status with a reused key = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestVoteVideoRateLimit(t *testing.T) {
+	db := openTestDB(t)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+	limit := 3
+	router := newVoteRouter(db, &config.Config{VoteLimitWindow: time.Hour, VoteLimitPerUser: limit}, voter)
+
+	for i := 0; i < limit; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, voteRequest(createTestVideo(t, db, owner), ""))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("vote %d: status = %d, want %d: %s", i+1, w.Code, http.StatusCreated, w.Body)
+		}
+	}
+
+	reqs := make([]*http.Request, concurrentRequests)
+	for i := range reqs {
+		reqs[i] = voteRequest(createTestVideo(t, db, owner), "")
+	}
+	for _, w := range serveConcurrently(router, reqs) {
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("status over the limit = %d, want %d", w.Code, http.StatusTooManyRequests)
+			continue
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("missing Retry-After header")
+		}
+	}
+}
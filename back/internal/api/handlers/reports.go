@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ReportHandler recibe las denuncias de los usuarios sobre videos públicos
+type ReportHandler struct {
+	validator *validator.Validate
+	reports   *services.ReportService
+}
+
+// NewReportHandler crea el handler de denuncias
+func NewReportHandler(reports *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		validator: validator.New(),
+		reports:   reports,
+	}
+}
+
+// ReportVideo registra la denuncia del usuario sobre un video público
+// @Summary Denunciar video
+// @Description Denuncia un video público por contenido inapropiado. Cada usuario denuncia un video una sola vez. Al llegar a VIDEO_REPORT_THRESHOLD denuncias sin resolver, el video se oculta hasta que un jurado o admin las revisa
+// @Tags public
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param video_id path string true "ID del video"
+// @Param report body models.VideoReportRequest true "Motivo de la denuncia (spam, inappropriate, violence, harassment, copyright u other)"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse "Usuario ya denunció este video"
+// @Failure 500 {object} models.APIResponse
+// @Router /public/videos/{video_id}/report [post]
+func (h *ReportHandler) ReportVideo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	videoID, err := uuid.Parse(c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	var req models.VideoReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.reports.Report(c.Request.Context(), videoID, int(userID.(int64)), req); err != nil {
+		switch {
+		case errors.Is(err, services.ErrVideoNotReportable):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Video not found or not public",
+			})
+		case errors.Is(err, services.ErrReportOwnVideo):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: "You cannot report your own video",
+			})
+		case errors.Is(err, services.ErrAlreadyReported):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Error: "You have already reported this video",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to register report",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Message: "Report registered successfully",
+	})
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoundHandler expone las competencias, sus rondas y los ganadores
+type RoundHandler struct {
+	roundService *services.RoundService
+}
+
+// NewRoundHandler crea el handler de competencias y rondas
+func NewRoundHandler(roundService *services.RoundService) *RoundHandler {
+	return &RoundHandler{roundService: roundService}
+}
+
+// ListCompetitions devuelve las competencias con sus rondas
+// @Summary Listar competencias
+// @Description Obtiene las competencias con sus rondas (inscripciones, votación regional y final nacional) y el estado de cada una
+// @Tags public
+// @Produce json
+// @Success 200 {array} models.Competition
+// @Failure 500 {object} models.APIResponse
+// @Router /public/competitions [get]
+func (h *RoundHandler) ListCompetitions(c *gin.Context) {
+	competitions, err := h.roundService.ListCompetitions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve competitions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, competitions)
+}
+
+// GetRoundWinners devuelve los ganadores guardados al cerrar una ronda
+// @Summary Ganadores de una ronda
+// @Description Obtiene los videos clasificados de cada ciudad al cerrar la ronda. Vacío mientras la ronda no se cierra
+// @Tags public
+// @Produce json
+// @Param round_id path int true "ID de la ronda"
+// @Success 200 {array} models.RoundWinner
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /public/rounds/{round_id}/winners [get]
+func (h *RoundHandler) GetRoundWinners(c *gin.Context) {
+	roundID, err := strconv.Atoi(c.Param("round_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid round ID",
+		})
+		return
+	}
+
+	winners, err := h.roundService.GetWinners(roundID)
+	if err != nil {
+		if errors.Is(err, services.ErrRoundNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Round not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve round winners",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, winners)
+}
+
+// CreateCompetition crea una competencia
+// @Summary Crear competencia
+// @Description Crea una competencia sin rondas
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param competition body models.CompetitionCreate true "Datos de la competencia"
+// @Success 201 {object} models.Competition
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/competitions [post]
+func (h *RoundHandler) CreateCompetition(c *gin.Context) {
+	var req models.CompetitionCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	competition, err := h.roundService.CreateCompetition(req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRound) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to create competition",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, competition)
+}
+
+// CreateRound agrega una ronda a una competencia
+// @Summary Crear ronda
+// @Description Agrega una ronda (inscripciones, votación regional o final nacional) a la competencia
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param competition_id path int true "ID de la competencia"
+// @Param round body models.RoundCreate true "Datos de la ronda"
+// @Success 201 {object} models.Round
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/competitions/{competition_id}/rounds [post]
+func (h *RoundHandler) CreateRound(c *gin.Context) {
+	competitionID, err := strconv.Atoi(c.Param("competition_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid competition ID",
+		})
+		return
+	}
+
+	var req models.RoundCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return
+	}
+
+	round, err := h.roundService.CreateRound(competitionID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCompetitionNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Competition not found",
+			})
+		case errors.Is(err, services.ErrInvalidRound):
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Error: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to create round",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, round)
+}
+
+// CloseRound cierra una ronda antes de que termine su ventana
+// @Summary Cerrar ronda
+// @Description Cierra la ronda y guarda sus ganadores por ciudad
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Security AdminKey
+// @Param round_id path int true "ID de la ronda"
+// @Success 200 {array} models.RoundWinner
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/rounds/{round_id}/close [post]
+func (h *RoundHandler) CloseRound(c *gin.Context) {
+	roundID, err := strconv.Atoi(c.Param("round_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid round ID",
+		})
+		return
+	}
+
+	if err := h.roundService.CloseRound(roundID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoundNotFound):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Error: "Round not found",
+			})
+		case errors.Is(err, services.ErrRoundClosed):
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Error: "Round is already closed",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Error: "Failed to close round",
+			})
+		}
+		return
+	}
+
+	winners, err := h.roundService.GetWinners(roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve round winners",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, winners)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"back/internal/database/models"
+	"back/internal/services/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorageHandler recibe las subidas firmadas cuando se usa almacenamiento local,
+// emulando las URLs presignadas de S3
+type StorageHandler struct {
+	storage *storage.LocalStorage
+}
+
+// NewStorageHandler crea una instancia del handler para inyectar dependencias
+func NewStorageHandler(localStorage *storage.LocalStorage) *StorageHandler {
+	return &StorageHandler{storage: localStorage}
+}
+
+// SignedUpload guarda el archivo enviado a una URL generada por LocalStorage.PresignUpload
+// @Summary Subida firmada (storage local)
+// @Description Recibe el archivo (cuerpo binario) de una URL firmada. Solo disponible con STORAGE_TYPE=local
+// @Tags storage
+// @Accept octet-stream
+// @Produce json
+// @Param path query string true "Ruta de destino firmada"
+// @Param size query int true "Tamaño firmado en bytes"
+// @Param content_type query string true "Content type firmado"
+// @Param expires query int true "Expiración (unix)"
+// @Param signature query string true "Firma HMAC"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /storage/upload [put]
+func (h *StorageHandler) SignedUpload(c *gin.Context) {
+	destPath := c.Query("path")
+	contentType := c.Query("content_type")
+	size, sizeErr := strconv.ParseInt(c.Query("size"), 10, 64)
+	expires, expiresErr := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if destPath == "" || sizeErr != nil || expiresErr != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid signed upload URL",
+		})
+		return
+	}
+
+	if err := h.storage.VerifyUploadSignature(destPath, contentType, size, expires, c.Query("signature")); err != nil {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	// Igual que S3, rechazar si los headers no coinciden con lo firmado
+	requestType := strings.TrimSpace(strings.Split(c.GetHeader("Content-Type"), ";")[0])
+	if !strings.EqualFold(requestType, contentType) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: fmt.Sprintf("Content-Type must be %s", contentType),
+		})
+		return
+	}
+	if c.Request.ContentLength >= 0 && c.Request.ContentLength != size {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: fmt.Sprintf("Content-Length must be %d", size),
+		})
+		return
+	}
+
+	if err := h.storage.SaveStream(destPath, c.Request.Body, size); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to save file: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "File uploaded",
+	})
+}
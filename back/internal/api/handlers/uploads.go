@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ChunkChecksumHeader es el header con el SHA-256 (hex) de cada parte enviada
+const ChunkChecksumHeader = "X-Chunk-Checksum"
+
+// InitUpload inicia una subida reanudable por partes
+// @Summary Iniciar subida reanudable
+// @Description Crea una sesión de subida por partes. El cliente envía luego cada parte con PUT y finaliza con complete
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param upload body models.UploadInit true "Datos del video a subir"
+// @Success 201 {object} models.UploadSession
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Solo jugadores con el correo verificado (si se exige) pueden subir videos"
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads [post]
+func (h *VideoHandler) InitUpload(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+	userIDInt64 := userID.(int64)
+
+	req, ok := h.bindUploadInit(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.InitUpload(userIDInt64, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to start upload: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// RequestUploadURL genera una URL firmada para subir el video directamente al storage
+// @Summary Solicitar URL de subida directa
+// @Description Retorna una URL firmada (presignada en S3) para subir el archivo con PUT sin pasar por la API. Luego se debe llamar a confirm
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param upload body models.UploadInit true "Datos del video a subir"
+// @Success 201 {object} models.DirectUpload
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Solo jugadores con el correo verificado (si se exige) pueden subir videos"
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/upload-url [post]
+func (h *VideoHandler) RequestUploadURL(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+	userIDInt64 := userID.(int64)
+
+	req, ok := h.bindUploadInit(c)
+	if !ok {
+		return
+	}
+
+	upload, err := h.uploadService.InitDirectUpload(userIDInt64, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to generate upload URL: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// GetUpload retorna el estado de una subida reanudable (partes recibidas)
+// @Summary Estado de subida reanudable
+// @Description Retorna las partes ya recibidas para que el cliente reanude la subida tras un corte
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "ID de la subida"
+// @Success 200 {object} models.UploadSession
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads/{upload_id} [get]
+func (h *VideoHandler) GetUpload(c *gin.Context) {
+	userIDInt64, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.GetUpload(uploadID, userIDInt64)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// UploadChunk recibe una parte de una subida reanudable
+// @Summary Enviar parte de subida reanudable
+// @Description Recibe los bytes de una parte (cuerpo binario) y verifica su checksum SHA-256. Re-enviar una parte la reemplaza
+// @Tags videos
+// @Accept octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "ID de la subida"
+// @Param part_number path int true "Número de parte (desde 1)"
+// @Param X-Chunk-Checksum header string true "SHA-256 en hexadecimal de la parte"
+// @Success 200 {object} models.UploadPart
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 422 {object} models.APIResponse "Checksum o tamaño de la parte no coinciden"
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads/{upload_id}/parts/{part_number} [put]
+func (h *VideoHandler) UploadChunk(c *gin.Context) {
+	userIDInt64, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	partNumber, err := strconv.Atoi(c.Param("part_number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid part number",
+		})
+		return
+	}
+
+	checksum := c.GetHeader(ChunkChecksumHeader)
+	if checksum == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: ChunkChecksumHeader + " header required",
+		})
+		return
+	}
+
+	part, err := h.uploadService.AppendChunk(uploadID, userIDInt64, partNumber, c.Request.Body, checksum)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, part)
+}
+
+// CompleteUpload finaliza una subida reanudable y encola el procesamiento
+// @Summary Completar subida reanudable
+// @Description Ensambla las partes recibidas, registra el video y encola su procesamiento
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "ID de la subida"
+// @Success 201 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse "Faltan partes o la subida no está activa"
+// @Failure 422 {object} models.APIResponse{data=models.VideoValidationError} "El archivo no es un video válido"
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads/{upload_id}/complete [post]
+func (h *VideoHandler) CompleteUpload(c *gin.Context) {
+	userIDInt64, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.CompleteUpload(uploadID, userIDInt64)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	h.respondUploadCompleted(c, session)
+}
+
+// respondUploadCompleted encola el procesamiento del video recién registrado y responde al cliente
+func (h *VideoHandler) respondUploadCompleted(c *gin.Context, session *models.UploadSession) {
+	videoID := session.VideoID.String()
+	taskID, err := h.taskQueue.EnqueueVideoProcessing(videoID)
+	if err != nil {
+		log.Printf("Failed to enqueue video processing task for video %s: %v", videoID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Video subido correctamente. Procesamiento en curso.",
+		"task_id":  taskID,
+		"video_id": videoID,
+	})
+}
+
+// ConfirmUpload confirma una subida directa y encola el procesamiento
+// @Summary Confirmar subida directa
+// @Description Verifica que el archivo exista en el storage con el tamaño y tipo declarados, registra el video y encola su procesamiento
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "ID de la subida"
+// @Success 201 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse "El archivo no fue subido o la subida no está activa"
+// @Failure 422 {object} models.APIResponse{data=models.VideoValidationError} "El archivo no coincide con lo declarado o no es un video válido"
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads/{upload_id}/confirm [post]
+func (h *VideoHandler) ConfirmUpload(c *gin.Context) {
+	userIDInt64, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.ConfirmDirectUpload(uploadID, userIDInt64)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	h.respondUploadCompleted(c, session)
+}
+
+// AbortUpload cancela una subida reanudable
+// @Summary Cancelar subida reanudable
+// @Description Cancela la subida y descarta las partes almacenadas
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "ID de la subida"
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/uploads/{upload_id} [delete]
+func (h *VideoHandler) AbortUpload(c *gin.Context) {
+	userIDInt64, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uploadService.AbortUpload(uploadID, userIDInt64); err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Message: "Upload aborted",
+	})
+}
+
+// bindUploadInit valida los datos del video a subir (título, tamaño máximo y extensión)
+func (h *VideoHandler) bindUploadInit(c *gin.Context) (models.UploadInit, bool) {
+	var req models.UploadInit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid request format",
+		})
+		return req, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Validation failed: " + err.Error(),
+		})
+		return req, false
+	}
+
+	if req.Size > h.config.MaxFileSize {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: fmt.Sprintf("File size exceeds maximum allowed (%dMB)", h.config.MaxFileSize/1024/1024),
+		})
+		return req, false
+	}
+
+	if !isValidVideoFile(req.Filename) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid file type. Only MP4, MOV, WebM and MKV files are allowed",
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+// uploadParams extrae el usuario autenticado y valida el upload_id de la ruta
+func (h *VideoHandler) uploadParams(c *gin.Context) (int64, string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return 0, "", false
+	}
+
+	uploadID := c.Param("upload_id")
+	if _, err := uuid.Parse(uploadID); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid upload ID format",
+		})
+		return 0, "", false
+	}
+
+	return userID.(int64), uploadID, true
+}
+
+// respondUploadError traduce los errores del UploadService a respuestas HTTP
+func respondUploadError(c *gin.Context, err error) {
+	if respondVideoValidationError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, models.APIResponse{Error: "Upload not found"})
+	case errors.Is(err, services.ErrInvalidPartNumber):
+		c.JSON(http.StatusBadRequest, models.APIResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrUploadNotActive),
+		errors.Is(err, services.ErrUploadExpired),
+		errors.Is(err, services.ErrUploadIncomplete),
+		errors.Is(err, services.ErrUploadTypeMismatch),
+		errors.Is(err, services.ErrUploadObjectMissing):
+		c.JSON(http.StatusConflict, models.APIResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrChunkSizeMismatch),
+		errors.Is(err, services.ErrChecksumMismatch),
+		errors.Is(err, services.ErrUploadObjectMismatch):
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Error: "Upload failed: " + err.Error()})
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat mantiene viva la conexión frente a proxies que cortan conexiones inactivas
+const sseHeartbeat = 25 * time.Second
+
+type VideoEventsHandler struct {
+	broker *services.VideoEventBroker
+}
+
+// NewVideoEventsHandler crea el handler del stream de eventos de video
+func NewVideoEventsHandler(broker *services.VideoEventBroker) *VideoEventsHandler {
+	return &VideoEventsHandler{broker: broker}
+}
+
+// StreamVideoEvents envía por Server-Sent Events los cambios de estado de los videos del usuario
+// @Summary Eventos de mis videos
+// @Description Stream SSE con los cambios de estado y el avance de procesamiento de los videos del usuario autenticado. Cada evento "video" lleva un VideoEvent en JSON.
+// @Tags videos
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} models.VideoEvent
+// @Failure 401 {object} models.APIResponse
+// @Router /videos/events [get]
+func (h *VideoEventsHandler) StreamVideoEvents(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	events, unsubscribe := h.broker.Subscribe(userID.(int64))
+	defer unsubscribe()
+
+	startSSE(c)
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event := <-events:
+			c.SSEvent("video", event)
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": ping\n\n")
+		}
+		return true
+	})
+}
+
+// startSSE envía los headers de un stream de Server-Sent Events
+func startSSE(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx no debe acumular el stream
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
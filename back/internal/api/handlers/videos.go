@@ -2,18 +2,20 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
-	"path/filepath"
-	"strings"
 
 	"back/internal/config"
 	"back/internal/database/models"
 	"back/internal/services"
+	"back/internal/services/storage"
+	"back/internal/utils"
 	"back/internal/workers"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 type VideoHandler struct {
@@ -21,17 +23,23 @@ type VideoHandler struct {
 	config       *config.Config
 	validator    *validator.Validate
 	videoService services.VideoServiceInterface
+	taskService  *services.TaskService
 	taskQueue    *workers.TaskQueue
+
+	uploadService *services.UploadService
 }
 
 // NewVideoHandler crea una instancia del handler para inyectar dependencias
-func NewVideoHandler(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, videoService services.VideoServiceInterface) *VideoHandler {
+func NewVideoHandler(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, videoService services.VideoServiceInterface, fileStorage storage.Storage) *VideoHandler {
 	return &VideoHandler{
 		db:           db,
 		config:       cfg,
 		validator:    validator.New(),
 		videoService: videoService,
+		taskService:  services.NewTaskService(db),
 		taskQueue:    taskQueue,
+
+		uploadService: services.NewUploadService(db, cfg, fileStorage, videoService),
 	}
 }
 
@@ -48,6 +56,8 @@ func NewVideoHandler(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueu
 // @Success 201 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Solo jugadores con el correo verificado (si se exige) pueden subir videos"
+// @Failure 422 {object} models.APIResponse{data=models.VideoValidationError} "El archivo no es un video válido"
 // @Failure 500 {object} models.APIResponse
 // @Router /videos/upload [post]
 func (h *VideoHandler) UploadVideo(c *gin.Context) {
@@ -78,7 +88,7 @@ func (h *VideoHandler) UploadVideo(c *gin.Context) {
 
 	if !isValidVideoFile(header.Filename) {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Error: "Invalid file type. Only MP4 files are allowed",
+			Error: "Invalid file type. Only MP4, MOV, WebM and MKV files are allowed",
 		})
 		return
 	}
@@ -104,19 +114,24 @@ func (h *VideoHandler) UploadVideo(c *gin.Context) {
 
 	videoID, err := h.videoService.CreateVideo(userIDInt64, upload.Title, file, header.Filename, upload.IsPublic)
 	if err != nil {
+		if respondVideoValidationError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to create video record or save file: " + err.Error(),
 		})
 		return
 	}
 
-	if err := h.taskQueue.EnqueueVideoProcessing(videoID); err != nil {
+	taskID, err := h.taskQueue.EnqueueVideoProcessing(videoID)
+	if err != nil {
 		fmt.Printf("Failed to enqueue video processing task: %v\n", err)
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Video subido correctamente. Procesamiento en curso.",
-		"task_id": videoID,
+		"message":  "Video subido correctamente. Procesamiento en curso.",
+		"task_id":  taskID,
+		"video_id": videoID,
 	})
 }
 
@@ -152,8 +167,11 @@ func (h *VideoHandler) GetMyVideos(c *gin.Context) {
 	// Generar URLs públicas para todos los videos procesados
 	for i := range videos {
 		if videos[i].ProcessedURL != nil {
+			videos[i].FallbackURL = h.videoService.GenerateFallbackURL(videos[i].ProcessedURL)
 			videos[i].ProcessedURL = h.videoService.GeneratePublicURL(videos[i].ProcessedURL)
 		}
+		videos[i].ThumbnailURL = h.videoService.GenerateMediaURL(videos[i].ThumbnailURL)
+		videos[i].PreviewURL = h.videoService.GenerateMediaURL(videos[i].PreviewURL)
 	}
 
 	c.JSON(http.StatusOK, videos)
@@ -199,15 +217,81 @@ func (h *VideoHandler) GetVideoDetail(c *gin.Context) {
 
 	// Generar URL pública si el video está procesado
 	if video.ProcessedURL != nil {
+		video.FallbackURL = h.videoService.GenerateFallbackURL(video.ProcessedURL)
 		video.ProcessedURL = h.videoService.GeneratePublicURL(video.ProcessedURL)
 	}
+	video.ThumbnailURL = h.videoService.GenerateMediaURL(video.ThumbnailURL)
+	video.PreviewURL = h.videoService.GenerateMediaURL(video.PreviewURL)
 
 	c.JSON(http.StatusOK, video)
 }
 
+// GetVideoTasks lista el historial de tareas de procesamiento de un video
+// @Summary Obtener tareas de procesamiento de un video
+// @Description Lista las tareas de procesamiento del video con su estado, intentos y mensaje de error
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param video_id path string true "ID del video"
+// @Success 200 {array} models.TaskResult
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /videos/{video_id}/tasks [get]
+func (h *VideoHandler) GetVideoTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+	userIDInt64 := userID.(int64)
+
+	videoIDStr := c.Param("video_id")
+	if _, err := uuid.Parse(videoIDStr); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: "Invalid video ID format",
+		})
+		return
+	}
+
+	video, err := h.videoService.GetVideoByID(videoIDStr, userIDInt64)
+	if err != nil {
+		if err.Error() == "forbidden" {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Error: "You do not have access to this video",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve video",
+		})
+		return
+	}
+	if video == nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Error: "Video not found",
+		})
+		return
+	}
+
+	tasks, err := h.taskService.GetTasksByVideo(videoIDStr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Error: "Failed to retrieve video tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
 // DeleteVideo elimina un video
 // @Summary Eliminar video
-// @Description Elimina un video específico del usuario autenticado
+// @Description Elimina un video específico del usuario autenticado. Los videos ocultos por denuncias o rechazados no se pueden eliminar: sus archivos y denuncias se conservan para la revisión
 // @Tags videos
 // @Accept json
 // @Produce json
@@ -216,6 +300,7 @@ func (h *VideoHandler) GetVideoDetail(c *gin.Context) {
 // @Success 200 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
 // @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /videos/{video_id} [delete]
 func (h *VideoHandler) DeleteVideo(c *gin.Context) {
@@ -230,6 +315,12 @@ func (h *VideoHandler) DeleteVideo(c *gin.Context) {
 
 	videoIDStr := c.Param("video_id")
 	if err := h.videoService.DeleteVideo(videoIDStr, userIDInt64); err != nil {
+		if errors.Is(err, services.ErrVideoUnderReview) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Error: "Video is hidden by reports or rejected and cannot be deleted",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Error: "Failed to delete video",
 		})
@@ -244,6 +335,19 @@ func (h *VideoHandler) DeleteVideo(c *gin.Context) {
 
 // Función auxiliar para validar archivos de video
 func isValidVideoFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return ext == ".mp4"
+	return utils.VideoContainerFromFilename(filename) != ""
+}
+
+// respondVideoValidationError responde 422 con el detalle si err es un rechazo de la validación del video
+func respondVideoValidationError(c *gin.Context, err error) bool {
+	var validationErr *models.VideoValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+		Error: "Invalid video file: " + validationErr.Message,
+		Data:  validationErr,
+	})
+	return true
 }
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"back/internal/config"
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth protege /api/admin: acepta un access token de un usuario con alguno de los roles o,
+// para scripts y operación, el header X-Admin-Key, que equivale al rol admin.
+func AdminAuth(cfg *config.Config, sessions *services.SessionService, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-Admin-Key") != "" {
+			if !checkAdminKey(c, cfg) {
+				return
+			}
+			c.Set("user_role", models.RoleAdmin)
+			c.Next()
+			return
+		}
+
+		if !authenticate(c, cfg, sessions) {
+			return
+		}
+		if !hasRole(c, roles) {
+			c.JSON(http.StatusForbidden, models.APIResponse{Error: "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkAdminKey valida el header X-Admin-Key. Si no es válido responde el error, aborta la
+// petición y retorna false.
+func checkAdminKey(c *gin.Context, cfg *config.Config) bool {
+	if cfg.AdminAPIKey == "" {
+		c.JSON(http.StatusNotFound, models.APIResponse{Error: "Not found"})
+		c.Abort()
+		return false
+	}
+
+	key := c.GetHeader("X-Admin-Key")
+	if subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminAPIKey)) != 1 {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Invalid admin key"})
+		c.Abort()
+		return false
+	}
+	return true
+}
@@ -3,37 +3,68 @@ package middleware
 import (
 	"back/internal/config"
 	"back/internal/database/models"
+	"back/internal/services"
 	"back/internal/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware valida el token JWT y guarda el user_id en el contexto de Gin.
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// AuthMiddleware valida el token JWT y que su sesión (jti) siga vigente, y guarda el user_id, el
+// session_id y el user_role en el contexto de Gin.
+func AuthMiddleware(cfg *config.Config, sessions *services.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Authorization header required"})
-			c.Abort()
+		if !authenticate(c, cfg, sessions) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Invalid authorization header format"})
-			c.Abort()
-			return
-		}
+// authenticate valida el access token de la petición. Si no es válido responde el error, aborta
+// la petición y retorna false.
+func authenticate(c *gin.Context, cfg *config.Config, sessions *services.SessionService) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Authorization header required"})
+		c.Abort()
+		return false
+	}
 
-		claims, err := utils.ValidateJWT(tokenString, cfg.JWTSecret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Invalid or expired token"})
-			c.Abort()
-			return
-		}
+	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Invalid authorization header format"})
+		c.Abort()
+		return false
+	}
 
-		c.Set("user_id", int64(claims.UserID))
-		c.Next()
+	claims, err := utils.ValidateJWT(tokenString, cfg.JWTSecret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Invalid or expired token"})
+		c.Abort()
+		return false
+	}
+
+	active, err := sessions.IsActive(claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Error: "Failed to verify session"})
+		c.Abort()
+		return false
 	}
+	if !active {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{Error: "Session revoked or expired"})
+		c.Abort()
+		return false
+	}
+
+	// Tokens emitidos antes de los roles: el rol por defecto
+	role := claims.Role
+	if role == "" {
+		role = models.RolePlayer
+	}
+
+	c.Set("user_id", int64(claims.UserID))
+	c.Set("session_id", claims.ID)
+	c.Set("user_role", role)
+	return true
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"back/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole exige que el usuario autenticado tenga alguno de los roles. Va después de
+// AuthMiddleware (o AdminAuth), que guarda el user_role en el contexto.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasRole(c, roles) {
+			c.JSON(http.StatusForbidden, models.APIResponse{Error: "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasRole(c *gin.Context, roles []string) bool {
+	role := c.GetString("user_role")
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"back/internal/database/models"
+	"back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail exige que el usuario autenticado haya verificado su correo. Va después de
+// AuthMiddleware; con required en false deja pasar todas las peticiones.
+func RequireVerifiedEmail(accounts *services.AccountService, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !required {
+			c.Next()
+			return
+		}
+
+		verified, err := accounts.IsEmailVerified(int(c.GetInt64("user_id")))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Error: "Failed to verify email status"})
+			c.Abort()
+			return
+		}
+		if !verified {
+			c.JSON(http.StatusForbidden, models.APIResponse{Error: "Email not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
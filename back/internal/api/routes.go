@@ -2,13 +2,16 @@ package api
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
 	"time"
 
 	"back/internal/api/handlers"
 	"back/internal/api/middleware"
 	"back/internal/config"
+	"back/internal/database/models"
 	"back/internal/services"
+	"back/internal/services/storage"
 	"back/internal/workers"
 
 	"github.com/gin-gonic/gin"
@@ -17,15 +20,20 @@ import (
 )
 
 // SetupRoutes configura todas las rutas de la aplicación
-func SetupRoutes(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, videoService services.VideoServiceInterface) *gin.Engine {
+func SetupRoutes(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, videoService services.VideoServiceInterface, fileStorage storage.Storage, videoEvents *services.VideoEventBroker, rankingService *services.RankingService, roundService *services.RoundService, voteFraud *services.VoteFraudService, voteService *services.VoteService, sessionService *services.SessionService, accountService *services.AccountService, moderationService *services.ModerationService, reportService *services.ReportService, rankingEvents *services.RankingEventBroker) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery(), gin.Logger())
 
+	// La IP del cliente (límites de votos) solo se toma de X-Forwarded-For si viene de un proxy conocido
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Printf("Warning: invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	router.Use(func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 		c.Header("Access-Control-Allow-Origin", origin)
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Chunk-Checksum, X-Device-ID, X-Admin-Key, Idempotency-Key")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -37,9 +45,25 @@ func SetupRoutes(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, v
 	})
 
 	// Inicializar handlers inyectando dependencias
-	authHandler := handlers.NewAuthHandler(db, cfg)
-	videoHandler := handlers.NewVideoHandler(db, cfg, taskQueue, videoService)
-	rankingHandler := handlers.NewRankingHandler(db, cfg, videoService)
+	authHandler := handlers.NewAuthHandler(db, cfg, sessionService, accountService, rankingService)
+	videoHandler := handlers.NewVideoHandler(db, cfg, taskQueue, videoService, fileStorage)
+	rankingHandler := handlers.NewRankingHandler(db, cfg, videoService, rankingService, roundService, voteService, rankingEvents)
+	roundHandler := handlers.NewRoundHandler(roundService)
+	adminVotesHandler := handlers.NewAdminVotesHandler(voteFraud)
+	adminUsersHandler := handlers.NewAdminUsersHandler(db, cfg, sessionService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	adminVideosHandler := handlers.NewAdminVideosHandler(moderationService, reportService)
+	videoEventsHandler := handlers.NewVideoEventsHandler(videoEvents)
+
+	// Valida el access token y su sesión
+	authMiddleware := middleware.AuthMiddleware(cfg, sessionService)
+
+	// Correo verificado para votar y subir videos (según REQUIRE_VERIFIED_EMAIL_TO_*)
+	verifiedToVote := middleware.RequireVerifiedEmail(accountService, cfg.RequireVerifiedEmailToVote)
+	verifiedToUpload := middleware.RequireVerifiedEmail(accountService, cfg.RequireVerifiedEmailToUpload)
+
+	// Solo los jugadores suben videos
+	playersOnly := middleware.RequireRole(models.RolePlayer)
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -58,25 +82,59 @@ func SetupRoutes(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, v
 	{
 		authGroup.POST("/signup", authHandler.Signup)
 		authGroup.POST("/login", authHandler.Login)
-		authGroup.POST("/logout", middleware.AuthMiddleware(cfg), authHandler.Logout)
-		authGroup.GET("/profile", middleware.AuthMiddleware(cfg), authHandler.GetProfile)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authMiddleware, authHandler.Logout)
+		authGroup.POST("/logout-all", authMiddleware, authHandler.LogoutAll)
+		authGroup.GET("/profile", authMiddleware, authHandler.GetProfile)
+		authGroup.PUT("/profile", authMiddleware, authHandler.UpdateProfile)
+		authGroup.POST("/change-password", authMiddleware, authHandler.ChangePassword)
+		authGroup.DELETE("/account", authMiddleware, authHandler.DeleteAccount)
+
+		// Verificación de correo y restablecimiento de contraseña
+		authGroup.POST("/verify-email/send", authMiddleware, authHandler.SendVerificationEmail)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/password/forgot", authHandler.ForgotPassword)
+		authGroup.POST("/password/reset", authHandler.ResetPassword)
 	}
 
 	// Protected video routes
 	videosGroup := router.Group("/api/videos")
-	videosGroup.Use(middleware.AuthMiddleware(cfg))
+	videosGroup.Use(authMiddleware)
 	{
-		videosGroup.POST("/upload", videoHandler.UploadVideo)
+		videosGroup.POST("/upload", playersOnly, verifiedToUpload, videoHandler.UploadVideo)
+
+		// Subidas reanudables por partes
+		videosGroup.POST("/uploads", playersOnly, verifiedToUpload, videoHandler.InitUpload)
+		videosGroup.GET("/uploads/:upload_id", videoHandler.GetUpload)
+		videosGroup.PUT("/uploads/:upload_id/parts/:part_number", videoHandler.UploadChunk)
+		videosGroup.POST("/uploads/:upload_id/complete", videoHandler.CompleteUpload)
+		videosGroup.DELETE("/uploads/:upload_id", videoHandler.AbortUpload)
+
+		// Subida directa al storage con URL firmada
+		videosGroup.POST("/upload-url", playersOnly, verifiedToUpload, videoHandler.RequestUploadURL)
+		videosGroup.POST("/uploads/:upload_id/confirm", videoHandler.ConfirmUpload)
+
+		// Cambios de estado de los videos del usuario (Server-Sent Events)
+		videosGroup.GET("/events", videoEventsHandler.StreamVideoEvents)
+
 		videosGroup.GET("", videoHandler.GetMyVideos)
 		videosGroup.GET("/:video_id", videoHandler.GetVideoDetail)
+		videosGroup.GET("/:video_id/tasks", videoHandler.GetVideoTasks)
 		videosGroup.DELETE("/:video_id", videoHandler.DeleteVideo)
 	}
 
+	// Subidas firmadas para storage local (equivalente a las URLs presignadas de S3)
+	if localStorage, ok := fileStorage.(*storage.LocalStorage); ok {
+		storageHandler := handlers.NewStorageHandler(localStorage)
+		router.PUT(storage.LocalSignedUploadPath, storageHandler.SignedUpload)
+	}
+
 	// Protected user routes
 	userGroup := router.Group("/api/user")
-	userGroup.Use(middleware.AuthMiddleware(cfg))
+	userGroup.Use(authMiddleware)
 	{
 		userGroup.GET("/votes", rankingHandler.GetUserVotes)
+		userGroup.GET("/stats", authHandler.GetUserStats)
 	}
 
 	// Rutas públicas para videos
@@ -85,11 +143,55 @@ func SetupRoutes(db *sql.DB, cfg *config.Config, taskQueue *workers.TaskQueue, v
 		// Listar videos públicos disponibles para votación
 		publicGroup.GET("/videos", rankingHandler.ListPublicVideos)
 
-		// Emitir voto por un video público (requiere autenticación)
-		publicGroup.POST("/videos/:video_id/vote", middleware.AuthMiddleware(cfg), rankingHandler.VoteVideo)
+		// Playlists HLS de un video procesado (segmentos con URL firmada)
+		publicGroup.GET("/videos/:video_id/hls/*file", rankingHandler.GetHLSPlaylist)
+
+		// Emitir o retirar voto por un video público (requiere autenticación)
+		publicGroup.POST("/videos/:video_id/vote", authMiddleware, verifiedToVote, rankingHandler.VoteVideo)
+		publicGroup.DELETE("/videos/:video_id/vote", authMiddleware, rankingHandler.RetractVote)
+
+		// Denunciar un video público (requiere autenticación)
+		publicGroup.POST("/videos/:video_id/report", authMiddleware, reportHandler.ReportVideo)
 
 		// Consultar tabla de clasificación/ranking
 		publicGroup.GET("/rankings", rankingHandler.GetRankings)
+		publicGroup.GET("/rankings/top", rankingHandler.GetTopRankings)
+
+		// Ranking en vivo (Server-Sent Events)
+		publicGroup.GET("/rankings/stream", rankingHandler.StreamRankings)
+
+		// Competencias, rondas y ganadores
+		publicGroup.GET("/competitions", roundHandler.ListCompetitions)
+		publicGroup.GET("/rounds/:round_id/winners", roundHandler.GetRoundWinners)
+	}
+
+	// Administración: usuarios jury o admin, o el header X-Admin-Key (equivale a admin)
+	adminGroup := router.Group("/api/admin")
+	adminGroup.Use(middleware.AdminAuth(cfg, sessionService, models.RoleJury, models.RoleAdmin))
+	adminOnly := middleware.RequireRole(models.RoleAdmin)
+	{
+		// Revisión de votos sospechosos
+		adminGroup.GET("/votes/flagged", adminVotesHandler.ListFlaggedVotes)
+		adminGroup.POST("/votes/void", adminVotesHandler.VoidVotes)
+		adminGroup.POST("/votes/dismiss", adminVotesHandler.DismissVotes)
+
+		// Moderación de videos
+		adminGroup.GET("/videos/pending", adminVideosHandler.ListPendingVideos)
+		adminGroup.POST("/videos/:video_id/approve", adminVideosHandler.ApproveVideo)
+		adminGroup.POST("/videos/:video_id/reject", adminVideosHandler.RejectVideo)
+
+		// Denuncias de videos
+		adminGroup.GET("/reports", adminVideosHandler.ListReportedVideos)
+		adminGroup.POST("/reports/:video_id/resolve", adminVideosHandler.ResolveReports)
+
+		// Usuarios y roles
+		adminGroup.GET("/users", adminOnly, adminUsersHandler.ListUsers)
+		adminGroup.PUT("/users/:user_id/role", adminOnly, adminUsersHandler.UpdateUserRole)
+
+		// Competencias y rondas
+		adminGroup.POST("/competitions", adminOnly, roundHandler.CreateCompetition)
+		adminGroup.POST("/competitions/:competition_id/rounds", adminOnly, roundHandler.CreateRound)
+		adminGroup.POST("/rounds/:round_id/close", adminOnly, roundHandler.CloseRound)
 	}
 
 	return router
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,10 +27,17 @@ type Config struct {
 	RedisURL  string
 	SQSRegion string
 	SQSQueue  string // SQS Queue URL
+	SQSDLQURL string // URL de la dead-letter queue de SQS (tareas que agotaron sus intentos)
+
+	SQSVisibilityTimeout time.Duration // tiempo que un mensaje recibido queda oculto para otros workers
+	SQSHeartbeatInterval time.Duration // cada cuánto se extiende la visibilidad mientras el video se procesa
+
+	QueueMaxAttempts int // intentos por tarea antes de enviarla a la dead-letter queue
 
 	// JWT
-	JWTSecret     string
-	JWTExpiration time.Duration
+	JWTSecret              string
+	JWTExpiration          time.Duration // vida del access token
+	RefreshTokenExpiration time.Duration // vida de la sesión sin usar el refresh token
 
 	// File Storage
 	StorageType   string // "local" or "s3"
@@ -37,6 +45,17 @@ type Config struct {
 	ProcessedPath string
 	MaxFileSize   int64
 
+	// Upload Validation
+	UploadProbeTimeout time.Duration // tiempo máximo de ffprobe al validar un archivo subido
+
+	// Resumable Uploads
+	UploadChunkSize  int64         // tamaño de cada parte; S3 exige mínimo 5MB salvo la última
+	UploadSessionTTL time.Duration // vigencia de una subida reanudable sin completar
+
+	// Direct Uploads
+	PresignedUploadTTL time.Duration // vigencia de la URL firmada de subida directa
+	StorageSigningKey  string        // llave HMAC de las URLs firmadas en storage local (por defecto JWT_SECRET)
+
 	// AWS S3 Configuration
 	AWSRegion         string
 	S3BucketName      string
@@ -47,9 +66,52 @@ type Config struct {
 	MaxVideoDuration  int
 	OutputResolution  string
 	OutputAspectRatio string
+	PipelineProfile   string // perfil JSON/YAML con los pasos de procesamiento (vacío = perfil por defecto)
+
+	// Rankings
+	RankingUpdateInterval  time.Duration // como máximo una actualización del ranking en vivo por intervalo
+	RankingRefreshInterval time.Duration // antigüedad máxima de la vista video_rankings
+	RankingRefreshVotes    int           // votos nuevos que adelantan la actualización de la vista
+
+	RankingCacheEnabled           bool          // sorted sets de Redis (REDIS_URL) delante de la vista
+	RankingCacheReconcileInterval time.Duration // cada cuánto se reconstruye el caché desde la tabla votes
+
+	// Votos: límites por ventana (0 = sin límite) y detección de fraude
+	VoteLimitWindow       time.Duration
+	VoteLimitPerUser      int
+	VoteLimitPerIP        int
+	VoteLimitPerDevice    int
+	VoteFraudScanInterval time.Duration // cada cuánto se buscan grupos de votos sospechosos
+	VoteFraudWindow       time.Duration // votos de un mismo grupo llegan con a lo sumo esta separación
+	VoteFraudMinCluster   int           // votos por video en la ventana a partir de los que se marca el grupo
+	VoteFraudAccountAge   time.Duration // cuentas más nuevas que esto al votar se consideran recientes
+
+	// Moderación: los videos públicos esperan la aprobación de un jurado o admin
+	VideoModerationEnabled bool
+	VideoReportThreshold   int // denuncias sin resolver que ocultan un video (0 = nunca se oculta solo)
+
+	// Correo y verificación de cuentas
+	MailerType                   string // "smtp" o "file" (escribe cada correo en MailOutputDir y lo registra en el log)
+	MailFrom                     string
+	MailOutputDir                string
+	SMTPHost                     string
+	SMTPPort                     string
+	SMTPUsername                 string
+	SMTPPassword                 string
+	AppBaseURL                   string        // URL del frontend para los enlaces de los correos
+	EmailVerificationTTL         time.Duration // vigencia del enlace de verificación del correo
+	PasswordResetTTL             time.Duration // vigencia del enlace para restablecer la contraseña
+	RequireVerifiedEmailToVote   bool
+	RequireVerifiedEmailToUpload bool
+
+	// Administración
+	AdminAPIKey    string   // habilita /api/admin con el header X-Admin-Key (vacío = deshabilitado)
+	TrustedProxies []string // proxies de los que se acepta X-Forwarded-For para obtener la IP del cliente
 
 	// Worker
-	WorkerConcurrency int
+	WorkerConcurrency  int
+	WorkerDrainTimeout time.Duration // al apagarse, tiempo que se espera a que terminen los videos en curso
+	WorkerTaskTimeout  time.Duration // tiempo máximo de un intento de procesamiento; al vencer cuenta como fallo
 }
 
 func Load() *Config {
@@ -69,15 +131,30 @@ func Load() *Config {
 		RedisURL:  getEnv("REDIS_URL", "redis:6379"),
 		SQSRegion: getEnv("SQS_REGION", "us-east-1"),
 		SQSQueue:  getEnv("SQS_QUEUE_URL", ""),
+		SQSDLQURL: getEnv("SQS_DLQ_URL", ""),
+
+		SQSVisibilityTimeout: getDurationEnv("SQS_VISIBILITY_TIMEOUT", "5m"),
+		SQSHeartbeatInterval: getDurationEnv("SQS_HEARTBEAT_INTERVAL", "1m"),
 
-		JWTSecret:     getEnv("JWT_SECRET", "local-development-secret-key"),
-		JWTExpiration: getDurationEnv("JWT_EXPIRATION", "24h"),
+		QueueMaxAttempts: getIntEnv("QUEUE_MAX_ATTEMPTS", "3"),
+
+		JWTSecret:              getEnv("JWT_SECRET", "local-development-secret-key"),
+		JWTExpiration:          getDurationEnv("JWT_EXPIRATION", "15m"),
+		RefreshTokenExpiration: getDurationEnv("REFRESH_TOKEN_EXPIRATION", "720h"),
 
 		StorageType:   getEnv("STORAGE_TYPE", "local"), // "local" or "s3"
 		UploadPath:    getEnv("UPLOAD_PATH", "./uploads"),
 		ProcessedPath: getEnv("PROCESSED_PATH", "./processed"),
 		MaxFileSize:   getInt64Env("MAX_FILE_SIZE", "104857600"), // 100MB
 
+		UploadProbeTimeout: getDurationEnv("UPLOAD_PROBE_TIMEOUT", "20s"),
+
+		UploadChunkSize:  getInt64Env("UPLOAD_CHUNK_SIZE", "8388608"), // 8MB
+		UploadSessionTTL: getDurationEnv("UPLOAD_SESSION_TTL", "24h"),
+
+		PresignedUploadTTL: getDurationEnv("PRESIGNED_UPLOAD_TTL", "15m"),
+		StorageSigningKey:  getEnv("STORAGE_SIGNING_KEY", ""),
+
 		// AWS S3 Configuration
 		AWSRegion:         getEnv("AWS_REGION", "us-east-1"),
 		S3BucketName:      getEnv("S3_BUCKET_NAME", ""),
@@ -87,8 +164,46 @@ func Load() *Config {
 		MaxVideoDuration:  getIntEnv("MAX_VIDEO_DURATION", "30"),
 		OutputResolution:  getEnv("OUTPUT_RESOLUTION", "1280x720"),
 		OutputAspectRatio: getEnv("OUTPUT_ASPECT_RATIO", "16:9"),
-
-		WorkerConcurrency: getIntEnv("WORKER_CONCURRENCY", "12"), // concurrencia
+		PipelineProfile:   getEnv("PIPELINE_PROFILE", ""),
+
+		RankingUpdateInterval:  getDurationEnv("RANKING_UPDATE_INTERVAL", "2s"),
+		RankingRefreshInterval: getDurationEnv("RANKING_REFRESH_INTERVAL", "1m"),
+		RankingRefreshVotes:    getIntEnv("RANKING_REFRESH_VOTES", "100"),
+
+		RankingCacheEnabled:           getEnv("RANKING_CACHE_ENABLED", "true") == "true",
+		RankingCacheReconcileInterval: getDurationEnv("RANKING_CACHE_RECONCILE_INTERVAL", "10m"),
+
+		VoteLimitWindow:       getDurationEnv("VOTE_LIMIT_WINDOW", "1h"),
+		VoteLimitPerUser:      getIntEnv("VOTE_LIMIT_PER_USER", "30"),
+		VoteLimitPerIP:        getIntEnv("VOTE_LIMIT_PER_IP", "60"),
+		VoteLimitPerDevice:    getIntEnv("VOTE_LIMIT_PER_DEVICE", "30"),
+		VoteFraudScanInterval: getDurationEnv("VOTE_FRAUD_SCAN_INTERVAL", "1m"),
+		VoteFraudWindow:       getDurationEnv("VOTE_FRAUD_WINDOW", "10m"),
+		VoteFraudMinCluster:   getIntEnv("VOTE_FRAUD_MIN_CLUSTER", "10"),
+		VoteFraudAccountAge:   getDurationEnv("VOTE_FRAUD_ACCOUNT_AGE", "24h"),
+
+		VideoModerationEnabled: getEnv("VIDEO_MODERATION_ENABLED", "false") == "true",
+		VideoReportThreshold:   getIntEnv("VIDEO_REPORT_THRESHOLD", "5"),
+
+		MailerType:                   getEnv("MAILER_TYPE", "file"),
+		MailFrom:                     getEnv("MAIL_FROM", "ANB Rising Stars <no-reply@anb-rising-stars.local>"),
+		MailOutputDir:                getEnv("MAIL_OUTPUT_DIR", "./mail"),
+		SMTPHost:                     getEnv("SMTP_HOST", ""),
+		SMTPPort:                     getEnv("SMTP_PORT", "587"),
+		SMTPUsername:                 getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:                 getEnv("SMTP_PASSWORD", ""),
+		AppBaseURL:                   getEnv("APP_BASE_URL", "http://localhost"),
+		EmailVerificationTTL:         getDurationEnv("EMAIL_VERIFICATION_TTL", "48h"),
+		PasswordResetTTL:             getDurationEnv("PASSWORD_RESET_TTL", "1h"),
+		RequireVerifiedEmailToVote:   getEnv("REQUIRE_VERIFIED_EMAIL_TO_VOTE", "false") == "true",
+		RequireVerifiedEmailToUpload: getEnv("REQUIRE_VERIFIED_EMAIL_TO_UPLOAD", "false") == "true",
+
+		AdminAPIKey:    getEnv("ADMIN_API_KEY", ""),
+		TrustedProxies: strings.Split(getEnv("TRUSTED_PROXIES", "127.0.0.1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"), ","),
+
+		WorkerConcurrency:  getIntEnv("WORKER_CONCURRENCY", "12"), // concurrencia
+		WorkerDrainTimeout: getDurationEnv("WORKER_DRAIN_TIMEOUT", "2m"),
+		WorkerTaskTimeout:  getDurationEnv("WORKER_TASK_TIMEOUT", "30m"),
 	}
 }
 
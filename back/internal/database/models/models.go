@@ -16,8 +16,12 @@ type User struct {
 	PasswordHash string    `json:"-" db:"password_hash"`
 	City         string    `json:"city" db:"city" validate:"required,min=2,max=50" example:"Bogotá"`
 	Country      string    `json:"country" db:"country" validate:"required,min=2,max=50" example:"Colombia"`
+	Role         string    `json:"role" db:"role" example:"player"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at" example:"2024-01-15T10:30:00Z"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at" example:"2024-01-15T10:30:00Z"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	EmailVerified   bool       `json:"email_verified" example:"true"`
 }
 
 // UserRegistration representa los datos para registro de usuario
@@ -29,6 +33,7 @@ type UserRegistration struct {
 	Password2 string `json:"password2" validate:"required,min=8" example:"password123"`
 	City      string `json:"city" validate:"required,min=2,max=50" example:"Bogotá"`
 	Country   string `json:"country" validate:"required,min=2,max=50" example:"Colombia"`
+	Role      string `json:"role" validate:"omitempty,oneof=player voter" example:"player"` // vacío = player
 }
 
 // UserLogin representa los datos para login
@@ -37,6 +42,53 @@ type UserLogin struct {
 	Password string `json:"password" validate:"required" example:"password123"`
 }
 
+// EmailRequest representa el correo al que se envía el enlace para restablecer la contraseña
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email" example:"juan.perez@email.com"`
+}
+
+// TokenRequest representa un token de un solo uso recibido por correo
+type TokenRequest struct {
+	Token string `json:"token" validate:"required" example:"Zk3v...Q.9f2c..."`
+}
+
+// PasswordReset representa la nueva contraseña con el token recibido por correo
+type PasswordReset struct {
+	Token     string `json:"token" validate:"required" example:"Zk3v...Q.9f2c..."`
+	Password1 string `json:"password1" validate:"required,min=8" example:"N3w-Passw0rd!"`
+	Password2 string `json:"password2" validate:"required,min=8" example:"N3w-Passw0rd!"`
+}
+
+// ProfileUpdate representa los datos editables del perfil
+type ProfileUpdate struct {
+	FirstName string `json:"first_name" validate:"required,min=2,max=50" example:"Juan"`
+	LastName  string `json:"last_name" validate:"required,min=2,max=50" example:"Pérez"`
+	City      string `json:"city" validate:"required,min=2,max=50" example:"Bogotá"`
+	Country   string `json:"country" validate:"required,min=2,max=50" example:"Colombia"`
+}
+
+// PasswordChange representa el cambio de contraseña de un usuario autenticado
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+	Password1       string `json:"password1" validate:"required,min=8" example:"N3w-Passw0rd!"`
+	Password2       string `json:"password2" validate:"required,min=8" example:"N3w-Passw0rd!"`
+}
+
+// AccountDeletion confirma con la contraseña el borrado de la cuenta
+type AccountDeletion struct {
+	Password string `json:"password" validate:"required" example:"password123"`
+}
+
+// RoleUpdate representa el cambio de rol de un usuario
+type RoleUpdate struct {
+	Role string `json:"role" validate:"required,oneof=player voter jury admin" example:"jury"`
+}
+
+// RefreshRequest representa el refresh token a rotar
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"q3Vx0f6mR2b9..."`
+}
+
 // Video representa un video en el sistema
 type Video struct {
 	ID               uuid.UUID  `json:"video_id" db:"id"`
@@ -45,11 +97,19 @@ type Video struct {
 	OriginalFilename string     `json:"original_filename" db:"original_filename"`
 	OriginalURL      *string    `json:"original_url,omitempty" db:"original_url"`
 	ProcessedURL     *string    `json:"processed_url,omitempty" db:"processed_url"`
+	FallbackURL      *string    `json:"fallback_url,omitempty" db:"-"` // MP4 cuando processed_url es un playlist HLS
+	ThumbnailURL     *string    `json:"thumbnail_url,omitempty" db:"thumbnail_url"`
+	PreviewURL       *string    `json:"preview_url,omitempty" db:"preview_url"`
+	FailureReason    *string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	Progress         *int       `json:"progress,omitempty" db:"progress"`                 // avance del procesamiento (0-100)
+	ProcessingStage  *string    `json:"processing_stage,omitempty" db:"processing_stage"` // etapa en curso mientras está 'processing'
 	Status           string     `json:"status" db:"status"`
 	UploadedAt       time.Time  `json:"uploaded_at" db:"uploaded_at"`
 	ProcessedAt      *time.Time `json:"processed_at,omitempty" db:"processed_at"`
 	VotesCount       int        `json:"votes" db:"votes_count"`
 	IsPublic         bool       `json:"is_public" db:"is_public"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	RejectionReason  *string    `json:"rejection_reason,omitempty" db:"rejection_reason"` // motivo del rechazo en la moderación
 
 	// Campos adicionales para joins
 	UserFirstName string `json:"user_first_name,omitempty" db:"user_first_name"`
@@ -63,14 +123,171 @@ type VideoUpload struct {
 	IsPublic bool   `form:"is_public"`
 }
 
+// UploadInit representa los datos para iniciar una subida reanudable
+type UploadInit struct {
+	Title    string `json:"title" validate:"required,min=5,max=100" example:"Mi mejor jugada"`
+	Filename string `json:"filename" validate:"required,max=255" example:"jugada.mp4"`
+	Size     int64  `json:"size" validate:"required,gt=0" example:"52428800"`
+	IsPublic bool   `json:"is_public" example:"true"`
+}
+
+// UploadSession representa una subida reanudable por partes o una subida directa al storage
+type UploadSession struct {
+	ID               uuid.UUID `json:"upload_id" db:"id"`
+	UploadType       string    `json:"upload_type" db:"upload_type"`
+	UserID           int       `json:"-" db:"user_id"`
+	VideoID          uuid.UUID `json:"video_id" db:"video_id"`
+	Title            string    `json:"title" db:"title"`
+	OriginalFilename string    `json:"original_filename" db:"original_filename"`
+	IsPublic         bool      `json:"is_public" db:"is_public"`
+	TotalSize        int64     `json:"total_size" db:"total_size"`
+	ChunkSize        int64     `json:"chunk_size" db:"chunk_size"`
+	TotalParts       int       `json:"total_parts"`
+	StoragePath      string    `json:"-" db:"storage_path"`
+	StorageUploadID  string    `json:"-" db:"storage_upload_id"`
+	ContentType      string    `json:"-" db:"content_type"`
+	Status           string    `json:"status" db:"status"`
+	ReceivedParts    []int     `json:"received_parts"`
+	ReceivedBytes    int64     `json:"received_bytes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt        time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// VideoEvent es un cambio de estado o de avance de un video, enviado por /api/videos/events
+type VideoEvent struct {
+	VideoID         uuid.UUID `json:"video_id"`
+	UserID          int64     `json:"user_id"`
+	Status          string    `json:"status"`
+	Progress        *int      `json:"progress,omitempty"`
+	ProcessingStage *string   `json:"processing_stage,omitempty"`
+	FailureReason   *string   `json:"failure_reason,omitempty"`
+	At              time.Time `json:"at"`
+}
+
+// VideoValidationError detalla por qué se rechazó un archivo de video al subirlo
+type VideoValidationError struct {
+	Code      string  `json:"code" example:"no_video_stream"`
+	Message   string  `json:"message" example:"The file does not contain a video stream"`
+	Container string  `json:"container,omitempty" example:"mp4"`
+	Codec     string  `json:"codec,omitempty" example:"h264"`
+	Duration  float64 `json:"duration,omitempty" example:"28.5"`
+	Width     int     `json:"width,omitempty" example:"1920"`
+	Height    int     `json:"height,omitempty" example:"1080"`
+}
+
+func (e *VideoValidationError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// DirectUpload contiene la URL firmada para subir el archivo directamente al storage
+type DirectUpload struct {
+	UploadID  uuid.UUID         `json:"upload_id"`
+	VideoID   uuid.UUID         `json:"video_id"`
+	Method    string            `json:"method" example:"PUT"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// UploadPart representa una parte recibida de una subida reanudable
+type UploadPart struct {
+	PartNumber int    `json:"part_number" db:"part_number"`
+	Size       int64  `json:"size" db:"size"`
+	Checksum   string `json:"checksum" db:"checksum"`
+	ETag       string `json:"-" db:"etag"`
+}
+
 // Vote representa un voto en el sistema
 type Vote struct {
 	ID        int       `json:"id" db:"id"`
 	UserID    int       `json:"user_id" db:"user_id"`
 	VideoID   uuid.UUID `json:"video_id" db:"video_id"`
+	RoundID   *int      `json:"round_id,omitempty" db:"round_id"` // nil si se votó fuera de rondas
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// FlaggedVote es un voto marcado como sospechoso, pendiente de revisión
+type FlaggedVote struct {
+	ID               int       `json:"id" example:"42"`
+	VideoID          uuid.UUID `json:"video_id"`
+	VideoTitle       string    `json:"video_title"`
+	UserID           int       `json:"user_id"`
+	UserEmail        string    `json:"user_email"`
+	AccountCreatedAt time.Time `json:"account_created_at"`
+	RoundID          *int      `json:"round_id,omitempty"`
+	IPAddress        string    `json:"ip_address,omitempty"`
+	UserAgent        string    `json:"user_agent,omitempty"`
+	DeviceID         string    `json:"device_id,omitempty"`
+	FlagReason       string    `json:"flag_reason" example:"fresh_accounts"` // fresh_accounts o shared_ip
+	FlaggedAt        time.Time `json:"flagged_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// VoteReview indica qué votos marcados anular o descartar
+type VoteReview struct {
+	VoteIDs []int64    `json:"vote_ids" example:"42,43"`
+	VideoID *uuid.UUID `json:"video_id,omitempty"` // al anular: todos los votos marcados pendientes del video
+}
+
+// PendingVideo es un video procesado que espera la revisión de la moderación
+type PendingVideo struct {
+	VideoID      uuid.UUID  `json:"video_id"`
+	Title        string     `json:"title" example:"Mi mejor jugada"`
+	UserID       int        `json:"user_id"`
+	Username     string     `json:"username" example:"Juan Pérez"`
+	UserEmail    string     `json:"user_email"`
+	City         string     `json:"city" example:"Bogotá"`
+	VideoURL     *string    `json:"video_url,omitempty"` // MP4 procesado, con URL firmada
+	ThumbnailURL *string    `json:"thumbnail_url,omitempty"`
+	PreviewURL   *string    `json:"preview_url,omitempty"`
+	UploadedAt   time.Time  `json:"uploaded_at"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+}
+
+// VideoRejection es el motivo con el que se rechaza un video en la moderación
+type VideoRejection struct {
+	Reason string `json:"reason" validate:"required,min=5,max=500" example:"El video no muestra una jugada de baloncesto"`
+}
+
+// VideoReportRequest es la denuncia de un usuario sobre un video público
+type VideoReportRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam inappropriate violence harassment copyright other" example:"inappropriate"`
+	Details string `json:"details" validate:"max=500" example:"El video contiene lenguaje ofensivo"`
+}
+
+// VideoReport es una denuncia sin resolver
+type VideoReport struct {
+	ID        int       `json:"id" example:"7"`
+	UserID    int       `json:"user_id"`
+	UserEmail string    `json:"user_email"`
+	Reason    string    `json:"reason" example:"inappropriate"`
+	Details   *string   `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportedVideo es un video con denuncias sin resolver
+type ReportedVideo struct {
+	VideoID        uuid.UUID      `json:"video_id"`
+	Title          string         `json:"title" example:"Mi mejor jugada"`
+	UserID         int            `json:"user_id"`
+	Username       string         `json:"username" example:"Juan Pérez"`
+	Status         string         `json:"status" example:"processed"`
+	HiddenAt       *time.Time     `json:"hidden_at,omitempty"` // cuándo se ocultó al llegar al umbral de denuncias
+	VideoURL       *string        `json:"video_url,omitempty"` // MP4 procesado, con URL firmada
+	ThumbnailURL   *string        `json:"thumbnail_url,omitempty"`
+	OpenReports    int            `json:"open_reports" example:"3"`
+	Reasons        map[string]int `json:"reasons"` // denuncias sin resolver por motivo
+	LastReportedAt time.Time      `json:"last_reported_at"`
+	Reports        []VideoReport  `json:"reports"`
+}
+
+// ReportResolution indica cómo resolver las denuncias de un video: dismiss las descarta (y
+// vuelve a publicar el video si se ocultó) y remove rechaza el video con el motivo indicado
+type ReportResolution struct {
+	Action string `json:"action" validate:"required,oneof=dismiss remove" example:"remove"`
+	Reason string `json:"reason" validate:"required_if=Action remove,max=500" example:"El video contiene lenguaje ofensivo"`
+}
+
 // TaskResult representa el resultado de una tarea asíncrona
 type TaskResult struct {
 	ID           int        `json:"id" db:"id"`
@@ -78,19 +295,112 @@ type TaskResult struct {
 	VideoID      *uuid.UUID `json:"video_id,omitempty" db:"video_id"`
 	Status       string     `json:"status" db:"status"`
 	ErrorMessage *string    `json:"error_message,omitempty" db:"error_message"`
+	Attempts     int        `json:"attempts" db:"attempts"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
 }
 
 // RankingEntry representa una entrada en el ranking
 type RankingEntry struct {
-	Position int       `json:"position"`
-	VideoID  uuid.UUID `json:"video_id"`
-	Username string    `json:"username"`
-	Title    string    `json:"title"`
-	City     string    `json:"city"`
-	Votes    int       `json:"votes"`
-	VideoURL string    `json:"video_url,omitempty"`
+	Position     int       `json:"position"`
+	VideoID      uuid.UUID `json:"video_id"`
+	Username     string    `json:"username"`
+	Title        string    `json:"title"`
+	City         string    `json:"city"`
+	Votes        int       `json:"votes"`
+	VideoURL     string    `json:"video_url,omitempty"`
+	FallbackURL  string    `json:"fallback_url,omitempty"` // MP4 cuando video_url es un playlist HLS
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	PreviewURL   string    `json:"preview_url,omitempty"`
+}
+
+// RankingChange es una entrada del ranking que cambió de posición o de votos, o que entró al ranking
+type RankingChange struct {
+	RankingEntry
+	PreviousPosition int `json:"previous_position,omitempty"` // 0 si la entrada es nueva
+}
+
+// RankingUpdate es la diferencia entre dos versiones del ranking de una ciudad (o el global)
+type RankingUpdate struct {
+	City    string          `json:"city,omitempty"`
+	Changes []RankingChange `json:"changes"`
+	Removed []uuid.UUID     `json:"removed,omitempty"` // videos que salieron del ranking
+}
+
+// Competition es una edición del tryout, dividida en rondas
+type Competition struct {
+	ID          int       `json:"id" db:"id" example:"1"`
+	Name        string    `json:"name" db:"name" example:"ANB Rising Stars 2025"`
+	Description string    `json:"description,omitempty" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Rounds      []Round   `json:"rounds"`
+}
+
+// CompetitionCreate representa los datos para crear una competencia
+type CompetitionCreate struct {
+	Name        string `json:"name" validate:"required,min=3,max=255" example:"ANB Rising Stars 2025"`
+	Description string `json:"description" example:"Tryout nacional de talentos"`
+}
+
+// Round es una fase de una competencia con su ventana de tiempo
+type Round struct {
+	ID                int        `json:"id" db:"id" example:"1"`
+	CompetitionID     int        `json:"competition_id" db:"competition_id" example:"1"`
+	Name              string     `json:"name" db:"name" example:"Votación regional Bogotá"`
+	Phase             string     `json:"phase" db:"phase" example:"regional"`
+	Status            string     `json:"status" example:"open"` // scheduled, open o closed según la ventana
+	StartsAt          time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt            time.Time  `json:"ends_at" db:"ends_at"`
+	EligibleCities    []string   `json:"eligible_cities" db:"eligible_cities"` // vacío = todas las ciudades
+	QualifyingRoundID *int       `json:"qualifying_round_id,omitempty" db:"qualifying_round_id"`
+	WinnersPerCity    int        `json:"winners_per_city" db:"winners_per_city" example:"3"`
+	VotesPerUser      *int       `json:"votes_per_user,omitempty" db:"votes_per_user" example:"5"` // nil = sin límite
+	ClosedAt          *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+}
+
+// RoundCreate representa los datos para crear una ronda
+type RoundCreate struct {
+	Name              string    `json:"name" validate:"required,min=3,max=255" example:"Votación regional Bogotá"`
+	Phase             string    `json:"phase" validate:"required,oneof=submissions regional national" example:"regional"`
+	StartsAt          time.Time `json:"starts_at" validate:"required"`
+	EndsAt            time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	EligibleCities    []string  `json:"eligible_cities" example:"Bogotá"`
+	QualifyingRoundID *int      `json:"qualifying_round_id,omitempty"`
+	WinnersPerCity    int       `json:"winners_per_city" validate:"omitempty,min=1" example:"3"`
+	VotesPerUser      *int      `json:"votes_per_user,omitempty" validate:"omitempty,min=1" example:"5"`
+}
+
+// RoundWinner es un video clasificado al cerrar una ronda
+type RoundWinner struct {
+	RoundID        int       `json:"round_id"`
+	VideoID        uuid.UUID `json:"video_id"`
+	Title          string    `json:"title"`
+	Username       string    `json:"username"`
+	City           string    `json:"city"`
+	Votes          int       `json:"votes"`
+	CityPosition   int       `json:"city_position"`
+	GlobalPosition int       `json:"global_position"`
+}
+
+// UserVote es un voto vigente del usuario
+type UserVote struct {
+	VideoID uuid.UUID `json:"video_id"`
+	RoundID *int      `json:"round_id,omitempty"`
+	VotedAt time.Time `json:"voted_at"`
+}
+
+// RoundVoteBudget es lo que le queda por votar al usuario en una ronda abierta con presupuesto
+type RoundVoteBudget struct {
+	RoundID        int `json:"round_id" example:"1"`
+	VotesPerUser   int `json:"votes_per_user" example:"5"`
+	RemainingVotes int `json:"remaining_votes" example:"2"`
+}
+
+// UserVotes son los votos vigentes del usuario y sus cupos en las rondas abiertas
+type UserVotes struct {
+	Votes   []UserVote        `json:"votes"`
+	Budgets []RoundVoteBudget `json:"budgets"`
 }
 
 // APIResponse representa una respuesta genérica de la API
@@ -100,11 +410,13 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty" example:"Error en la validación"`
 }
 
-// LoginResponse representa la respuesta del login
+// LoginResponse representa la respuesta del login y de la rotación del refresh token
 type LoginResponse struct {
-	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	TokenType   string `json:"token_type" example:"Bearer"`
-	ExpiresIn   int    `json:"expires_in" example:"3600"`
+	AccessToken      string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType        string `json:"token_type" example:"Bearer"`
+	ExpiresIn        int    `json:"expires_in" example:"900"`
+	RefreshToken     string `json:"refresh_token" example:"q3Vx0f6mR2b9..."`
+	RefreshExpiresIn int    `json:"refresh_expires_in" example:"2592000"`
 }
 
 // VideoStatus constants
@@ -113,12 +425,57 @@ const (
 	VideoStatusProcessing = "processing"
 	VideoStatusProcessed  = "processed"
 	VideoStatusFailed     = "failed"
+	VideoStatusPending    = "pending_review" // procesado, esperando la revisión de un jurado o admin
+	VideoStatusRejected   = "rejected"
+)
+
+// VideoReport resolution constants
+const (
+	ReportResolutionDismissed = "dismissed"
+	ReportResolutionRemoved   = "removed"
+)
+
+// Role constants
+const (
+	RolePlayer = "player" // sube videos y vota
+	RoleVoter  = "voter"  // solo vota
+	RoleJury   = "jury"   // revisa los votos de la competencia
+	RoleAdmin  = "admin"  // opera la competencia por /api/admin
+)
+
+// RoundPhase constants
+const (
+	RoundPhaseSubmissions = "submissions" // inscripciones abiertas, sin votación
+	RoundPhaseRegional    = "regional"
+	RoundPhaseNational    = "national"
+)
+
+// RoundStatus constants
+const (
+	RoundStatusScheduled = "scheduled"
+	RoundStatusOpen      = "open"
+	RoundStatusClosed    = "closed"
+)
+
+// UploadStatus constants
+const (
+	UploadStatusActive     = "active"
+	UploadStatusCompleting = "completing"
+	UploadStatusCompleted  = "completed"
+	UploadStatusAborted    = "aborted"
+)
+
+// UploadType constants
+const (
+	UploadTypeChunked = "chunked"
+	UploadTypeDirect  = "direct"
 )
 
 // TaskStatus constants
 const (
 	TaskStatusPending   = "pending"
 	TaskStatusRunning   = "running"
+	TaskStatusRetrying  = "retrying"
 	TaskStatusCompleted = "completed"
 	TaskStatusFailed    = "failed"
 )
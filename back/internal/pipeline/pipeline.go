@@ -0,0 +1,278 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"back/internal/config"
+	"back/internal/utils"
+)
+
+// Pipeline ejecuta en orden los pasos de un perfil ya validado
+type Pipeline struct {
+	profile *Profile
+	steps   []step
+}
+
+// Job es el estado de procesamiento de un video. Todos sus archivos viven en un
+// directorio temporal propio que se elimina con Cleanup.
+type Job struct {
+	VideoID string
+
+	workDir   string
+	input     string
+	current   string
+	hasAudio  bool
+	thumbnail string
+	preview   string
+	hlsDir    string
+	seq       int
+}
+
+// Result contiene los archivos generados por el pipeline dentro del directorio del trabajo
+type Result struct {
+	OutputPath    string
+	ThumbnailPath string // vacío si el perfil no genera miniatura
+	PreviewPath   string // vacío si el perfil no genera vista previa
+	HLSDir        string // directorio con master.m3u8 y las variantes; vacío si el perfil no genera HLS
+}
+
+// StepError identifica el paso del perfil que falló
+type StepError struct {
+	Index int
+	Step  string
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("pipeline step %d (%s) failed: %v", e.Index+1, e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error { return e.Err }
+
+// New valida el perfil contra la configuración y construye el pipeline
+func New(profile *Profile, cfg *config.Config) (*Pipeline, error) {
+	if len(profile.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline profile %q has no steps", profile.Name)
+	}
+
+	for _, bin := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			return nil, fmt.Errorf("%s not found in PATH: %w", bin, err)
+		}
+	}
+
+	p := &Pipeline{profile: profile}
+	var canvas *scalePadStep
+	hlsIndex := -1
+	thumbnails := 0
+	previews := 0
+
+	for i, sc := range profile.Steps {
+		s, err := buildStep(sc, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline profile %q, step %d (%s): %w", profile.Name, i+1, sc.Type, err)
+		}
+
+		// HLS se genera del video final: después solo pueden ir la miniatura y la vista previa
+		if hlsIndex >= 0 && sc.Type != StepThumbnail && sc.Type != StepPreview {
+			return nil, fmt.Errorf("pipeline profile %q, step %d (%s): only %s and %s may come after the %s step", profile.Name, i+1, sc.Type, StepThumbnail, StepPreview, StepHLS)
+		}
+
+		switch sc.Type {
+		case StepScalePad:
+			canvas = s.(*scalePadStep)
+		case StepIntroOutro:
+			// Los clips se concatenan sin recodificar, así que el video ya debe estar en MP4/H.264
+			if canvas == nil {
+				return nil, fmt.Errorf("pipeline profile %q, step %d (%s): must come after a %s step", profile.Name, i+1, sc.Type, StepScalePad)
+			}
+		case StepThumbnail:
+			thumbnails++
+		case StepPreview:
+			previews++
+		case StepHLS:
+			if canvas == nil {
+				return nil, fmt.Errorf("pipeline profile %q, step %d (%s): must come after a %s step", profile.Name, i+1, sc.Type, StepScalePad)
+			}
+			if err := s.(*hlsStep).fitCanvas(canvas); err != nil {
+				return nil, fmt.Errorf("pipeline profile %q, step %d (%s): %w", profile.Name, i+1, sc.Type, err)
+			}
+			hlsIndex = i
+		}
+
+		p.steps = append(p.steps, s)
+	}
+
+	if canvas == nil {
+		return nil, fmt.Errorf("pipeline profile %q needs a %s step to produce the MP4 output", profile.Name, StepScalePad)
+	}
+	if thumbnails > 1 {
+		return nil, fmt.Errorf("pipeline profile %q has more than one %s step", profile.Name, StepThumbnail)
+	}
+	if previews > 1 {
+		return nil, fmt.Errorf("pipeline profile %q has more than one %s step", profile.Name, StepPreview)
+	}
+
+	return p, nil
+}
+
+// Name retorna el nombre del perfil en uso
+func (p *Pipeline) Name() string { return p.profile.Name }
+
+// Describe resume los pasos del perfil (para logs)
+func (p *Pipeline) Describe() string {
+	names := make([]string, len(p.steps))
+	for i, s := range p.steps {
+		names[i] = s.name()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// NewJob crea el directorio temporal del trabajo. sourceExt es la extensión del archivo
+// original, que se conserva mientras el video no se recodifique.
+func NewJob(videoID, sourceExt string) (*Job, error) {
+	workDir, err := os.MkdirTemp("", fmt.Sprintf("video-%s-", videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	if sourceExt == "" {
+		sourceExt = ".mp4"
+	}
+	input := filepath.Join(workDir, "input"+strings.ToLower(sourceExt))
+
+	return &Job{
+		VideoID:  videoID,
+		workDir:  workDir,
+		input:    input,
+		current:  input,
+		hasAudio: true,
+	}, nil
+}
+
+// InputPath es la ruta donde se debe descargar el video original
+func (j *Job) InputPath() string { return j.input }
+
+// Cleanup elimina el directorio del trabajo y todos sus archivos intermedios
+func (j *Job) Cleanup() {
+	if err := os.RemoveAll(j.workDir); err != nil {
+		log.Printf("Warning: failed to remove job directory %s: %v", j.workDir, err)
+	}
+}
+
+// nextPath reserva el archivo de salida del siguiente paso
+func (j *Job) nextPath(label, ext string) string {
+	j.seq++
+	return filepath.Join(j.workDir, fmt.Sprintf("%02d_%s%s", j.seq, label, ext))
+}
+
+// Run ejecuta los pasos sobre el archivo descargado en job.InputPath().
+// Los pasos de filtro consecutivos (scale_pad, watermark) se combinan en una sola recodificación.
+// Si onProgress no es nil, recibe el paso en curso y el avance total a medida que ffmpeg procesa.
+func (p *Pipeline) Run(ctx context.Context, job *Job, onProgress func(Progress)) (*Result, error) {
+	tracker := newProgressTracker(p.steps, onProgress)
+	for i := 0; i < len(p.steps); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if _, ok := p.steps[i].(filterStep); ok {
+			end := i
+			var group []filterStep
+			for end < len(p.steps) {
+				fs, ok := p.steps[end].(filterStep)
+				if !ok {
+					break
+				}
+				group = append(group, fs)
+				end++
+			}
+
+			stepCtx := tracker.start(ctx, job, describeGroup(group), transcodeWeight)
+			if err := runFilterGroup(stepCtx, job, group); err != nil {
+				return nil, &StepError{Index: i, Step: describeGroup(group), Err: err}
+			}
+			tracker.finish(transcodeWeight)
+			i = end
+			continue
+		}
+
+		fs := p.steps[i].(fileStep)
+		stepCtx := tracker.start(ctx, job, fs.name(), stepWeights[fs.name()])
+		if err := fs.run(stepCtx, job); err != nil {
+			return nil, &StepError{Index: i, Step: fs.name(), Err: err}
+		}
+		tracker.finish(stepWeights[fs.name()])
+		i++
+	}
+
+	return &Result{OutputPath: job.current, ThumbnailPath: job.thumbnail, PreviewPath: job.preview, HLSDir: job.hlsDir}, nil
+}
+
+// runFilterGroup recodifica el video aplicando los filtros del grupo en un solo paso de ffmpeg.
+// Si falla y el grupo tiene pasos opcionales, reintenta sin ellos.
+func runFilterGroup(ctx context.Context, job *Job, group []filterStep) error {
+	err := transcode(ctx, job, group)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	var required []filterStep
+	for _, fs := range group {
+		if !fs.optional() {
+			required = append(required, fs)
+		}
+	}
+	if len(required) == len(group) {
+		return err
+	}
+
+	log.Printf("Warning: %s failed for video %s, retrying without optional steps: %v", describeGroup(group), job.VideoID, err)
+	if len(required) == 0 {
+		return nil
+	}
+	return transcode(ctx, job, required)
+}
+
+func transcode(ctx context.Context, job *Job, group []filterStep) error {
+	opts := utils.EncodeOptions{Preset: defaultPreset, CRF: defaultCRF, KeepAudio: job.hasAudio}
+
+	var chain []string
+	var overlays []string
+	in := "[0:v]"
+	for i, fs := range group {
+		out := fmt.Sprintf("[v%d]", i+1)
+		if i == len(group)-1 {
+			out = "[out]"
+		}
+
+		segment, overlay := fs.filter(in, out, len(overlays)+1)
+		chain = append(chain, segment)
+		if overlay != "" {
+			overlays = append(overlays, overlay)
+		}
+		fs.encodeOptions(&opts)
+		in = out
+	}
+
+	dst := job.nextPath("encoded", ".mp4")
+	if err := utils.TranscodeVideo(ctx, job.current, dst, strings.Join(chain, ";"), overlays, opts); err != nil {
+		return err
+	}
+	job.current = dst
+	return nil
+}
+
+func describeGroup(group []filterStep) string {
+	names := make([]string, len(group))
+	for i, fs := range group {
+		names[i] = fs.name()
+	}
+	return strings.Join(names, "+")
+}
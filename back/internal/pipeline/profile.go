@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"back/internal/config"
+	"back/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tipos de paso soportados en un perfil
+const (
+	StepTrim       = "trim"
+	StepStripAudio = "strip_audio"
+	StepScalePad   = "scale_pad"
+	StepWatermark  = "watermark"
+	StepIntroOutro = "intro_outro"
+	StepThumbnail  = "thumbnail"
+	StepHLS        = "hls"
+	StepPreview    = "preview"
+)
+
+// DefaultWatermarkPath es la marca de agua que incluye la imagen del worker
+const DefaultWatermarkPath = "/app/assets/anb_watermark.png"
+
+// Profile describe el procesamiento que se aplica a cada video, en orden
+type Profile struct {
+	Name  string       `json:"name" yaml:"name"`
+	Steps []StepConfig `json:"steps" yaml:"steps"`
+}
+
+// StepConfig es la configuración de un paso. Cada tipo usa solo sus campos;
+// los demás se ignoran.
+type StepConfig struct {
+	Type string `json:"type" yaml:"type"`
+
+	// Optional permite continuar sin el paso si falla (watermark, intro_outro)
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
+
+	// trim: duración máxima en segundos (0 = MAX_VIDEO_DURATION)
+	MaxDuration int `json:"max_duration,omitempty" yaml:"max_duration,omitempty"`
+
+	// scale_pad: caja máxima "ANCHOxALTO" y relación de aspecto "W:H" del lienzo final
+	// (vacíos = OUTPUT_RESOLUTION / OUTPUT_ASPECT_RATIO), y calidad de libx264
+	Resolution  string `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+	AspectRatio string `json:"aspect_ratio,omitempty" yaml:"aspect_ratio,omitempty"`
+	Preset      string `json:"preset,omitempty" yaml:"preset,omitempty"`
+	CRF         int    `json:"crf,omitempty" yaml:"crf,omitempty"`
+
+	// watermark: imagen, esquina (top-left, top-right, bottom-left, bottom-right, center) y margen en px
+	Image    string `json:"image,omitempty" yaml:"image,omitempty"`
+	Position string `json:"position,omitempty" yaml:"position,omitempty"`
+	Margin   int    `json:"margin,omitempty" yaml:"margin,omitempty"`
+
+	// intro_outro: clips MP4 que se agregan al inicio y al final (al menos uno)
+	Intro string `json:"intro,omitempty" yaml:"intro,omitempty"`
+	Outro string `json:"outro,omitempty" yaml:"outro,omitempty"`
+
+	// thumbnail: segundo del fotograma y ancho en px de la miniatura; con best_frame se elige
+	// el fotograma más representativo de los segundos siguientes a at (evita fotogramas negros)
+	// preview: segundo de inicio, ancho en px, duración en segundos, cuadros por segundo y
+	// formato de la vista previa animada (gif o webp)
+	At        float64 `json:"at,omitempty" yaml:"at,omitempty"`
+	Width     int     `json:"width,omitempty" yaml:"width,omitempty"`
+	BestFrame bool    `json:"best_frame,omitempty" yaml:"best_frame,omitempty"`
+	Duration  float64 `json:"duration,omitempty" yaml:"duration,omitempty"`
+	FPS       int     `json:"fps,omitempty" yaml:"fps,omitempty"`
+	Format    string  `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// hls: variantes de la escalera (vacío = 360p/480p/720p hasta el alto del lienzo) y duración
+	// de cada segmento en segundos; usa preset de scale_pad si no se indica
+	Renditions      []RenditionConfig `json:"renditions,omitempty" yaml:"renditions,omitempty"`
+	SegmentDuration int               `json:"segment_duration,omitempty" yaml:"segment_duration,omitempty"`
+}
+
+// RenditionConfig es una variante HLS: alto en px y bitrate de video en kbps
+type RenditionConfig struct {
+	Height  int `json:"height" yaml:"height"`
+	Bitrate int `json:"bitrate" yaml:"bitrate"`
+}
+
+// LoadProfile lee un perfil desde un archivo JSON o YAML (según la extensión)
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline profile: %w", err)
+	}
+
+	var profile Profile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		err = dec.Decode(&profile)
+	default:
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&profile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline profile %s: %w", path, err)
+	}
+
+	if profile.Name == "" {
+		profile.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return &profile, nil
+}
+
+// DefaultProfile reproduce el procesamiento original: recorte, sin audio, escalado a la
+// resolución de salida y marca de agua (si la imagen está disponible), más la miniatura y
+// la vista previa animada para las grillas
+func DefaultProfile(cfg *config.Config) *Profile {
+	profile := &Profile{
+		Name: "default",
+		Steps: []StepConfig{
+			{Type: StepTrim},
+			{Type: StepStripAudio},
+			{Type: StepScalePad},
+		},
+	}
+
+	if utils.FileExists(DefaultWatermarkPath) {
+		profile.Steps = append(profile.Steps, StepConfig{
+			Type:     StepWatermark,
+			Image:    DefaultWatermarkPath,
+			Optional: true,
+		})
+	}
+
+	profile.Steps = append(profile.Steps,
+		StepConfig{Type: StepThumbnail, At: 1, BestFrame: true},
+		StepConfig{Type: StepPreview},
+	)
+
+	return profile
+}
+
+// Load obtiene el perfil configurado en PIPELINE_PROFILE (o el perfil por defecto) y lo valida
+func Load(cfg *config.Config) (*Pipeline, error) {
+	profile := DefaultProfile(cfg)
+	if cfg.PipelineProfile != "" {
+		loaded, err := LoadProfile(cfg.PipelineProfile)
+		if err != nil {
+			return nil, err
+		}
+		profile = loaded
+	}
+
+	return New(profile, cfg)
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+
+	"back/internal/utils"
+)
+
+// Progress es el avance de Run: Stage es el paso en curso (nombre del paso, o de los filtros
+// combinados como "scale_pad+watermark") y Percent el avance total del pipeline (0-100)
+type Progress struct {
+	Stage   string
+	Percent int
+}
+
+// transcodeWeight es el peso de una recodificación de filtros combinados en el avance total
+const transcodeWeight = 6
+
+// stepWeights es el peso relativo de cada paso de archivo: las copias de streams son casi
+// instantáneas y las recodificaciones dominan el tiempo de procesamiento
+var stepWeights = map[string]int{
+	StepTrim:       1,
+	StepStripAudio: 1,
+	StepIntroOutro: 1,
+	StepThumbnail:  1,
+	StepPreview:    2,
+	StepHLS:        8,
+}
+
+// progressTracker traduce el avance de cada comando ffmpeg (segundos escritos) al avance total
+type progressTracker struct {
+	onProgress func(Progress)
+	total      int
+	done       int
+}
+
+func newProgressTracker(steps []step, onProgress func(Progress)) *progressTracker {
+	t := &progressTracker{onProgress: onProgress}
+	inGroup := false
+	for _, s := range steps {
+		if _, ok := s.(filterStep); ok {
+			// Los filtros consecutivos se ejecutan en una sola recodificación
+			if !inGroup {
+				t.total += transcodeWeight
+			}
+			inGroup = true
+			continue
+		}
+		inGroup = false
+		t.total += stepWeights[s.name()]
+	}
+	return t
+}
+
+// start reporta el inicio de un paso y retorna el contexto con el que sus comandos ffmpeg
+// reportan el avance, proporcional a la duración del video de entrada
+func (t *progressTracker) start(ctx context.Context, job *Job, stage string, weight int) context.Context {
+	if t.onProgress == nil || t.total == 0 {
+		return ctx
+	}
+	t.report(stage, 0, weight)
+
+	duration, err := utils.GetVideoDuration(ctx, job.current)
+	if err != nil || duration <= 0 {
+		return ctx
+	}
+	return utils.WithProgress(ctx, func(processed float64) {
+		t.report(stage, min(processed/duration, 1), weight)
+	})
+}
+
+// finish suma el peso del paso terminado al avance acumulado
+func (t *progressTracker) finish(weight int) {
+	t.done += weight
+}
+
+func (t *progressTracker) report(stage string, fraction float64, weight int) {
+	percent := 100 * (float64(t.done) + fraction*float64(weight)) / float64(t.total)
+	t.onProgress(Progress{Stage: stage, Percent: int(percent)})
+}
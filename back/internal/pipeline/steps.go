@@ -0,0 +1,491 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"back/internal/config"
+	"back/internal/utils"
+)
+
+const (
+	defaultPreset          = "ultrafast"
+	defaultCRF             = 28
+	defaultWatermarkMargin = 10
+	defaultThumbnailWidth  = 640
+	defaultSegmentDuration = 4
+	defaultPreviewWidth    = 320
+	defaultPreviewDuration = 3
+	defaultPreviewFPS      = 10
+	defaultPreviewFormat   = "gif"
+)
+
+// defaultRenditions es la escalera HLS cuando el perfil no define variantes
+var defaultRenditions = []RenditionConfig{
+	{Height: 360, Bitrate: 800},
+	{Height: 480, Bitrate: 1400},
+	{Height: 720, Bitrate: 2800},
+}
+
+var validPresets = map[string]bool{
+	"ultrafast": true, "superfast": true, "veryfast": true, "faster": true, "fast": true,
+	"medium": true, "slow": true, "slower": true, "veryslow": true,
+}
+
+type step interface {
+	name() string
+}
+
+// fileStep transforma el archivo actual del trabajo en uno nuevo con su propio comando
+type fileStep interface {
+	step
+	run(ctx context.Context, job *Job) error
+}
+
+// filterStep aporta un segmento de -filter_complex a una recodificación compartida
+type filterStep interface {
+	step
+	// filter retorna el segmento que lee de in y escribe en out; si necesita una entrada
+	// adicional (imagen), la retorna como overlay y la referencia como [overlayIndex:v]
+	filter(in, out string, overlayIndex int) (segment string, overlay string)
+	encodeOptions(opts *utils.EncodeOptions)
+	optional() bool
+}
+
+func buildStep(sc StepConfig, cfg *config.Config) (step, error) {
+	switch sc.Type {
+	case StepTrim:
+		maxDuration := sc.MaxDuration
+		if maxDuration == 0 {
+			maxDuration = cfg.MaxVideoDuration
+		}
+		if maxDuration <= 0 {
+			return nil, fmt.Errorf("max_duration must be positive")
+		}
+		return &trimStep{maxDuration: maxDuration}, nil
+
+	case StepStripAudio:
+		return &stripAudioStep{}, nil
+
+	case StepScalePad:
+		resolution := sc.Resolution
+		if resolution == "" {
+			resolution = cfg.OutputResolution
+		}
+		aspectRatio := sc.AspectRatio
+		if aspectRatio == "" {
+			aspectRatio = cfg.OutputAspectRatio
+		}
+		width, height, err := canvasSize(resolution, aspectRatio)
+		if err != nil {
+			return nil, err
+		}
+
+		preset := sc.Preset
+		if preset == "" {
+			preset = defaultPreset
+		}
+		if !validPresets[preset] {
+			return nil, fmt.Errorf("unknown preset %q", preset)
+		}
+		crf := sc.CRF
+		if crf == 0 {
+			crf = defaultCRF
+		}
+		if crf < 0 || crf > 51 {
+			return nil, fmt.Errorf("crf must be between 0 and 51 (0 = default %d)", defaultCRF)
+		}
+		return &scalePadStep{width: width, height: height, preset: preset, crf: crf}, nil
+
+	case StepWatermark:
+		if sc.Image == "" {
+			return nil, fmt.Errorf("image is required")
+		}
+		if !utils.FileExists(sc.Image) {
+			return nil, fmt.Errorf("image %s not found", sc.Image)
+		}
+		position := sc.Position
+		if position == "" {
+			position = "top-right"
+		}
+		margin := sc.Margin
+		if margin == 0 {
+			margin = defaultWatermarkMargin
+		}
+		if margin < 0 {
+			return nil, fmt.Errorf("margin must not be negative")
+		}
+		overlay, err := overlayPosition(position, margin)
+		if err != nil {
+			return nil, err
+		}
+		return &watermarkStep{image: sc.Image, overlay: overlay, isOptional: sc.Optional}, nil
+
+	case StepIntroOutro:
+		if sc.Intro == "" && sc.Outro == "" {
+			return nil, fmt.Errorf("intro or outro is required")
+		}
+		for _, clip := range []string{sc.Intro, sc.Outro} {
+			if clip == "" {
+				continue
+			}
+			if !utils.FileExists(clip) {
+				return nil, fmt.Errorf("clip %s not found", clip)
+			}
+			// ffmpeg concat lee la lista con comillas simples
+			if strings.Contains(clip, "'") {
+				return nil, fmt.Errorf("clip path %s must not contain quotes", clip)
+			}
+		}
+		return &introOutroStep{intro: sc.Intro, outro: sc.Outro, isOptional: sc.Optional}, nil
+
+	case StepThumbnail:
+		if sc.At < 0 {
+			return nil, fmt.Errorf("at must not be negative")
+		}
+		width := sc.Width
+		if width == 0 {
+			width = defaultThumbnailWidth
+		}
+		if width < 0 {
+			return nil, fmt.Errorf("width must be positive")
+		}
+		return &thumbnailStep{at: sc.At, width: width, bestFrame: sc.BestFrame}, nil
+
+	case StepPreview:
+		if sc.At < 0 {
+			return nil, fmt.Errorf("at must not be negative")
+		}
+		width := sc.Width
+		if width == 0 {
+			width = defaultPreviewWidth
+		}
+		duration := sc.Duration
+		if duration == 0 {
+			duration = defaultPreviewDuration
+		}
+		fps := sc.FPS
+		if fps == 0 {
+			fps = defaultPreviewFPS
+		}
+		if width < 0 || duration < 0 || fps < 0 {
+			return nil, fmt.Errorf("width, duration and fps must be positive")
+		}
+		if duration > 10 || fps > 30 {
+			return nil, fmt.Errorf("preview is limited to 10 seconds at 30 fps")
+		}
+		format := sc.Format
+		if format == "" {
+			format = defaultPreviewFormat
+		}
+		if format != "gif" && format != "webp" {
+			return nil, fmt.Errorf("unknown preview format %q (gif or webp)", format)
+		}
+		return &previewStep{at: sc.At, width: width, duration: duration, fps: fps, format: format}, nil
+
+	case StepHLS:
+		segment := sc.SegmentDuration
+		if segment == 0 {
+			segment = defaultSegmentDuration
+		}
+		if segment < 1 || segment > 30 {
+			return nil, fmt.Errorf("segment_duration must be between 1 and 30")
+		}
+		if sc.Preset != "" && !validPresets[sc.Preset] {
+			return nil, fmt.Errorf("unknown preset %q", sc.Preset)
+		}
+		seen := make(map[int]bool)
+		for _, rc := range sc.Renditions {
+			if rc.Height <= 0 || rc.Height%2 != 0 {
+				return nil, fmt.Errorf("rendition height must be a positive even number")
+			}
+			if rc.Bitrate <= 0 {
+				return nil, fmt.Errorf("rendition bitrate must be positive")
+			}
+			if seen[rc.Height] {
+				return nil, fmt.Errorf("duplicate rendition height %d", rc.Height)
+			}
+			seen[rc.Height] = true
+		}
+		return &hlsStep{renditions: sc.Renditions, segmentDuration: segment, preset: sc.Preset}, nil
+	}
+
+	return nil, fmt.Errorf("unknown step type %q", sc.Type)
+}
+
+// canvasSize calcula el lienzo de salida: el mayor tamaño con la relación de aspecto pedida
+// que cabe en la resolución, redondeado a pares (requisito de H.264)
+func canvasSize(resolution, aspectRatio string) (int, int, error) {
+	boxW, boxH, err := parsePair(resolution, "x")
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid resolution %q: %w", resolution, err)
+	}
+	if aspectRatio == "" {
+		return boxW &^ 1, boxH &^ 1, nil
+	}
+	aw, ah, err := parsePair(aspectRatio, ":")
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q: %w", aspectRatio, err)
+	}
+
+	width, height := boxW, boxW*ah/aw
+	if height > boxH {
+		width, height = boxH*aw/ah, boxH
+	}
+	return width &^ 1, height &^ 1, nil
+}
+
+func parsePair(value, sep string) (int, int, error) {
+	parts := strings.Split(value, sep)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected format A%sB", sep)
+	}
+	a, errA := strconv.Atoi(strings.TrimSpace(parts[0]))
+	b, errB := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if errA != nil || errB != nil || a <= 0 || b <= 0 {
+		return 0, 0, fmt.Errorf("values must be positive integers")
+	}
+	return a, b, nil
+}
+
+func overlayPosition(position string, margin int) (string, error) {
+	m := strconv.Itoa(margin)
+	switch position {
+	case "top-left":
+		return m + ":" + m, nil
+	case "top-right":
+		return "main_w-overlay_w-" + m + ":" + m, nil
+	case "bottom-left":
+		return m + ":main_h-overlay_h-" + m, nil
+	case "bottom-right":
+		return "main_w-overlay_w-" + m + ":main_h-overlay_h-" + m, nil
+	case "center":
+		return "(main_w-overlay_w)/2:(main_h-overlay_h)/2", nil
+	}
+	return "", fmt.Errorf("unknown position %q", position)
+}
+
+// trimStep recorta el video a la duración máxima (sin recodificar)
+type trimStep struct {
+	maxDuration int
+}
+
+func (s *trimStep) name() string { return StepTrim }
+
+func (s *trimStep) run(ctx context.Context, job *Job) error {
+	duration, err := utils.GetVideoDuration(ctx, job.current)
+	if err != nil {
+		return fmt.Errorf("failed to get video duration: %w", err)
+	}
+	if duration <= float64(s.maxDuration) {
+		return nil
+	}
+
+	dst := job.nextPath("trimmed", filepath.Ext(job.current))
+	if err := utils.TrimVideo(ctx, job.current, dst, s.maxDuration); err != nil {
+		return err
+	}
+	job.current = dst
+	return nil
+}
+
+// stripAudioStep elimina el audio (sin recodificar el video)
+type stripAudioStep struct{}
+
+func (s *stripAudioStep) name() string { return StepStripAudio }
+
+func (s *stripAudioStep) run(ctx context.Context, job *Job) error {
+	dst := job.nextPath("noaudio", filepath.Ext(job.current))
+	if err := utils.RemoveAudio(ctx, job.current, dst); err != nil {
+		return err
+	}
+	job.current = dst
+	job.hasAudio = false
+	return nil
+}
+
+// scalePadStep escala el video para que quepa en el lienzo (sin agrandarlo) y completa con barras
+type scalePadStep struct {
+	width, height int
+	preset        string
+	crf           int
+}
+
+func (s *scalePadStep) name() string { return StepScalePad }
+
+func (s *scalePadStep) filter(in, out string, _ int) (string, string) {
+	return fmt.Sprintf("%sscale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1%s",
+		in, s.width, s.height, s.width, s.height, out), ""
+}
+
+func (s *scalePadStep) encodeOptions(opts *utils.EncodeOptions) {
+	opts.Preset = s.preset
+	opts.CRF = s.crf
+}
+
+func (s *scalePadStep) optional() bool { return false }
+
+// watermarkStep superpone una imagen en la posición configurada
+type watermarkStep struct {
+	image      string
+	overlay    string
+	isOptional bool
+}
+
+func (s *watermarkStep) name() string { return StepWatermark }
+
+func (s *watermarkStep) filter(in, out string, overlayIndex int) (string, string) {
+	return fmt.Sprintf("%s[%d:v]overlay=%s%s", in, overlayIndex, s.overlay, out), s.image
+}
+
+func (s *watermarkStep) encodeOptions(*utils.EncodeOptions) {}
+
+func (s *watermarkStep) optional() bool { return s.isOptional }
+
+// introOutroStep concatena los clips de apertura y cierre
+type introOutroStep struct {
+	intro, outro string
+	isOptional   bool
+}
+
+func (s *introOutroStep) name() string { return StepIntroOutro }
+
+func (s *introOutroStep) run(ctx context.Context, job *Job) error {
+	dst := job.nextPath("introoutro", ".mp4")
+	if err := utils.AddOpeningClosing(ctx, job.current, dst, s.intro, s.outro); err != nil {
+		if s.isOptional {
+			log.Printf("Warning: %s failed for video %s, continuing without it: %v", s.name(), job.VideoID, err)
+			return nil
+		}
+		return err
+	}
+	job.current = dst
+	return nil
+}
+
+// thumbnailStep genera una miniatura JPEG del video actual sin modificarlo
+type thumbnailStep struct {
+	at        float64
+	width     int
+	bestFrame bool
+}
+
+func (s *thumbnailStep) name() string { return StepThumbnail }
+
+func (s *thumbnailStep) run(ctx context.Context, job *Job) error {
+	at := s.at
+	if duration, err := utils.GetVideoDuration(ctx, job.current); err == nil && at >= duration {
+		// Si el video es más corto, tomar el fotograma de la mitad
+		at = duration / 2
+	}
+
+	dst := filepath.Join(job.workDir, "thumbnail.jpg")
+	extract := utils.ExtractThumbnail
+	if s.bestFrame {
+		extract = utils.ExtractBestThumbnail
+	}
+	if err := extract(ctx, job.current, dst, at, s.width); err != nil {
+		return err
+	}
+	job.thumbnail = dst
+	return nil
+}
+
+// previewStep genera una vista previa animada, corta y de baja resolución, sin modificar el video
+type previewStep struct {
+	at       float64
+	width    int
+	duration float64
+	fps      int
+	format   string
+}
+
+func (s *previewStep) name() string { return StepPreview }
+
+func (s *previewStep) run(ctx context.Context, job *Job) error {
+	at := s.at
+	if duration, err := utils.GetVideoDuration(ctx, job.current); err == nil && at+s.duration > duration {
+		// Si el video es más corto, empezar antes (o desde el inicio)
+		at = max(duration-s.duration, 0)
+	}
+
+	dst := filepath.Join(job.workDir, "preview."+s.format)
+	if err := utils.CreateAnimatedPreview(ctx, job.current, dst, at, s.duration, s.width, s.fps); err != nil {
+		return err
+	}
+	job.preview = dst
+	return nil
+}
+
+// hlsStep genera la escalera HLS a partir del video ya codificado. No cambia job.current, que
+// se conserva como MP4 de respaldo.
+type hlsStep struct {
+	renditions      []RenditionConfig
+	segmentDuration int
+	preset          string
+}
+
+func (s *hlsStep) name() string { return StepHLS }
+
+// fitCanvas ajusta las variantes al lienzo de scale_pad: valida las configuradas o, si no hay,
+// toma las variantes por defecto que caben (como mínimo la más baja)
+func (s *hlsStep) fitCanvas(canvas *scalePadStep) error {
+	if s.preset == "" {
+		s.preset = canvas.preset
+	}
+
+	if len(s.renditions) > 0 {
+		for _, rc := range s.renditions {
+			if rc.Height > canvas.height {
+				return fmt.Errorf("rendition height %d exceeds the %s canvas height %d", rc.Height, StepScalePad, canvas.height)
+			}
+		}
+	} else {
+		for _, rc := range defaultRenditions {
+			if rc.Height <= canvas.height {
+				s.renditions = append(s.renditions, rc)
+			}
+		}
+		if len(s.renditions) == 0 {
+			s.renditions = []RenditionConfig{{Height: canvas.height, Bitrate: defaultRenditions[0].Bitrate}}
+		}
+	}
+
+	sort.Slice(s.renditions, func(i, j int) bool { return s.renditions[i].Height < s.renditions[j].Height })
+	return nil
+}
+
+func (s *hlsStep) run(ctx context.Context, job *Job) error {
+	renditions := make([]utils.HLSRendition, len(s.renditions))
+	for i, rc := range s.renditions {
+		renditions[i] = utils.HLSRendition{Name: fmt.Sprintf("%dp", rc.Height), Height: rc.Height, Bitrate: rc.Bitrate}
+	}
+
+	dir := filepath.Join(job.workDir, "hls")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create HLS directory: %w", err)
+	}
+
+	// Cada variante referencia su pista de audio, así que se verifica que el video la tenga
+	keepAudio := job.hasAudio
+	if keepAudio {
+		probe, err := utils.ProbeVideo(ctx, job.current)
+		if err != nil {
+			return fmt.Errorf("failed to probe video: %w", err)
+		}
+		keepAudio = probe.HasAudio
+	}
+
+	opts := utils.EncodeOptions{Preset: s.preset, KeepAudio: keepAudio}
+	if err := utils.EncodeHLS(ctx, job.current, dir, renditions, s.segmentDuration, opts); err != nil {
+		return err
+	}
+	job.hlsDir = dir
+	return nil
+}
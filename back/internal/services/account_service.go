@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+	"back/internal/services/mailer"
+	"back/internal/services/storage"
+	"back/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Propósitos de los tokens de cuenta enviados por correo
+const (
+	AccountTokenVerifyEmail   = "verify_email"
+	AccountTokenResetPassword = "reset_password"
+)
+
+// accountTokenResendInterval evita enviar en ráfaga correos del mismo tipo al mismo usuario
+const accountTokenResendInterval = time.Minute
+
+// Errores de la verificación de correo y el restablecimiento de contraseña
+var (
+	ErrInvalidAccountToken   = errors.New("invalid, expired or already used token")
+	ErrEmailAlreadyVerified  = errors.New("email already verified")
+	ErrAccountTokenThrottled = errors.New("a token was requested too recently")
+)
+
+// AccountService verifica el correo de los usuarios y restablece contraseñas con tokens firmados
+// de un solo uso enviados por correo. El token es "<aleatorio>.<firma>": la firma HMAC liga el
+// token a su propósito y descarta los falsificados sin consultar la base de datos, donde solo se
+// guarda el hash de la parte aleatoria.
+type AccountService struct {
+	db             *sql.DB
+	cfg            *config.Config
+	mailer         mailer.Mailer
+	sessions       *SessionService
+	storage        storage.Storage
+	rankingService *RankingService
+	signingKey     []byte
+}
+
+func NewAccountService(db *sql.DB, cfg *config.Config, mail mailer.Mailer, sessions *SessionService, fileStorage storage.Storage, rankingService *RankingService) *AccountService {
+	return &AccountService{
+		db:             db,
+		cfg:            cfg,
+		mailer:         mail,
+		sessions:       sessions,
+		storage:        fileStorage,
+		rankingService: rankingService,
+		signingKey:     []byte(cfg.JWTSecret),
+	}
+}
+
+// SendVerification envía al usuario el enlace para verificar su correo
+func (s *AccountService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issue(ctx, user.ID, AccountTokenVerifyEmail, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifica tu correo en ANB Rising Stars",
+		Body: fmt.Sprintf("Hola %s,\n\nPara verificar tu correo abre este enlace (vence en %s):\n\n%s\n\nSi no creaste una cuenta en ANB Rising Stars, ignora este mensaje.",
+			user.FirstName, s.cfg.EmailVerificationTTL, s.link(AccountTokenVerifyEmail, token)),
+	})
+}
+
+// VerifyEmail marca como verificado el correo del usuario del token
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consume(ctx, tx, token, AccountTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`,
+		userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RequestPasswordReset envía el enlace para restablecer la contraseña. Si el correo no está
+// registrado no hace nada, para no revelar qué correos tienen cuenta.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	var userID int
+	var firstName string
+	err := s.db.QueryRowContext(ctx, `SELECT id, first_name FROM users WHERE email = $1`, email).Scan(&userID, &firstName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issue(ctx, userID, AccountTokenResetPassword, s.cfg.PasswordResetTTL)
+	if errors.Is(err, ErrAccountTokenThrottled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Restablece tu contraseña de ANB Rising Stars",
+		Body: fmt.Sprintf("Hola %s,\n\nPara elegir una nueva contraseña abre este enlace (vence en %s):\n\n%s\n\nSi no lo solicitaste, ignora este mensaje: tu contraseña no cambiará.",
+			firstName, s.cfg.PasswordResetTTL, s.link(AccountTokenResetPassword, token)),
+	})
+}
+
+// ResetPassword cambia la contraseña del usuario del token y cierra todas sus sesiones. Recibir
+// el enlace prueba que el usuario controla el correo, así que también queda verificado.
+func (s *AccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consume(ctx, tx, token, AccountTokenResetPassword)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users SET password_hash = $1, email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $2`, passwordHash, userID); err != nil {
+		return err
+	}
+	// Los demás enlaces de restablecimiento pendientes dejan de servir
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, userID, AccountTokenResetPassword); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if _, err := s.sessions.RevokeAll(userID); err != nil {
+		log.Printf("Warning: failed to revoke sessions of user %d after password reset: %v", userID, err)
+	}
+	return nil
+}
+
+// IsEmailVerified indica si el usuario verificó su correo
+func (s *AccountService) IsEmailVerified(userID int) (bool, error) {
+	var verified bool
+	err := s.db.QueryRow(`SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&verified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return verified, err
+}
+
+// PurgeUserFiles borra del storage los archivos del usuario: los de sus videos (original,
+// procesado, HLS, miniatura y vista previa) y los de sus subidas sin completar. Va antes de borrar
+// la cuenta, porque el borrado en cascada de las filas pierde las rutas. Un archivo que no se
+// puede borrar solo se registra en el log, para no impedir el borrado de la cuenta.
+func (s *AccountService) PurgeUserFiles(ctx context.Context, userID int) error {
+	// Las subidas activas se abortan primero para que no lleguen más partes
+	uploads, err := s.db.QueryContext(ctx, `
+		UPDATE upload_sessions SET status = $1, updated_at = NOW()
+		WHERE user_id = $2 AND status = $3
+		RETURNING upload_type, storage_path, COALESCE(storage_upload_id, '')`,
+		models.UploadStatusAborted, userID, models.UploadStatusActive)
+	if err != nil {
+		return err
+	}
+	defer uploads.Close()
+
+	for uploads.Next() {
+		var uploadType, storagePath, storageUploadID string
+		if err := uploads.Scan(&uploadType, &storagePath, &storageUploadID); err != nil {
+			return err
+		}
+		if err := discardUploadData(s.storage, uploadType, storagePath, storageUploadID); err != nil {
+			log.Printf("Warning: failed to discard upload %s of user %d: %v", storagePath, userID, err)
+		}
+	}
+	if err := uploads.Err(); err != nil {
+		return err
+	}
+
+	videos, err := s.db.QueryContext(ctx, `
+		SELECT id, COALESCE(original_url, ''), COALESCE(processed_url, ''), COALESCE(thumbnail_url, ''), COALESCE(preview_url, '')
+		FROM videos WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	defer videos.Close()
+
+	for videos.Next() {
+		var videoID, original, processed, thumbnail, preview string
+		if err := videos.Scan(&videoID, &original, &processed, &thumbnail, &preview); err != nil {
+			return err
+		}
+
+		var files []string
+		if original != "" {
+			files = append(files, original)
+		}
+		if processed != "" {
+			// Con HLS, processed_url es el playlist y el MP4 queda como respaldo
+			files = append(files, fmt.Sprintf("processed/%s_processed.mp4", videoID))
+			if isHLSPath(processed) {
+				s.deleteUserFile(userID, fmt.Sprintf("processed/%s", videoID), s.storage.DeletePrefix)
+			}
+		}
+		for _, media := range []string{thumbnail, preview} {
+			if media != "" {
+				files = append(files, storage.ProcessedPathFromURL(media))
+			}
+		}
+		for _, path := range files {
+			s.deleteUserFile(userID, path, s.storage.DeleteFile)
+		}
+	}
+	return videos.Err()
+}
+
+// DeleteAccount borra el usuario; sus videos, votos y sesiones caen en cascada. Sus videos
+// publicados salen del caché de rankings y se avisa a los rankings en vivo. Los archivos se borran
+// antes con PurgeUserFiles.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM videos WHERE user_id = $1 AND status = 'processed' AND is_public = true`, userID)
+	if err != nil {
+		return err
+	}
+	var published []uuid.UUID
+	for rows.Next() {
+		var videoID uuid.UUID
+		if err := rows.Scan(&videoID); err != nil {
+			rows.Close()
+			return err
+		}
+		published = append(published, videoID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if len(published) > 0 {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_notify('`+rankingEventsChannel+`', 'account')`); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// La cuenta ya no existe: si el caché falla, la próxima reconciliación lo corrige
+	for _, videoID := range published {
+		if err := s.rankingService.RemoveVideo(context.Background(), videoID); err != nil {
+			log.Printf("Warning: failed to remove video %s of deleted user %d from ranking cache: %v", videoID, userID, err)
+		}
+	}
+	return nil
+}
+
+// deleteUserFile borra un archivo (o prefijo) del usuario; los que ya no existen se ignoran
+func (s *AccountService) deleteUserFile(userID int, path string, remove func(string) error) {
+	if err := remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Warning: failed to delete %s of user %d: %v", path, userID, err)
+	}
+}
+
+// issue genera un token para el usuario y guarda el hash de su parte aleatoria
+func (s *AccountService) issue(ctx context.Context, userID int, purpose string, ttl time.Duration) (string, error) {
+	var recent bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM account_tokens WHERE user_id = $1 AND purpose = $2 AND created_at > NOW() - make_interval(secs => $3))`,
+		userID, purpose, accountTokenResendInterval.Seconds()).Scan(&recent)
+	if err != nil {
+		return "", err
+	}
+	if recent {
+		return "", ErrAccountTokenThrottled
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))`,
+		userID, purpose, hashAccountToken(secret), ttl.Seconds()); err != nil {
+		return "", err
+	}
+	return secret + "." + s.sign(purpose, secret), nil
+}
+
+// consume verifica la firma del token y lo marca como usado. Retorna el usuario del token.
+func (s *AccountService) consume(ctx context.Context, tx *sql.Tx, token, purpose string) (int, error) {
+	secret, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(purpose, secret))) {
+		return 0, ErrInvalidAccountToken
+	}
+
+	var userID int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`, hashAccountToken(secret), purpose).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidAccountToken
+	}
+	return userID, err
+}
+
+func (s *AccountService) sign(purpose, secret string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(purpose + "." + secret))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// link arma el enlace del frontend que recibe el token en el parámetro del propósito
+func (s *AccountService) link(purpose, token string) string {
+	return fmt.Sprintf("%s/?%s=%s", strings.TrimRight(s.cfg.AppBaseURL, "/"), purpose, token)
+}
+
+func hashAccountToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
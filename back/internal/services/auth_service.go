@@ -36,10 +36,14 @@ func (s *AuthService) EmailExists(email string) (bool, error) {
 // CreateUser crea un nuevo usuario en la base de datos
 func (s *AuthService) CreateUser(user *models.User) error {
 	query := `
-		INSERT INTO users (first_name, last_name, email, password_hash, city, country, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (first_name, last_name, email, password_hash, city, country, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
+	if user.Role == "" {
+		user.Role = models.RolePlayer
+	}
+
 	err := s.db.QueryRow(
 		query,
 		user.FirstName,
@@ -48,6 +52,7 @@ func (s *AuthService) CreateUser(user *models.User) error {
 		user.PasswordHash,
 		user.City,
 		user.Country,
+		user.Role,
 		time.Now(),
 		time.Now(),
 	).Scan(&user.ID)
@@ -59,7 +64,7 @@ func (s *AuthService) CreateUser(user *models.User) error {
 func (s *AuthService) GetUserByEmail(email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, first_name, last_name, email, password_hash, city, country, created_at, updated_at
+		SELECT id, first_name, last_name, email, password_hash, city, country, role, created_at, updated_at, email_verified_at
 		FROM users
 		WHERE email = $1`
 
@@ -71,13 +76,16 @@ func (s *AuthService) GetUserByEmail(email string) (*models.User, error) {
 		&user.PasswordHash,
 		&user.City,
 		&user.Country,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	user.EmailVerified = user.EmailVerifiedAt != nil
 
 	return user, nil
 }
@@ -86,7 +94,7 @@ func (s *AuthService) GetUserByEmail(email string) (*models.User, error) {
 func (s *AuthService) GetUserByID(id int) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, first_name, last_name, email, password_hash, city, country, created_at, updated_at
+		SELECT id, first_name, last_name, email, password_hash, city, country, role, created_at, updated_at, email_verified_at
 		FROM users
 		WHERE id = $1`
 
@@ -98,13 +106,16 @@ func (s *AuthService) GetUserByID(id int) (*models.User, error) {
 		&user.PasswordHash,
 		&user.City,
 		&user.Country,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	user.EmailVerified = user.EmailVerifiedAt != nil
 
 	return user, nil
 }
@@ -137,6 +148,45 @@ func (s *AuthService) UpdateUserPassword(userID int, newPasswordHash string) err
 	return err
 }
 
+// ListUsers lista los usuarios, los más recientes primero. role y email (parcial) son filtros opcionales.
+func (s *AuthService) ListUsers(role, email string, limit, offset int) ([]models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT id, first_name, last_name, email, city, country, role, created_at, updated_at, email_verified_at
+		FROM users
+		WHERE ($1 = '' OR role = $1) AND ($2 = '' OR email ILIKE '%' || $2 || '%')
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4`, role, email, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.City, &user.Country,
+			&user.Role, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = user.EmailVerifiedAt != nil
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateUserRole cambia el rol de un usuario. Retorna sql.ErrNoRows si el usuario no existe.
+func (s *AuthService) UpdateUserRole(userID int, role string) error {
+	res, err := s.db.Exec(`UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`, role, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // DeleteUser elimina un usuario (soft delete o hard delete según configuración)
 func (s *AuthService) DeleteUser(userID int) error {
 	// Hard delete - en un entorno real podrías querer hacer soft delete
@@ -0,0 +1,25 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+
+	"back/internal/config"
+)
+
+// NewMailer crea una instancia de Mailer basada en la configuración
+// Si MAILER_TYPE=smtp, usa SMTPMailer, de lo contrario usa FileMailer
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	if cfg.MailerType == "smtp" {
+		log.Printf("Initializing SMTP mailer: host=%s, port=%s", cfg.SMTPHost, cfg.SMTPPort)
+
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAILER_TYPE=smtp")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	}
+
+	// Default: los correos se escriben en disco para pruebas locales
+	log.Printf("Initializing file mailer: outputDir=%s", cfg.MailOutputDir)
+	return NewFileMailer(cfg.MailOutputDir, cfg.MailFrom), nil
+}
@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"time"
+)
+
+// Message es un correo de texto plano
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envía correos
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// formatMessage arma el correo con sus headers en formato RFC 5322
+func formatMessage(from string, msg Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, msg.To, mime.QEncoding.Encode("UTF-8", msg.Subject), time.Now().Format(time.RFC1123Z), msg.Body))
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+)
+
+// Errores de la moderación de videos
+var (
+	ErrVideoNotFound      = errors.New("video not found")
+	ErrVideoNotReviewable = errors.New("video cannot be reviewed in its current status")
+	ErrVideoUnderReview   = errors.New("video hidden by reports or rejected; cannot delete")
+)
+
+// ModerationService gestiona la cola de videos que esperan la aprobación de un jurado o admin
+// antes de publicarse (VIDEO_MODERATION_ENABLED). Aprobar un video lo deja en 'processed', el
+// único estado que ven el listado público, los votos y los rankings.
+type ModerationService struct {
+	db             *sql.DB
+	videoService   *VideoService
+	rankingService *RankingService
+}
+
+func NewModerationService(db *sql.DB, videoService *VideoService, rankingService *RankingService) *ModerationService {
+	return &ModerationService{
+		db:             db,
+		videoService:   videoService,
+		rankingService: rankingService,
+	}
+}
+
+// ListPending retorna los videos pendientes de revisión, primero los que llevan más tiempo
+// esperando, con URLs firmadas para previsualizarlos
+func (s *ModerationService) ListPending(limit, offset int) ([]models.PendingVideo, error) {
+	rows, err := s.db.Query(`
+		SELECT v.id, v.title, u.id, u.first_name || ' ' || u.last_name, u.email, u.city,
+			COALESCE(v.processed_url, ''), v.thumbnail_url, v.preview_url, v.uploaded_at, v.processed_at
+		FROM videos v
+		JOIN users u ON v.user_id = u.id
+		WHERE v.status = $1
+		ORDER BY v.processed_at, v.uploaded_at
+		LIMIT $2 OFFSET $3`, models.VideoStatusPending, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	videos := []models.PendingVideo{}
+	for rows.Next() {
+		var video models.PendingVideo
+		var processedURL string
+		if err := rows.Scan(&video.VideoID, &video.Title, &video.UserID, &video.Username, &video.UserEmail, &video.City,
+			&processedURL, &video.ThumbnailURL, &video.PreviewURL, &video.UploadedAt, &video.ProcessedAt); err != nil {
+			return nil, err
+		}
+
+		video.VideoURL = s.signedVideoURL(video.VideoID, processedURL)
+		video.ThumbnailURL = s.videoService.GenerateMediaURL(video.ThumbnailURL)
+		video.PreviewURL = s.videoService.GenerateMediaURL(video.PreviewURL)
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
+// Approve publica un video pendiente, rechazado u ocultado por denuncias, y descarta sus denuncias
+// sin resolver. reviewerID es nil con el header X-Admin-Key.
+func (s *ModerationService) Approve(videoID uuid.UUID, reviewerID *int) error {
+	if err := s.review(videoID, reviewerID, models.ReportResolutionDismissed, withVideoEvent(`
+		UPDATE videos SET status = $1, reviewed_at = NOW(), reviewed_by = $2, rejection_reason = NULL, hidden_at = NULL
+		WHERE id = $3 AND status IN ($4, $5)`),
+		models.VideoStatusProcessed, reviewerID, videoID, models.VideoStatusPending, models.VideoStatusRejected); err != nil {
+		return err
+	}
+
+	// Un video rechazado después de publicarse puede conservar votos. Se fija el total en lugar de
+	// sumarlo: si el video siguió en el caché (RemoveVideo falló o hubo una reconciliación), sumar
+	// contaría sus votos dos veces
+	var votes int
+	if err := s.db.QueryRow(`SELECT COALESCE(votes_count, 0) FROM videos WHERE id = $1`, videoID).Scan(&votes); err != nil {
+		log.Printf("Warning: failed to read votes of approved video %s: %v", videoID, err)
+	} else if err := s.rankingService.SetVotes(context.Background(), videoID, votes); err != nil {
+		log.Printf("Warning: failed to add approved video %s to ranking cache: %v", videoID, err)
+	}
+	return nil
+}
+
+// Reject rechaza un video pendiente o ya publicado, que deja de verse y de recibir votos, y da
+// por resueltas sus denuncias. El motivo queda visible para quien lo subió.
+func (s *ModerationService) Reject(videoID uuid.UUID, reviewerID *int, reason string) error {
+	if err := s.review(videoID, reviewerID, models.ReportResolutionRemoved, withVideoEvent(`
+		UPDATE videos SET status = $1, reviewed_at = NOW(), reviewed_by = $2, rejection_reason = $3, hidden_at = NULL
+		WHERE id = $4 AND status IN ($5, $6)`),
+		models.VideoStatusRejected, reviewerID, reason, videoID, models.VideoStatusPending, models.VideoStatusProcessed); err != nil {
+		return err
+	}
+
+	if err := s.rankingService.RemoveVideo(context.Background(), videoID); err != nil {
+		log.Printf("Warning: failed to remove rejected video %s from ranking cache: %v", videoID, err)
+	}
+	return nil
+}
+
+// review aplica el cambio de estado, resuelve las denuncias sin resolver del video y avisa a los
+// rankings en vivo en la misma transacción
+func (s *ModerationService) review(videoID uuid.UUID, reviewerID *int, resolution, update string, args ...interface{}) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(update, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return s.notReviewable(videoID)
+	}
+	if _, err := tx.Exec(`
+		UPDATE video_reports SET resolved_at = NOW(), resolved_by = $1, resolution = $2
+		WHERE video_id = $3 AND resolved_at IS NULL`, reviewerID, resolution, videoID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`SELECT pg_notify('` + rankingEventsChannel + `', 'moderation')`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// notReviewable distingue un video inexistente de uno en un estado que no admite la revisión
+func (s *ModerationService) notReviewable(videoID uuid.UUID) error {
+	var exists bool
+	if err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM videos WHERE id = $1)`, videoID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrVideoNotFound
+	}
+	return ErrVideoNotReviewable
+}
+
+// signedVideoURL firma la URL del MP4 procesado para revisarlo. El playlist HLS solo se sirve
+// para videos publicados, así que con HLS se usa el MP4 de respaldo.
+func (s *ModerationService) signedVideoURL(videoID uuid.UUID, processedURL string) *string {
+	if isHLSPath(processedURL) {
+		processedURL = fmt.Sprintf("/videos/%s_processed.mp4", videoID)
+	}
+	return s.videoService.GenerateMediaURL(&processedURL)
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Claves de Redis del caché de rankings
+const (
+	rankingCacheGlobalKey  = "rankings:global"         // sorted set global: video_id -> puntaje
+	rankingCacheCityPrefix = "rankings:city:"          // sorted set por ciudad (+ nombre exacto de la ciudad)
+	rankingCacheCitiesKey  = "rankings:cities"         // ciudades que tienen sorted set
+	rankingCacheVideosKey  = "rankings:videos"         // hash video_id -> datos del video en JSON
+	rankingCacheReadyKey   = "rankings:ready"          // existe desde la primera reconciliación (unix de la última)
+	rankingCacheUpdatedKey = "rankings:updated"        // unix del último cambio, incremental o por reconciliación
+	rankingCacheLockKey    = "rankings:reconcile:lock" // una sola réplica reconcilia a la vez
+)
+
+// rankingCacheTimeout acota cada operación contra Redis: si tarda más, se usa Postgres
+const rankingCacheTimeout = 500 * time.Millisecond
+
+var (
+	// ErrRankingCacheNotReady indica que los sorted sets aún no se construyeron
+	ErrRankingCacheNotReady = errors.New("ranking cache not ready")
+	// ErrRankingCacheReconciling indica que otra réplica está reconstruyendo el caché
+	ErrRankingCacheReconciling = errors.New("ranking cache reconcile already in progress")
+)
+
+// rankingCacheVideo son los datos del video que se guardan junto al sorted set
+type rankingCacheVideo struct {
+	Title        string    `json:"title"`
+	Username     string    `json:"username"`
+	City         string    `json:"city"`
+	VideoURL     string    `json:"video_url,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	PreviewURL   string    `json:"preview_url,omitempty"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// RankingCache mantiene en Redis un sorted set global y uno por ciudad con los votos de cada
+// video. Los votos se suman al registrarse y Reconcile reconstruye todo desde la tabla votes.
+type RankingCache struct {
+	redis    *redis.Client
+	db       *sql.DB
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewRankingCache(db *sql.DB, cfg *config.Config) *RankingCache {
+	return &RankingCache{
+		redis: redis.NewClient(&redis.Options{
+			Addr:         cfg.RedisURL,
+			DialTimeout:  rankingCacheTimeout,
+			ReadTimeout:  rankingCacheTimeout,
+			WriteTimeout: rankingCacheTimeout,
+		}),
+		db:       db,
+		interval: cfg.RankingCacheReconcileInterval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// rankingScore ordena por votos y, a igual cantidad, primero el video más antiguo (igual que
+// video_rankings). La parte entera son los votos; la fracción, en (0, 1), decrece con uploaded_at.
+func rankingScore(votes int, uploadedAt time.Time) float64 {
+	return float64(votes) + 1 - float64(uploadedAt.Unix())/1e10
+}
+
+func rankingCacheCityKey(city string) string {
+	return rankingCacheCityPrefix + city
+}
+
+// touchRankingCache agrega al pipeline el registro de la hora del cambio
+func touchRankingCache(ctx context.Context, pipe redis.Pipeliner) {
+	pipe.Set(ctx, rankingCacheUpdatedKey, strconv.FormatInt(time.Now().Unix(), 10), 0)
+}
+
+// RecordVote suma delta votos al video en el ranking global y en el de su ciudad. Antes de la
+// primera reconciliación no hace nada: Reconcile ya incluirá el voto.
+func (c *RankingCache) RecordVote(ctx context.Context, videoID uuid.UUID, delta int) error {
+	ctx, cancel := context.WithTimeout(ctx, rankingCacheTimeout)
+	defer cancel()
+
+	ready, err := c.redis.Exists(ctx, rankingCacheReadyKey).Result()
+	if err != nil {
+		return err
+	}
+	if ready == 0 {
+		return nil
+	}
+
+	member := videoID.String()
+	video, err := c.loadVideo(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(video)
+	if err != nil {
+		return err
+	}
+
+	// El ZADD NX fija la fracción de desempate si el video aún no estaba en el ranking
+	base := redis.Z{Score: rankingScore(0, video.UploadedAt), Member: member}
+	cityKey := rankingCacheCityKey(video.City)
+
+	pipe := c.redis.TxPipeline()
+	pipe.HSetNX(ctx, rankingCacheVideosKey, member, data)
+	pipe.SAdd(ctx, rankingCacheCitiesKey, video.City)
+	for _, key := range []string{rankingCacheGlobalKey, cityKey} {
+		pipe.ZAddNX(ctx, key, base)
+		pipe.ZIncrBy(ctx, key, float64(delta), member)
+		if delta < 0 {
+			// Los videos sin votos no aparecen en el ranking
+			pipe.ZRemRangeByScore(ctx, key, "-inf", "(1")
+		}
+	}
+	touchRankingCache(ctx, pipe)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SetVotes fija los votos del video en el caché de rankings con ZADD, sin sumar a lo que ya
+// tuviera: repetirlo no cuenta dos veces los votos. Con menos de un voto el video sale del ranking.
+func (c *RankingCache) SetVotes(ctx context.Context, videoID uuid.UUID, votes int) error {
+	ctx, cancel := context.WithTimeout(ctx, rankingCacheTimeout)
+	defer cancel()
+
+	ready, err := c.redis.Exists(ctx, rankingCacheReadyKey).Result()
+	if err != nil {
+		return err
+	}
+	if ready == 0 {
+		return nil
+	}
+
+	member := videoID.String()
+	video, err := c.loadVideo(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(video)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.redis.TxPipeline()
+	for _, key := range []string{rankingCacheGlobalKey, rankingCacheCityKey(video.City)} {
+		if votes < 1 {
+			// Los videos sin votos no aparecen en el ranking
+			pipe.ZRem(ctx, key, member)
+			continue
+		}
+		pipe.ZAdd(ctx, key, redis.Z{Score: rankingScore(votes, video.UploadedAt), Member: member})
+	}
+	if votes > 0 {
+		pipe.HSet(ctx, rankingCacheVideosKey, member, data)
+		pipe.SAdd(ctx, rankingCacheCitiesKey, video.City)
+	}
+	touchRankingCache(ctx, pipe)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RemoveVideo saca el video del ranking global y del de su ciudad (deja de ser visible)
+func (c *RankingCache) RemoveVideo(ctx context.Context, videoID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, rankingCacheTimeout)
+	defer cancel()
+
+	member := videoID.String()
+	data, err := c.redis.HGet(ctx, rankingCacheVideosKey, member).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var video rankingCacheVideo
+	if err := json.Unmarshal(data, &video); err != nil {
+		return err
+	}
+
+	pipe := c.redis.TxPipeline()
+	pipe.ZRem(ctx, rankingCacheGlobalKey, member)
+	pipe.ZRem(ctx, rankingCacheCityKey(video.City), member)
+	pipe.HDel(ctx, rankingCacheVideosKey, member)
+	touchRankingCache(ctx, pipe)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RefreshVideo vuelve a leer de Postgres los datos del video que está en el caché (por ejemplo,
+// cuando su autor cambia de nombre o de ciudad) y, si cambió la ciudad, lo mueve al ranking de
+// la nueva conservando su puntaje
+func (c *RankingCache) RefreshVideo(ctx context.Context, videoID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, rankingCacheTimeout)
+	defer cancel()
+
+	member := videoID.String()
+	data, err := c.redis.HGet(ctx, rankingCacheVideosKey, member).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var cached rankingCacheVideo
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return err
+	}
+
+	video, err := c.queryVideo(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	data, err = json.Marshal(video)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.redis.TxPipeline()
+	pipe.HSet(ctx, rankingCacheVideosKey, member, data)
+	if video.City != cached.City {
+		score, err := c.redis.ZScore(ctx, rankingCacheGlobalKey, member).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		pipe.ZRem(ctx, rankingCacheCityKey(cached.City), member)
+		if err == nil {
+			pipe.ZAdd(ctx, rankingCacheCityKey(video.City), redis.Z{Score: score, Member: member})
+			pipe.SAdd(ctx, rankingCacheCitiesKey, video.City)
+		}
+	}
+	touchRankingCache(ctx, pipe)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// loadVideo lee los datos del video del hash del caché o, si no están, de Postgres
+func (c *RankingCache) loadVideo(ctx context.Context, videoID uuid.UUID) (*rankingCacheVideo, error) {
+	var video rankingCacheVideo
+
+	data, err := c.redis.HGet(ctx, rankingCacheVideosKey, videoID.String()).Bytes()
+	if err == nil {
+		return &video, json.Unmarshal(data, &video)
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return c.queryVideo(ctx, videoID)
+}
+
+// queryVideo lee de Postgres los datos del video que se guardan en el hash del caché
+func (c *RankingCache) queryVideo(ctx context.Context, videoID uuid.UUID) (*rankingCacheVideo, error) {
+	var video rankingCacheVideo
+	var videoURL, thumbnailURL, previewURL sql.NullString
+	err := c.db.QueryRowContext(ctx, `
+		SELECT v.title, u.first_name || ' ' || u.last_name, u.city, v.processed_url, v.thumbnail_url, v.preview_url, v.uploaded_at
+		FROM videos v
+		JOIN users u ON v.user_id = u.id
+		WHERE v.id = $1`, videoID).Scan(
+		&video.Title, &video.Username, &video.City, &videoURL, &thumbnailURL, &previewURL, &video.UploadedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	video.VideoURL = videoURL.String
+	video.ThumbnailURL = thumbnailURL.String
+	video.PreviewURL = previewURL.String
+	return &video, nil
+}
+
+// GetRankings retorna limit entradas desde offset del ranking de city (vacío = global) y la hora
+// del último cambio del caché (o de la última reconciliación, si no hubo cambios desde entonces)
+func (c *RankingCache) GetRankings(ctx context.Context, city string, limit, offset int) ([]models.RankingEntry, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, rankingCacheTimeout)
+	defer cancel()
+
+	key := rankingCacheGlobalKey
+	if city != "" {
+		key = rankingCacheCityKey(city)
+	}
+
+	pipe := c.redis.Pipeline()
+	readyCmd := pipe.Get(ctx, rankingCacheReadyKey)
+	updatedCmd := pipe.Get(ctx, rankingCacheUpdatedKey)
+	rangeCmd := pipe.ZRevRangeWithScores(ctx, key, int64(offset), int64(offset+limit-1))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, time.Time{}, err
+	}
+	if errors.Is(readyCmd.Err(), redis.Nil) {
+		return nil, time.Time{}, ErrRankingCacheNotReady
+	}
+
+	updated, err := updatedCmd.Int64()
+	if err != nil {
+		if updated, err = readyCmd.Int64(); err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid %s value: %w", rankingCacheReadyKey, err)
+		}
+	}
+	updatedAt := time.Unix(updated, 0)
+
+	scores := rangeCmd.Val()
+	if len(scores) == 0 {
+		return nil, updatedAt, nil
+	}
+
+	members := make([]string, len(scores))
+	for i, z := range scores {
+		members[i] = z.Member.(string)
+	}
+	videos, err := c.redis.HMGet(ctx, rankingCacheVideosKey, members...).Result()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	rankings := make([]models.RankingEntry, 0, len(scores))
+	for i, z := range scores {
+		data, ok := videos[i].(string)
+		if !ok {
+			return nil, time.Time{}, fmt.Errorf("ranking cache has no data for video %s", members[i])
+		}
+		var video rankingCacheVideo
+		if err := json.Unmarshal([]byte(data), &video); err != nil {
+			return nil, time.Time{}, err
+		}
+		videoID, err := uuid.Parse(members[i])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		rankings = append(rankings, models.RankingEntry{
+			Position:     offset + i + 1,
+			VideoID:      videoID,
+			Username:     video.Username,
+			Title:        video.Title,
+			City:         video.City,
+			Votes:        int(math.Floor(z.Score)),
+			VideoURL:     video.VideoURL,
+			ThumbnailURL: video.ThumbnailURL,
+			PreviewURL:   video.PreviewURL,
+		})
+	}
+	return rankings, updatedAt, nil
+}
+
+// Reconcile reconstruye los sorted sets desde la tabla votes, corrigiendo votos perdidos (Redis
+// caído o reiniciado) o de videos que dejaron de ser públicos. El reemplazo es atómico (MULTI).
+// Si otra réplica está reconciliando, retorna ErrRankingCacheReconciling.
+func (c *RankingCache) Reconcile(ctx context.Context) error {
+	locked, err := c.redis.SetNX(ctx, rankingCacheLockKey, 1, 5*time.Minute).Result()
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return ErrRankingCacheReconciling
+	}
+	defer c.redis.Del(context.Background(), rankingCacheLockKey)
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT v.id, v.title, u.first_name || ' ' || u.last_name, u.city, v.processed_url, v.thumbnail_url, v.preview_url, v.uploaded_at, COUNT(*)
+		FROM votes vo
+		JOIN videos v ON vo.video_id = v.id
+		JOIN users u ON v.user_id = u.id
+		WHERE v.is_public = true AND v.status = 'processed' AND vo.voided_at IS NULL
+		GROUP BY v.id, u.id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	global := []redis.Z{}
+	cities := map[string][]redis.Z{}
+	videos := map[string]interface{}{}
+	for rows.Next() {
+		var videoID uuid.UUID
+		var video rankingCacheVideo
+		var videoURL, thumbnailURL, previewURL sql.NullString
+		var votes int
+		if err := rows.Scan(&videoID, &video.Title, &video.Username, &video.City, &videoURL, &thumbnailURL, &previewURL, &video.UploadedAt, &votes); err != nil {
+			return err
+		}
+		video.VideoURL = videoURL.String
+		video.ThumbnailURL = thumbnailURL.String
+		video.PreviewURL = previewURL.String
+
+		data, err := json.Marshal(video)
+		if err != nil {
+			return err
+		}
+		member := videoID.String()
+		z := redis.Z{Score: rankingScore(votes, video.UploadedAt), Member: member}
+		global = append(global, z)
+		cities[video.City] = append(cities[video.City], z)
+		videos[member] = data
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	oldCities, err := c.redis.SMembers(ctx, rankingCacheCitiesKey).Result()
+	if err != nil {
+		return err
+	}
+
+	// Los votos que se registren durante la consulta pueden perderse; la siguiente reconciliación los recupera
+	pipe := c.redis.TxPipeline()
+	stale := []string{rankingCacheGlobalKey, rankingCacheCitiesKey, rankingCacheVideosKey}
+	for _, city := range oldCities {
+		stale = append(stale, rankingCacheCityKey(city))
+	}
+	pipe.Del(ctx, stale...)
+	if len(global) > 0 {
+		pipe.ZAdd(ctx, rankingCacheGlobalKey, global...)
+		pipe.HSet(ctx, rankingCacheVideosKey, videos)
+	}
+	for city, scores := range cities {
+		pipe.ZAdd(ctx, rankingCacheCityKey(city), scores...)
+		pipe.SAdd(ctx, rankingCacheCitiesKey, city)
+	}
+	pipe.Set(ctx, rankingCacheReadyKey, strconv.FormatInt(time.Now().Unix(), 10), 0)
+	touchRankingCache(ctx, pipe)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Start reconcilia al arrancar y luego cada RankingCacheReconcileInterval
+func (c *RankingCache) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.Reconcile(context.Background()); err != nil && !errors.Is(err, ErrRankingCacheReconciling) {
+				log.Printf("Warning: failed to reconcile ranking cache: %v", err)
+			}
+
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop detiene la reconciliación periódica
+func (c *RankingCache) Stop() {
+	close(c.stop)
+}
+
+// Close cierra la conexión con Redis
+func (c *RankingCache) Close() error {
+	return c.redis.Close()
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// rankingEventsChannel es el canal de LISTEN/NOTIFY que avisa los cambios en votes y las
+// actualizaciones de la vista video_rankings
+const rankingEventsChannel = "ranking_events"
+
+// RankingBoardSize es cuántas posiciones tiene cada ranking en vivo
+const RankingBoardSize = 50
+
+// rankingUpdateBuffer es cuántas actualizaciones se encolan por suscriptor; si se llena, el
+// suscriptor se desconecta y al reconectar recibe el ranking completo
+const rankingUpdateBuffer = 8
+
+// RankingEventBroker mantiene los rankings en vivo (global y por ciudad) que tienen suscriptores.
+// Los votos solo marcan los rankings como desactualizados: se recalculan una vez por intervalo,
+// así una ráfaga de votos produce como mucho una actualización por intervalo.
+type RankingEventBroker struct {
+	rankingService *RankingService
+	listener       *pq.Listener
+	interval       time.Duration
+
+	mu          sync.Mutex
+	boards      map[string][]models.RankingEntry
+	subscribers map[string]map[chan models.RankingUpdate]struct{}
+}
+
+// NewRankingEventBroker escucha los avisos de nuevos votos; si la conexión se cae, pq reconecta sola
+func NewRankingEventBroker(cfg *config.Config, rankingService *RankingService) (*RankingEventBroker, error) {
+	listener := pq.NewListener(cfg.GetDatabaseDSN(), 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Warning: ranking events listener: %v", err)
+		}
+	})
+	if err := listener.Listen(rankingEventsChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", rankingEventsChannel, err)
+	}
+
+	b := &RankingEventBroker{
+		rankingService: rankingService,
+		listener:       listener,
+		interval:       cfg.RankingUpdateInterval,
+		boards:         make(map[string][]models.RankingEntry),
+		subscribers:    make(map[string]map[chan models.RankingUpdate]struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+// Subscribe retorna el ranking actual de city (vacío = global) y las actualizaciones siguientes.
+// La función retornada cancela la suscripción.
+func (b *RankingEventBroker) Subscribe(city string) ([]models.RankingEntry, <-chan models.RankingUpdate, func(), error) {
+	key := strings.TrimSpace(city)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	board, ok := b.boards[key]
+	if !ok {
+		var err error
+		board, _, err = b.rankingService.GetTopRankings(RankingBoardSize, key)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		b.boards[key] = board
+	}
+
+	ch := make(chan models.RankingUpdate, rankingUpdateBuffer)
+	if b.subscribers[key] == nil {
+		b.subscribers[key] = make(map[chan models.RankingUpdate]struct{})
+	}
+	b.subscribers[key][ch] = struct{}{}
+
+	return board, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.unsubscribe(key, ch)
+	}, nil
+}
+
+// Close deja de escuchar votos
+func (b *RankingEventBroker) Close() error {
+	return b.listener.Close()
+}
+
+func (b *RankingEventBroker) unsubscribe(key string, ch chan models.RankingUpdate) {
+	if _, ok := b.subscribers[key][ch]; !ok {
+		return
+	}
+	delete(b.subscribers[key], ch)
+	close(ch)
+	if len(b.subscribers[key]) == 0 {
+		delete(b.subscribers, key)
+		delete(b.boards, key)
+	}
+}
+
+func (b *RankingEventBroker) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	stale := false
+	for {
+		select {
+		case _, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			// Una notificación nil indica reconexión: pudieron perderse votos, también se recalcula
+			stale = true
+		case <-ticker.C:
+			if stale {
+				stale = false
+				b.refresh()
+			}
+		}
+	}
+}
+
+// refresh recalcula los rankings con suscriptores y les envía lo que cambió. Las consultas se
+// hacen sin el lock para no bloquear Subscribe ni las desuscripciones mientras tanto.
+func (b *RankingEventBroker) refresh() {
+	b.mu.Lock()
+	keys := make([]string, 0, len(b.boards))
+	for key := range b.boards {
+		keys = append(keys, key)
+	}
+	b.mu.Unlock()
+
+	boards := make(map[string][]models.RankingEntry, len(keys))
+	for _, key := range keys {
+		board, _, err := b.rankingService.GetTopRankings(RankingBoardSize, key)
+		if err != nil {
+			log.Printf("Warning: failed to refresh ranking %q: %v", key, err)
+			continue
+		}
+		boards[key] = board
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, board := range boards {
+		// El ranking pudo quedarse sin suscriptores mientras se consultaba
+		previous, ok := b.boards[key]
+		if !ok {
+			continue
+		}
+		b.boards[key] = board
+
+		update := diffRankings(previous, board)
+		if len(update.Changes) == 0 && len(update.Removed) == 0 {
+			continue
+		}
+		update.City = key
+
+		for ch := range b.subscribers[key] {
+			select {
+			case ch <- update:
+			default:
+				b.unsubscribe(key, ch)
+			}
+		}
+	}
+}
+
+// diffRankings retorna las entradas de next que son nuevas o cambiaron de posición o de votos,
+// y los videos de previous que ya no están en next
+func diffRankings(previous, next []models.RankingEntry) models.RankingUpdate {
+	before := make(map[uuid.UUID]models.RankingEntry, len(previous))
+	for _, entry := range previous {
+		before[entry.VideoID] = entry
+	}
+
+	update := models.RankingUpdate{Changes: []models.RankingChange{}}
+	for _, entry := range next {
+		old, ok := before[entry.VideoID]
+		delete(before, entry.VideoID)
+		if ok && old.Position == entry.Position && old.Votes == entry.Votes {
+			continue
+		}
+		update.Changes = append(update.Changes, models.RankingChange{RankingEntry: entry, PreviousPosition: old.Position})
+	}
+	for _, entry := range previous {
+		if _, removed := before[entry.VideoID]; removed {
+			update.Removed = append(update.Removed, entry.VideoID)
+		}
+	}
+	return update
+}
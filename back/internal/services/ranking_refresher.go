@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"back/internal/config"
+)
+
+// rankingsView es la vista materializada de la que se leen los rankings
+const rankingsView = "video_rankings"
+
+// rankingRefreshCheckInterval es cada cuánto se revisa si la vista de rankings debe actualizarse
+const rankingRefreshCheckInterval = 5 * time.Second
+
+// RankingRefresher actualiza la vista video_rankings en segundo plano cuando pasó
+// RankingRefreshInterval desde la última actualización o llegaron RankingRefreshVotes votos
+type RankingRefresher struct {
+	db        *sql.DB
+	interval  time.Duration
+	threshold int
+	stop      chan struct{}
+}
+
+func NewRankingRefresher(db *sql.DB, cfg *config.Config) *RankingRefresher {
+	return &RankingRefresher{
+		db:        db,
+		interval:  cfg.RankingRefreshInterval,
+		threshold: cfg.RankingRefreshVotes,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start lanza la revisión periódica de la vista
+func (r *RankingRefresher) Start() {
+	go func() {
+		ticker := time.NewTicker(rankingRefreshCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.refreshIfStale(); err != nil {
+					log.Printf("Warning: failed to refresh %s: %v", rankingsView, err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop detiene la revisión periódica
+func (r *RankingRefresher) Stop() {
+	close(r.stop)
+}
+
+func (r *RankingRefresher) refreshIfStale() error {
+	var due bool
+	var votes int
+	err := r.db.QueryRow(`
+		SELECT refreshed_at <= LOCALTIMESTAMP - make_interval(secs => $2),
+		       (SELECT COUNT(*) FROM votes WHERE created_at > refreshed_at)
+		FROM materialized_view_refreshes
+		WHERE view_name = $1`, rankingsView, r.interval.Seconds()).Scan(&due, &votes)
+	if err != nil {
+		return err
+	}
+
+	if !due && votes < r.threshold {
+		return nil
+	}
+	return r.Refresh(context.Background())
+}
+
+// Refresh actualiza la vista sin bloquear las lecturas (CONCURRENTLY) y avisa a los rankings en
+// vivo. Si otra réplica la está actualizando, no hace nada.
+func (r *RankingRefresher) Refresh(ctx context.Context) error {
+	// El advisory lock asegura que una sola réplica actualice la vista a la vez. Pertenece a la
+	// sesión, así que todo se ejecuta en la misma conexión
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, rankingsView).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, rankingsView)
+
+	// Los votos que lleguen durante la actualización cuentan para la siguiente
+	var startedAt time.Time
+	if err := conn.QueryRowContext(ctx, `SELECT LOCALTIMESTAMP`).Scan(&startedAt); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+rankingsView); err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		WITH refreshed AS (
+			UPDATE materialized_view_refreshes SET refreshed_at = $1 WHERE view_name = $2 RETURNING view_name
+		)
+		SELECT pg_notify('`+rankingEventsChannel+`', 'refresh') FROM refreshed`, startedAt, rankingsView)
+	return err
+}
@@ -1,121 +1,149 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"time"
 
 	"back/internal/config"
 	"back/internal/database/models"
+
+	"github.com/google/uuid"
 )
 
 type RankingService struct {
 	db     *sql.DB
 	config *config.Config
+	cache  *RankingCache
 }
 
-func NewRankingService(db *sql.DB, cfg *config.Config) *RankingService {
+// NewRankingService crea el servicio de rankings; cache puede ser nil (se lee solo de Postgres)
+func NewRankingService(db *sql.DB, cfg *config.Config, cache *RankingCache) *RankingService {
 	return &RankingService{
 		db:     db,
 		config: cfg,
+		cache:  cache,
 	}
 }
 
-// GetRankings obtiene el ranking de jugadores con paginación
-func (s *RankingService) GetRankings(page, limit int, city string) ([]models.RankingEntry, error) {
-	offset := (page - 1) * limit
+// GetRankings obtiene el ranking de jugadores con paginación desde el caché de Redis o, si no
+// está disponible, desde la vista video_rankings. Con city, filtra por la ciudad exacta y usa la
+// posición dentro de la ciudad. También retorna a qué momento corresponde el ranking.
+func (s *RankingService) GetRankings(page, limit int, city string) ([]models.RankingEntry, time.Time, error) {
+	return s.getRankings(city, limit, (page-1)*limit)
+}
 
-	var query string
-	var args []interface{}
+// GetTopRankings obtiene el top de rankings (desde el caché de Redis si está disponible)
+func (s *RankingService) GetTopRankings(limit int, city string) ([]models.RankingEntry, time.Time, error) {
+	return s.getRankings(city, limit, 0)
+}
 
-	baseQuery := `
-		WITH ranked_videos AS (
-			SELECT 
-				v.id as video_id,
-				v.title,
-				v.processed_url,
-				v.votes_count,
-				u.first_name || ' ' || u.last_name as username,
-				u.city,
-				ROW_NUMBER() OVER (ORDER BY v.votes_count DESC, v.uploaded_at ASC) as position
-			FROM videos v
-			JOIN users u ON v.user_id = u.id
-			WHERE v.is_public = true AND v.status = 'processed' AND v.votes_count > 0`
+// RecordVote suma delta votos (negativo al anularlos) al video en el caché de rankings
+func (s *RankingService) RecordVote(ctx context.Context, videoID uuid.UUID, delta int) error {
+	if s.cache == nil {
+		return nil
+	}
+	return s.cache.RecordVote(ctx, videoID, delta)
+}
 
-	if city != "" {
-		query = baseQuery + ` AND u.city ILIKE $1
-		)
-		SELECT video_id, title, processed_url, votes_count, username, city, position
-		FROM ranked_videos
-		ORDER BY position
-		LIMIT $2 OFFSET $3`
-		args = []interface{}{"%" + city + "%", limit, offset}
-	} else {
-		query = baseQuery + `
-		)
-		SELECT video_id, title, processed_url, votes_count, username, city, position
-		FROM ranked_videos
-		ORDER BY position
-		LIMIT $1 OFFSET $2`
-		args = []interface{}{limit, offset}
+// SetVotes fija los votos del video en el caché de rankings (por ejemplo, al volver a publicarlo)
+func (s *RankingService) SetVotes(ctx context.Context, videoID uuid.UUID, votes int) error {
+	if s.cache == nil {
+		return nil
 	}
+	return s.cache.SetVotes(ctx, videoID, votes)
+}
 
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, err
+// RemoveVideo saca del caché de rankings un video que dejó de ser visible
+func (s *RankingService) RemoveVideo(ctx context.Context, videoID uuid.UUID) error {
+	if s.cache == nil {
+		return nil
 	}
-	defer rows.Close()
+	return s.cache.RemoveVideo(ctx, videoID)
+}
 
-	var rankings []models.RankingEntry
+// RefreshUserVideos actualiza el nombre y la ciudad del autor en los videos publicados del
+// usuario (tras editar su perfil) y avisa a los rankings en vivo
+func (s *RankingService) RefreshUserVideos(ctx context.Context, userID int) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM videos WHERE user_id = $1 AND status = 'processed' AND is_public = true`, userID)
+	if err != nil {
+		return err
+	}
+	var videoIDs []uuid.UUID
 	for rows.Next() {
-		var entry models.RankingEntry
-		var videoURL sql.NullString
-
-		err := rows.Scan(
-			&entry.VideoID,
-			&entry.Title,
-			&videoURL,
-			&entry.Votes,
-			&entry.Username,
-			&entry.City,
-			&entry.Position,
-		)
-		if err != nil {
-			return nil, err
+		var videoID uuid.UUID
+		if err := rows.Scan(&videoID); err != nil {
+			rows.Close()
+			return err
 		}
+		videoIDs = append(videoIDs, videoID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(videoIDs) == 0 {
+		return nil
+	}
 
-		if videoURL.Valid {
-			entry.VideoURL = videoURL.String
+	if s.cache != nil {
+		for _, videoID := range videoIDs {
+			// Si falla, la próxima reconciliación lo corrige
+			if err := s.cache.RefreshVideo(ctx, videoID); err != nil {
+				log.Printf("Warning: failed to refresh video %s in ranking cache: %v", videoID, err)
+			}
 		}
+	}
+	_, err = s.db.ExecContext(ctx, `SELECT pg_notify('`+rankingEventsChannel+`', 'profile')`)
+	return err
+}
 
-		rankings = append(rankings, entry)
+func (s *RankingService) getRankings(city string, limit, offset int) ([]models.RankingEntry, time.Time, error) {
+	if s.cache != nil {
+		rankings, updatedAt, err := s.cache.GetRankings(context.Background(), city, limit, offset)
+		if err == nil {
+			return rankings, updatedAt, nil
+		}
+		if !errors.Is(err, ErrRankingCacheNotReady) {
+			log.Printf("Warning: ranking cache unavailable, reading from %s: %v", rankingsView, err)
+		}
 	}
 
-	return rankings, nil
+	rankings, err := s.queryRankings(city, limit, offset)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	// Sin la fecha de actualización igual se responde el ranking (el handler omite el header)
+	refreshedAt, _ := s.refreshedAt()
+	return rankings, refreshedAt, nil
 }
 
-// GetTopRankings obtiene el top de rankings (más eficiente, con potencial caché)
-func (s *RankingService) GetTopRankings(limit int, city string) ([]models.RankingEntry, error) {
-	var query string
-	var args []interface{}
+// refreshedAt retorna cuándo se actualizó por última vez la vista video_rankings
+func (s *RankingService) refreshedAt() (time.Time, error) {
+	var refreshedAt time.Time
+	err := s.db.QueryRow(`SELECT refreshed_at AT TIME ZONE current_setting('TimeZone') FROM materialized_view_refreshes WHERE view_name = $1`, rankingsView).Scan(&refreshedAt)
+	return refreshedAt, err
+}
 
-	baseQuery := `
-		SELECT 
-			v.id as video_id,
-			v.title,
-			v.processed_url,
-			v.votes_count,
-			u.first_name || ' ' || u.last_name as username,
-			u.city,
-			ROW_NUMBER() OVER (ORDER BY v.votes_count DESC, v.uploaded_at ASC) as position
-		FROM videos v
-		JOIN users u ON v.user_id = u.id
-		WHERE v.is_public = true AND v.status = 'processed' AND v.votes_count > 0`
+func (s *RankingService) queryRankings(city string, limit, offset int) ([]models.RankingEntry, error) {
+	query := `
+		SELECT id, title, processed_url, thumbnail_url, preview_url, votes_count, username, city, global_position
+		FROM video_rankings
+		WHERE votes_count > 0
+		ORDER BY global_position
+		LIMIT $1 OFFSET $2`
+	args := []interface{}{limit, offset}
 
 	if city != "" {
-		query = baseQuery + ` AND u.city ILIKE $1 ORDER BY v.votes_count DESC, v.uploaded_at ASC LIMIT $2`
-		args = []interface{}{"%" + city + "%", limit}
-	} else {
-		query = baseQuery + ` ORDER BY v.votes_count DESC, v.uploaded_at ASC LIMIT $1`
-		args = []interface{}{limit}
+		query = `
+		SELECT id, title, processed_url, thumbnail_url, preview_url, votes_count, username, city, city_position
+		FROM video_rankings
+		WHERE city = $3 AND votes_count > 0
+		ORDER BY city_position
+		LIMIT $1 OFFSET $2`
+		args = append(args, city)
 	}
 
 	rows, err := s.db.Query(query, args...)
@@ -125,37 +153,35 @@ func (s *RankingService) GetTopRankings(limit int, city string) ([]models.Rankin
 	defer rows.Close()
 
 	var rankings []models.RankingEntry
-	position := 1
-
 	for rows.Next() {
 		var entry models.RankingEntry
-		var videoURL sql.NullString
-		var dbPosition int // Para cuando usamos ROW_NUMBER()
+		var videoURL, thumbnailURL, previewURL sql.NullString
 
 		err := rows.Scan(
 			&entry.VideoID,
 			&entry.Title,
 			&videoURL,
+			&thumbnailURL,
+			&previewURL,
 			&entry.Votes,
 			&entry.Username,
 			&entry.City,
-			&dbPosition,
+			&entry.Position,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		entry.Position = position
-		position++
-
 		if videoURL.Valid {
 			entry.VideoURL = videoURL.String
 		}
+		entry.ThumbnailURL = thumbnailURL.String
+		entry.PreviewURL = previewURL.String
 
 		rankings = append(rankings, entry)
 	}
 
-	return rankings, nil
+	return rankings, rows.Err()
 }
 
 // GetRankingByUser obtiene la posición de un usuario específico en el ranking
@@ -169,6 +195,8 @@ func (s *RankingService) GetRankingByUser(userID int, city string) (*models.Rank
 				v.id as video_id,
 				v.title,
 				v.processed_url,
+				v.thumbnail_url,
+				v.preview_url,
 				v.votes_count,
 				v.user_id,
 				u.first_name || ' ' || u.last_name as username,
@@ -181,7 +209,7 @@ func (s *RankingService) GetRankingByUser(userID int, city string) (*models.Rank
 	if city != "" {
 		query = baseQuery + ` AND u.city ILIKE $1
 		)
-		SELECT video_id, title, processed_url, votes_count, username, city, position
+		SELECT video_id, title, processed_url, thumbnail_url, preview_url, votes_count, username, city, position
 		FROM ranked_videos
 		WHERE user_id = $2
 		ORDER BY votes_count DESC
@@ -190,7 +218,7 @@ func (s *RankingService) GetRankingByUser(userID int, city string) (*models.Rank
 	} else {
 		query = baseQuery + `
 		)
-		SELECT video_id, title, processed_url, votes_count, username, city, position
+		SELECT video_id, title, processed_url, thumbnail_url, preview_url, votes_count, username, city, position
 		FROM ranked_videos
 		WHERE user_id = $1
 		ORDER BY votes_count DESC
@@ -199,12 +227,14 @@ func (s *RankingService) GetRankingByUser(userID int, city string) (*models.Rank
 	}
 
 	var entry models.RankingEntry
-	var videoURL sql.NullString
+	var videoURL, thumbnailURL, previewURL sql.NullString
 
 	err := s.db.QueryRow(query, args...).Scan(
 		&entry.VideoID,
 		&entry.Title,
 		&videoURL,
+		&thumbnailURL,
+		&previewURL,
 		&entry.Votes,
 		&entry.Username,
 		&entry.City,
@@ -218,6 +248,8 @@ func (s *RankingService) GetRankingByUser(userID int, city string) (*models.Rank
 	if videoURL.Valid {
 		entry.VideoURL = videoURL.String
 	}
+	entry.ThumbnailURL = thumbnailURL.String
+	entry.PreviewURL = previewURL.String
 
 	return &entry, nil
 }
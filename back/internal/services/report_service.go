@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Errores de las denuncias de videos
+var (
+	ErrVideoNotReportable = errors.New("video not found or not public")
+	ErrReportOwnVideo     = errors.New("cannot report own video")
+	ErrAlreadyReported    = errors.New("video already reported by the user")
+	ErrNoOpenReports      = errors.New("video has no open reports")
+)
+
+// ReportService registra las denuncias de los usuarios sobre videos públicos. Cuando un video
+// llega a VideoReportThreshold denuncias sin resolver se oculta: vuelve a 'pending_review' y
+// aparece en la cola de moderación hasta que un jurado o admin resuelve las denuncias.
+type ReportService struct {
+	db             *sql.DB
+	cfg            *config.Config
+	moderation     *ModerationService
+	rankingService *RankingService
+}
+
+func NewReportService(db *sql.DB, cfg *config.Config, moderation *ModerationService, rankingService *RankingService) *ReportService {
+	return &ReportService{
+		db:             db,
+		cfg:            cfg,
+		moderation:     moderation,
+		rankingService: rankingService,
+	}
+}
+
+// Report registra la denuncia del usuario y oculta el video si llegó al umbral. Cada usuario
+// denuncia un video una sola vez (ErrAlreadyReported).
+func (s *ReportService) Report(ctx context.Context, videoID uuid.UUID, userID int, req models.VideoReportRequest) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO video_reports (video_id, user_id, reason, details)
+		SELECT v.id, $2, $3, NULLIF($4, '')
+		FROM videos v
+		WHERE v.id = $1 AND v.status = 'processed' AND v.is_public = true AND v.user_id <> $2
+		ON CONFLICT (video_id, user_id) DO NOTHING`, videoID, userID, req.Reason, req.Details)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return s.reportError(ctx, videoID, userID)
+	}
+
+	if s.cfg.VideoReportThreshold <= 0 {
+		return nil
+	}
+	hidden, err := s.hideIfReported(ctx, videoID)
+	if err != nil {
+		log.Printf("Warning: failed to check report threshold of video %s: %v", videoID, err)
+		return nil
+	}
+	if hidden {
+		log.Printf("Video %s hidden after reaching %d open reports", videoID, s.cfg.VideoReportThreshold)
+		if err := s.rankingService.RemoveVideo(context.Background(), videoID); err != nil {
+			log.Printf("Warning: failed to remove hidden video %s from ranking cache: %v", videoID, err)
+		}
+	}
+	return nil
+}
+
+// hideIfReported devuelve el video a 'pending_review' si tiene al menos VideoReportThreshold
+// denuncias sin resolver. El UPDATE corre después de confirmar la denuncia para que cuente las
+// denuncias simultáneas ya confirmadas.
+func (s *ReportService) hideIfReported(ctx context.Context, videoID uuid.UUID) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, withVideoEvent(`
+		UPDATE videos SET status = $1, hidden_at = NOW()
+		WHERE id = $2 AND status = $3
+			AND (SELECT COUNT(*) FROM video_reports WHERE video_id = $2 AND resolved_at IS NULL) >= $4`),
+		models.VideoStatusPending, videoID, models.VideoStatusProcessed, s.cfg.VideoReportThreshold)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify('`+rankingEventsChannel+`', 'report')`); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// reportError consulta por qué no se registró la denuncia
+func (s *ReportService) reportError(ctx context.Context, videoID uuid.UUID, userID int) error {
+	var ownerID int
+	var visible bool
+	err := s.db.QueryRowContext(ctx, `SELECT user_id, status = 'processed' AND is_public = true FROM videos WHERE id = $1`,
+		videoID).Scan(&ownerID, &visible)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVideoNotReportable
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !visible:
+		return ErrVideoNotReportable
+	case ownerID == userID:
+		return ErrReportOwnVideo
+	default:
+		return ErrAlreadyReported
+	}
+}
+
+// ListReported retorna los videos con denuncias sin resolver, primero los más denunciados, con
+// sus denuncias y una URL firmada para revisarlos
+func (s *ReportService) ListReported(limit, offset int) ([]models.ReportedVideo, error) {
+	rows, err := s.db.Query(`
+		SELECT v.id, v.title, u.id, u.first_name || ' ' || u.last_name, v.status, v.hidden_at,
+			COALESCE(v.processed_url, ''), v.thumbnail_url, COUNT(r.id), MAX(r.created_at)
+		FROM video_reports r
+		JOIN videos v ON r.video_id = v.id
+		JOIN users u ON v.user_id = u.id
+		WHERE r.resolved_at IS NULL
+		GROUP BY v.id, u.id
+		ORDER BY COUNT(r.id) DESC, MAX(r.created_at) DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	videos := []models.ReportedVideo{}
+	index := map[uuid.UUID]int{}
+	var videoIDs []string
+	for rows.Next() {
+		var video models.ReportedVideo
+		var processedURL string
+		if err := rows.Scan(&video.VideoID, &video.Title, &video.UserID, &video.Username, &video.Status, &video.HiddenAt,
+			&processedURL, &video.ThumbnailURL, &video.OpenReports, &video.LastReportedAt); err != nil {
+			return nil, err
+		}
+		video.VideoURL = s.moderation.signedVideoURL(video.VideoID, processedURL)
+		video.ThumbnailURL = s.moderation.videoService.GenerateMediaURL(video.ThumbnailURL)
+		video.Reasons = map[string]int{}
+		video.Reports = []models.VideoReport{}
+
+		index[video.VideoID] = len(videos)
+		videoIDs = append(videoIDs, video.VideoID.String())
+		videos = append(videos, video)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(videos) == 0 {
+		return videos, nil
+	}
+
+	reports, err := s.db.Query(`
+		SELECT r.id, r.video_id, r.user_id, u.email, r.reason, r.details, r.created_at
+		FROM video_reports r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.resolved_at IS NULL AND r.video_id = ANY($1::uuid[])
+		ORDER BY r.created_at`, pq.Array(videoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer reports.Close()
+
+	for reports.Next() {
+		var report models.VideoReport
+		var videoID uuid.UUID
+		if err := reports.Scan(&report.ID, &videoID, &report.UserID, &report.UserEmail, &report.Reason, &report.Details, &report.CreatedAt); err != nil {
+			return nil, err
+		}
+		video := &videos[index[videoID]]
+		video.Reasons[report.Reason]++
+		video.Reports = append(video.Reports, report)
+	}
+	return videos, reports.Err()
+}
+
+// Resolve resuelve las denuncias sin resolver de un video. dismiss las descarta y, si el video
+// se había ocultado por denuncias, lo vuelve a publicar; remove rechaza el video con reason.
+func (s *ReportService) Resolve(videoID uuid.UUID, reviewerID *int, resolution models.ReportResolution) error {
+	if resolution.Action == "remove" {
+		return s.moderation.Reject(videoID, reviewerID, resolution.Reason)
+	}
+
+	var hidden bool
+	err := s.db.QueryRow(`SELECT hidden_at IS NOT NULL AND status = $2 FROM videos WHERE id = $1`,
+		videoID, models.VideoStatusPending).Scan(&hidden)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVideoNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if hidden {
+		return s.moderation.Approve(videoID, reviewerID)
+	}
+
+	res, err := s.db.Exec(`
+		UPDATE video_reports SET resolved_at = NOW(), resolved_by = $1, resolution = $2
+		WHERE video_id = $3 AND resolved_at IS NULL`, reviewerID, models.ReportResolutionDismissed, videoID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNoOpenReports
+	}
+	return nil
+}
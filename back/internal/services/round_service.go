@@ -0,0 +1,496 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"back/internal/database/models"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Errores de negocio de las competencias y rondas
+var (
+	ErrCompetitionNotFound = errors.New("competition not found")
+	ErrRoundNotFound       = errors.New("round not found")
+	ErrRoundClosed         = errors.New("round is already closed")
+	ErrInvalidRound        = errors.New("invalid round")
+	ErrVotingClosed        = errors.New("no voting round is open")
+	ErrVideoNotInRound     = errors.New("video is not competing in the open rounds")
+	ErrVoteBudgetExhausted = errors.New("no votes left in the round")
+)
+
+// roundCloseInterval es cada cuánto se cierran las rondas cuya ventana terminó
+const roundCloseInterval = time.Minute
+
+// openVotingRound es la condición SQL de una ronda (alias r) que acepta votos ahora
+const openVotingRound = `r.phase <> 'submissions' AND r.closed_at IS NULL AND NOW() >= r.starts_at AND NOW() < r.ends_at`
+
+// roundColumns son las columnas de rounds en el orden que espera scanRound
+const roundColumns = `r.id, r.competition_id, r.name, r.phase, r.starts_at, r.ends_at, r.eligible_cities,
+	r.qualifying_round_id, r.winners_per_city, r.votes_per_user, r.closed_at`
+
+// RoundService gestiona las competencias, sus rondas y los votos de cada ronda. Mientras no haya
+// rondas de votación configuradas, la votación es libre (un único ranking permanente).
+type RoundService struct {
+	db        *sql.DB
+	validator *validator.Validate
+	stop      chan struct{}
+}
+
+func NewRoundService(db *sql.DB) *RoundService {
+	return &RoundService{
+		db:        db,
+		validator: validator.New(),
+		stop:      make(chan struct{}),
+	}
+}
+
+// CreateCompetition crea una competencia sin rondas
+func (s *RoundService) CreateCompetition(req models.CompetitionCreate) (*models.Competition, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRound, err)
+	}
+
+	competition := models.Competition{Name: req.Name, Description: req.Description, Rounds: []models.Round{}}
+	err := s.db.QueryRow(`INSERT INTO competitions (name, description) VALUES ($1, NULLIF($2, '')) RETURNING id, created_at`,
+		req.Name, req.Description).Scan(&competition.ID, &competition.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &competition, nil
+}
+
+// CreateRound agrega una ronda a la competencia
+func (s *RoundService) CreateRound(competitionID int, req models.RoundCreate) (*models.Round, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRound, err)
+	}
+	if req.WinnersPerCity == 0 {
+		req.WinnersPerCity = 3
+	}
+
+	cities := []string{}
+	for _, city := range req.EligibleCities {
+		if city = strings.TrimSpace(city); city != "" {
+			cities = append(cities, city)
+		}
+	}
+
+	var exists bool
+	if err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM competitions WHERE id = $1)`, competitionID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrCompetitionNotFound
+	}
+
+	if req.QualifyingRoundID != nil {
+		if err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM rounds WHERE id = $1 AND competition_id = $2)`,
+			*req.QualifyingRoundID, competitionID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: qualifying round %d is not part of the competition", ErrInvalidRound, *req.QualifyingRoundID)
+		}
+	}
+
+	var roundID int
+	err := s.db.QueryRow(`
+		INSERT INTO rounds (competition_id, name, phase, starts_at, ends_at, eligible_cities, qualifying_round_id, winners_per_city, votes_per_user)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		competitionID, req.Name, req.Phase, req.StartsAt, req.EndsAt, pq.Array(cities), req.QualifyingRoundID, req.WinnersPerCity, req.VotesPerUser,
+	).Scan(&roundID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRound(roundID)
+}
+
+// GetRound obtiene una ronda
+func (s *RoundService) GetRound(roundID int) (*models.Round, error) {
+	round, err := scanRound(s.db.QueryRow(`SELECT `+roundColumns+` FROM rounds r WHERE r.id = $1`, roundID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoundNotFound
+	}
+	return round, err
+}
+
+// ListCompetitions obtiene las competencias con sus rondas, las más recientes primero
+func (s *RoundService) ListCompetitions() ([]models.Competition, error) {
+	rows, err := s.db.Query(`SELECT id, name, COALESCE(description, ''), created_at FROM competitions ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	competitions := []models.Competition{}
+	index := map[int]int{}
+	for rows.Next() {
+		competition := models.Competition{Rounds: []models.Round{}}
+		if err := rows.Scan(&competition.ID, &competition.Name, &competition.Description, &competition.CreatedAt); err != nil {
+			return nil, err
+		}
+		index[competition.ID] = len(competitions)
+		competitions = append(competitions, competition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	roundRows, err := s.db.Query(`SELECT ` + roundColumns + ` FROM rounds r ORDER BY r.starts_at, r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer roundRows.Close()
+
+	for roundRows.Next() {
+		round, err := scanRound(roundRows)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[round.CompetitionID]; ok {
+			competitions[i].Rounds = append(competitions[i].Rounds, *round)
+		}
+	}
+	return competitions, roundRows.Err()
+}
+
+// VotingRound retorna la ronda abierta en la que compite el video, o nil si la votación es libre
+// porque no hay rondas de votación configuradas
+func (s *RoundService) VotingRound(videoID uuid.UUID) (*int, error) {
+	var configured, open bool
+	var roundID sql.NullInt64
+	err := s.db.QueryRow(`
+		SELECT
+			EXISTS(SELECT 1 FROM rounds WHERE phase <> 'submissions'),
+			EXISTS(SELECT 1 FROM rounds r WHERE `+openVotingRound+`),
+			(SELECT r.id
+			 FROM rounds r, videos v
+			 JOIN users u ON v.user_id = u.id
+			 WHERE v.id = $1 AND `+openVotingRound+`
+			   AND (cardinality(r.eligible_cities) = 0 OR u.city = ANY(r.eligible_cities))
+			   AND (r.qualifying_round_id IS NULL OR EXISTS(
+			       SELECT 1 FROM round_winners w WHERE w.round_id = r.qualifying_round_id AND w.video_id = v.id))
+			 ORDER BY r.starts_at DESC
+			 LIMIT 1)`, videoID).Scan(&configured, &open, &roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !configured:
+		return nil, nil
+	case !open:
+		return nil, ErrVotingClosed
+	case !roundID.Valid:
+		return nil, ErrVideoNotInRound
+	}
+	id := int(roundID.Int64)
+	return &id, nil
+}
+
+// UserVotes retorna los votos del usuario en las rondas abiertas (o sus votos libres si no hay
+// rondas de votación), es decir, los votos que todavía cuentan, y lo que le queda por votar en
+// las rondas abiertas que tienen presupuesto de votos
+func (s *RoundService) UserVotes(userID int) (*models.UserVotes, error) {
+	rows, err := s.db.Query(`
+		SELECT vo.video_id, vo.round_id, vo.created_at
+		FROM votes vo
+		LEFT JOIN rounds r ON vo.round_id = r.id
+		WHERE vo.user_id = $1
+		  AND CASE WHEN EXISTS(SELECT 1 FROM rounds WHERE phase <> 'submissions')
+		      THEN r.id IS NOT NULL AND `+openVotingRound+`
+		      ELSE vo.round_id IS NULL END
+		ORDER BY vo.created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := models.UserVotes{Votes: []models.UserVote{}, Budgets: []models.RoundVoteBudget{}}
+	for rows.Next() {
+		var vote models.UserVote
+		var roundID sql.NullInt64
+		if err := rows.Scan(&vote.VideoID, &roundID, &vote.VotedAt); err != nil {
+			return nil, err
+		}
+		if roundID.Valid {
+			id := int(roundID.Int64)
+			vote.RoundID = &id
+		}
+		votes.Votes = append(votes.Votes, vote)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	budgetRows, err := s.db.Query(`
+		SELECT r.id, r.votes_per_user, (SELECT COUNT(*) FROM votes WHERE user_id = $1 AND round_id = r.id)
+		FROM rounds r
+		WHERE r.votes_per_user IS NOT NULL AND `+openVotingRound+`
+		ORDER BY r.starts_at, r.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer budgetRows.Close()
+
+	for budgetRows.Next() {
+		var budget models.RoundVoteBudget
+		var used int
+		if err := budgetRows.Scan(&budget.RoundID, &budget.VotesPerUser, &used); err != nil {
+			return nil, err
+		}
+		budget.RemainingVotes = max(budget.VotesPerUser-used, 0)
+		votes.Budgets = append(votes.Budgets, budget)
+	}
+	return &votes, budgetRows.Err()
+}
+
+// CheckVoteBudget retorna ErrVoteBudgetExhausted si el usuario ya usó todos los votos de la ronda.
+// Los votos anulados siguen ocupando su cupo: no se pueden retirar.
+func (s *RoundService) CheckVoteBudget(userID, roundID int) error {
+	var budget sql.NullInt64
+	var used int
+	err := s.db.QueryRow(`
+		SELECT r.votes_per_user, (SELECT COUNT(*) FROM votes WHERE user_id = $1 AND round_id = r.id)
+		FROM rounds r
+		WHERE r.id = $2`, userID, roundID).Scan(&budget, &used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoundNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if budget.Valid && used >= int(budget.Int64) {
+		return ErrVoteBudgetExhausted
+	}
+	return nil
+}
+
+// GetRoundRankings obtiene el ranking de una ronda con sus votos. Con city, filtra por la ciudad
+// exacta y usa la posición dentro de la ciudad.
+func (s *RoundService) GetRoundRankings(roundID int, city string, limit, offset int) ([]models.RankingEntry, error) {
+	if _, err := s.GetRound(roundID); err != nil {
+		return nil, err
+	}
+
+	position := "global_position"
+	filter := ""
+	args := []interface{}{roundID, limit, offset}
+	if city != "" {
+		position = "city_position"
+		filter = "WHERE city = $4"
+		args = append(args, city)
+	}
+
+	rows, err := s.db.Query(`
+		WITH ranked AS (`+roundRankingQuery+`)
+		SELECT video_id, title, processed_url, thumbnail_url, preview_url, votes_count, username, city, `+position+`
+		FROM ranked
+		`+filter+`
+		ORDER BY `+position+`
+		LIMIT $2 OFFSET $3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rankings []models.RankingEntry
+	for rows.Next() {
+		var entry models.RankingEntry
+		var videoURL, thumbnailURL, previewURL sql.NullString
+		if err := rows.Scan(&entry.VideoID, &entry.Title, &videoURL, &thumbnailURL, &previewURL,
+			&entry.Votes, &entry.Username, &entry.City, &entry.Position); err != nil {
+			return nil, err
+		}
+		entry.VideoURL = videoURL.String
+		entry.ThumbnailURL = thumbnailURL.String
+		entry.PreviewURL = previewURL.String
+		rankings = append(rankings, entry)
+	}
+	return rankings, rows.Err()
+}
+
+// roundRankingQuery ordena los videos de la ronda $1 por votos en la ronda, igual que video_rankings
+const roundRankingQuery = `
+	SELECT
+		v.id AS video_id,
+		v.title,
+		v.processed_url,
+		v.thumbnail_url,
+		v.preview_url,
+		t.votes_count,
+		u.first_name || ' ' || u.last_name AS username,
+		u.city,
+		ROW_NUMBER() OVER (ORDER BY t.votes_count DESC, v.uploaded_at ASC) AS global_position,
+		ROW_NUMBER() OVER (PARTITION BY u.city ORDER BY t.votes_count DESC, v.uploaded_at ASC) AS city_position
+	FROM round_tallies t
+	JOIN videos v ON t.video_id = v.id
+	JOIN users u ON v.user_id = u.id
+	WHERE t.round_id = $1 AND t.votes_count > 0 AND v.is_public = true AND v.status = 'processed'`
+
+// CloseRound cierra la ronda y guarda los winners_per_city primeros de cada ciudad como ganadores
+func (s *RoundService) CloseRound(roundID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// El UPDATE condicionado evita que dos réplicas cierren la misma ronda
+	var winnersPerCity int
+	err = tx.QueryRow(`UPDATE rounds SET closed_at = NOW() WHERE id = $1 AND closed_at IS NULL RETURNING winners_per_city`,
+		roundID).Scan(&winnersPerCity)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.GetRound(roundID); err != nil {
+			return err
+		}
+		return ErrRoundClosed
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		WITH ranked AS (`+roundRankingQuery+`)
+		INSERT INTO round_winners (round_id, video_id, city, votes_count, city_position, global_position)
+		SELECT $1, video_id, city, votes_count, city_position, global_position
+		FROM ranked
+		WHERE city_position <= $2`, roundID, winnersPerCity)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetWinners obtiene los ganadores de una ronda cerrada
+func (s *RoundService) GetWinners(roundID int) ([]models.RoundWinner, error) {
+	if _, err := s.GetRound(roundID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT w.round_id, w.video_id, v.title, u.first_name || ' ' || u.last_name, w.city, w.votes_count, w.city_position, w.global_position
+		FROM round_winners w
+		JOIN videos v ON w.video_id = v.id
+		JOIN users u ON v.user_id = u.id
+		WHERE w.round_id = $1
+		ORDER BY w.global_position`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	winners := []models.RoundWinner{}
+	for rows.Next() {
+		var w models.RoundWinner
+		if err := rows.Scan(&w.RoundID, &w.VideoID, &w.Title, &w.Username, &w.City, &w.Votes, &w.CityPosition, &w.GlobalPosition); err != nil {
+			return nil, err
+		}
+		winners = append(winners, w)
+	}
+	return winners, rows.Err()
+}
+
+// Start lanza el cierre periódico de las rondas cuya ventana terminó
+func (s *RoundService) Start() {
+	go func() {
+		ticker := time.NewTicker(roundCloseInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.closeExpiredRounds()
+			}
+		}
+	}()
+}
+
+// Stop detiene el cierre periódico
+func (s *RoundService) Stop() {
+	close(s.stop)
+}
+
+func (s *RoundService) closeExpiredRounds() {
+	rows, err := s.db.Query(`SELECT id FROM rounds WHERE closed_at IS NULL AND ends_at <= NOW()`)
+	if err != nil {
+		log.Printf("Warning: failed to list expired rounds: %v", err)
+		return
+	}
+
+	var roundIDs []int
+	for rows.Next() {
+		var roundID int
+		if err := rows.Scan(&roundID); err == nil {
+			roundIDs = append(roundIDs, roundID)
+		}
+	}
+	rows.Close()
+
+	for _, roundID := range roundIDs {
+		err := s.CloseRound(roundID)
+		switch {
+		case err == nil:
+			log.Printf("Round %d closed", roundID)
+		case errors.Is(err, ErrRoundClosed):
+			// Otra réplica la cerró primero
+		default:
+			log.Printf("Warning: failed to close round %d: %v", roundID, err)
+		}
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRound(row rowScanner) (*models.Round, error) {
+	var round models.Round
+	var qualifyingRoundID sql.NullInt64
+	var votesPerUser sql.NullInt64
+	var closedAt sql.NullTime
+	err := row.Scan(&round.ID, &round.CompetitionID, &round.Name, &round.Phase, &round.StartsAt, &round.EndsAt,
+		pq.Array(&round.EligibleCities), &qualifyingRoundID, &round.WinnersPerCity, &votesPerUser, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if qualifyingRoundID.Valid {
+		id := int(qualifyingRoundID.Int64)
+		round.QualifyingRoundID = &id
+	}
+	if votesPerUser.Valid {
+		votes := int(votesPerUser.Int64)
+		round.VotesPerUser = &votes
+	}
+	if round.EligibleCities == nil {
+		round.EligibleCities = []string{}
+	}
+
+	now := time.Now()
+	switch {
+	case closedAt.Valid:
+		round.ClosedAt = &closedAt.Time
+		round.Status = models.RoundStatusClosed
+	case now.Before(round.StartsAt):
+		round.Status = models.RoundStatusScheduled
+	case now.Before(round.EndsAt):
+		round.Status = models.RoundStatusOpen
+	default:
+		// Terminó pero aún no se guardaron los ganadores: ya no acepta votos
+		round.Status = models.RoundStatusClosed
+	}
+	return &round, nil
+}
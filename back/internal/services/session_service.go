@@ -0,0 +1,189 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+	"back/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Errores de las sesiones
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token already used, session revoked")
+)
+
+// refreshReuseGrace es cuánto tiempo tras una rotación se tolera el refresh token anterior sin
+// revocar la sesión: dos pestañas que renuevan a la vez no deben cerrar la sesión
+const refreshReuseGrace = 30 * time.Second
+
+// SessionOrigin es desde dónde se inicia o renueva una sesión
+type SessionOrigin struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionService gestiona las sesiones de user_sessions: cada login crea una sesión con un
+// refresh token que rota en cada uso, y los access tokens llevan el jti de la sesión
+type SessionService struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+func NewSessionService(db *sql.DB, cfg *config.Config) *SessionService {
+	return &SessionService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Create inicia una sesión para el usuario y retorna sus tokens
+func (s *SessionService) Create(user *models.User, origin SessionOrigin) (*models.LoginResponse, error) {
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Se aprovecha el login para borrar las sesiones vencidas o revocadas del usuario
+	if _, err := s.db.Exec(`DELETE FROM user_sessions WHERE user_id = $1 AND (expires_at < NOW() OR revoked_at IS NOT NULL)`,
+		user.ID); err != nil {
+		return nil, err
+	}
+
+	var jti string
+	err = s.db.QueryRow(`
+		INSERT INTO user_sessions (user_id, session_token, expires_at, ip_address, user_agent)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3), NULLIF($4, '')::inet, NULLIF($5, ''))
+		RETURNING jti`,
+		user.ID, tokenHash, s.cfg.RefreshTokenExpiration.Seconds(), origin.IP, origin.UserAgent,
+	).Scan(&jti)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokens(user.ID, user.Email, user.Role, jti, refreshToken)
+}
+
+// Refresh rota el refresh token: retorna tokens nuevos y el anterior deja de servir. Presentar un
+// refresh token ya rotado indica que se filtró, y revoca la sesión (ErrRefreshTokenReused).
+func (s *SessionService) Refresh(refreshToken string, origin SessionOrigin) (*models.LoginResponse, error) {
+	presented := hashRefreshToken(refreshToken)
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// El UPDATE condicionado evita que dos peticiones roten el mismo token
+	var userID int
+	var email, role, jti string
+	err = s.db.QueryRow(`
+		UPDATE user_sessions us
+		SET previous_token = us.session_token, session_token = $2, last_accessed_at = NOW(),
+			expires_at = NOW() + make_interval(secs => $3),
+			ip_address = NULLIF($4, '')::inet, user_agent = NULLIF($5, '')
+		FROM users u
+		WHERE us.user_id = u.id AND us.session_token = $1 AND us.revoked_at IS NULL AND us.expires_at > NOW()
+		RETURNING us.user_id, u.email, u.role, us.jti`,
+		presented, newHash, s.cfg.RefreshTokenExpiration.Seconds(), origin.IP, origin.UserAgent,
+	).Scan(&userID, &email, &role, &jti)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.checkReuse(presented)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokens(userID, email, role, jti, newToken)
+}
+
+// checkReuse revoca la sesión si el token presentado es uno ya rotado fuera del margen de gracia
+func (s *SessionService) checkReuse(presented string) error {
+	res, err := s.db.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE previous_token = $1 AND revoked_at IS NULL AND last_accessed_at < NOW() - make_interval(secs => $2)`,
+		presented, refreshReuseGrace.Seconds())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return ErrRefreshTokenReused
+	}
+	return ErrInvalidRefreshToken
+}
+
+// IsActive indica si la sesión del jti sigue vigente (no revocada ni vencida)
+func (s *SessionService) IsActive(jti string) (bool, error) {
+	if _, err := uuid.Parse(jti); err != nil {
+		// Tokens emitidos antes de las sesiones: no tienen un jti de sesión
+		return false, nil
+	}
+
+	var active bool
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM user_sessions WHERE jti = $1 AND revoked_at IS NULL AND expires_at > NOW())`,
+		jti).Scan(&active)
+	return active, err
+}
+
+// Revoke cierra la sesión del jti
+func (s *SessionService) Revoke(jti string) error {
+	_, err := s.db.Exec(`UPDATE user_sessions SET revoked_at = NOW() WHERE jti = $1 AND revoked_at IS NULL`, jti)
+	return err
+}
+
+// RevokeAll cierra todas las sesiones del usuario y retorna cuántas cerró
+func (s *SessionService) RevokeAll(userID int) (int64, error) {
+	res, err := s.db.Exec(`UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RevokeOthers cierra las sesiones del usuario excepto la del jti y retorna cuántas cerró
+func (s *SessionService) RevokeOthers(userID int, jti string) (int64, error) {
+	res, err := s.db.Exec(`UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND jti::text <> $2 AND revoked_at IS NULL`,
+		userID, jti)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SessionService) tokens(userID int, email, role, jti, refreshToken string) (*models.LoginResponse, error) {
+	accessToken, err := utils.GenerateJWT(userID, email, role, jti, s.cfg.JWTSecret, s.cfg.JWTExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(s.cfg.JWTExpiration.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(s.cfg.RefreshTokenExpiration.Seconds()),
+	}, nil
+}
+
+// newRefreshToken genera un refresh token aleatorio y el hash que se guarda en la base de datos
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
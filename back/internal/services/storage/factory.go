@@ -37,5 +37,14 @@ func NewStorage(cfg *config.Config) (Storage, error) {
 
 	// Default: local storage
 	log.Printf("Initializing local storage: uploadPath=%s, processedPath=%s", cfg.UploadPath, cfg.ProcessedPath)
-	return NewLocalStorage(cfg.UploadPath, cfg.ProcessedPath), nil
+	localStorage := NewLocalStorage(cfg.UploadPath, cfg.ProcessedPath)
+
+	// Sin una llave dedicada se firma con el secreto JWT
+	signingKey := cfg.StorageSigningKey
+	if signingKey == "" {
+		signingKey = cfg.JWTSecret
+	}
+	localStorage.SigningKey = []byte(signingKey)
+
+	return localStorage, nil
 }
@@ -1,9 +1,43 @@
 package storage
 
 import (
+	"errors"
+	"io"
 	"mime/multipart"
+	"path"
+	"strings"
+	"time"
 )
 
+// ErrObjectNotFound indica que el archivo solicitado no existe en el storage
+var ErrObjectNotFound = errors.New("object not found")
+
+// UploadedPart identifica una parte ya subida de una carga multiparte
+type UploadedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// PresignedUpload describe cómo subir un archivo directamente al storage sin pasar por la API
+type PresignedUpload struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ProcessedPathFromURL convierte la ruta web guardada en BD ("/videos/<ruta>") en la ruta
+// relativa del storage ("processed/<ruta>"), conservando subdirectorios (ej: playlists HLS)
+func ProcessedPathFromURL(webPath string) string {
+	return "processed/" + strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(webPath, "/videos/")), "/")
+}
+
+// ObjectInfo contiene la metadata de un archivo almacenado
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
 // Storage define la interfaz para almacenamiento de archivos
 // Incluye métodos para upload, descarga y procesamiento de videos
 type Storage interface {
@@ -27,4 +61,44 @@ type Storage interface {
 	// Para S3: retorna URL presignada válida por 1 hora
 	// Para Local: retorna la ruta relativa que sirve Nginx
 	GetPublicURL(processedPath string) (string, error)
+
+	// Métodos para cargas multiparte (subidas reanudables por partes)
+	// InitMultipartUpload inicia una carga multiparte hacia destPath y retorna su identificador
+	InitMultipartUpload(destPath string) (string, error)
+
+	// UploadPart guarda la parte partNumber (desde 1) de la carga y retorna su ETag
+	// Re-subir una parte ya existente la reemplaza
+	UploadPart(destPath, uploadID string, partNumber int, data io.Reader, size int64) (string, error)
+
+	// CompleteMultipartUpload ensambla las partes en orden y deja el archivo final en destPath
+	CompleteMultipartUpload(destPath, uploadID string, parts []UploadedPart) error
+
+	// AbortMultipartUpload descarta las partes subidas de una carga no completada
+	AbortMultipartUpload(destPath, uploadID string) error
+
+	// Métodos para subidas directas (el cliente sube el archivo sin pasar por la API)
+	// PresignUpload genera una URL firmada para subir con PUT exactamente size bytes
+	// del content type indicado a destPath, válida durante expiresIn
+	PresignUpload(destPath, contentType string, size int64, expiresIn time.Duration) (*PresignedUpload, error)
+
+	// StatFile retorna tamaño y content type de un archivo, o ErrObjectNotFound si no existe
+	StatFile(path string) (*ObjectInfo, error)
+
+	// Métodos para validar archivos ya almacenados
+	// ReadHeader lee hasta n bytes desde el inicio del archivo (detección del contenedor)
+	ReadHeader(path string, n int64) ([]byte, error)
+
+	// GetSourceURL retorna una ubicación legible por ffprobe/ffmpeg sin descargar el archivo
+	// Para S3: URL presignada válida durante expiresIn
+	// Para Local: ruta absoluta del archivo
+	GetSourceURL(path string, expiresIn time.Duration) (string, error)
+
+	// SignProcessedURL retorna la URL pública de un archivo procesado sin verificar que exista
+	// (ej: segmentos HLS). processedPath es la ruta web (ej: "/videos/<id>/hls/360p/seg_000.ts")
+	// Para S3: URL presignada válida durante expiresIn
+	// Para Local: la misma ruta, que sirve Nginx
+	SignProcessedURL(processedPath string, expiresIn time.Duration) (string, error)
+
+	// DeletePrefix elimina todos los archivos bajo un directorio del storage (ej: "processed/<id>/hls")
+	DeletePrefix(prefix string) error
 }
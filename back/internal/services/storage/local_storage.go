@@ -1,16 +1,40 @@
 package storage
 
 import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// LocalSignedUploadPath es la ruta de la API que recibe las subidas firmadas en LocalStorage
+const LocalSignedUploadPath = "/api/storage/upload"
+
+// Errores de validación de las subidas firmadas en LocalStorage
+var (
+	ErrInvalidSignature = errors.New("invalid upload signature")
+	ErrSignatureExpired = errors.New("upload signature expired")
 )
 
 type LocalStorage struct {
 	UploadDir    string
 	ProcessedDir string
+
+	// SigningKey firma las URLs de subida directa (equivalente local a las URLs presignadas de S3)
+	SigningKey []byte
 }
 
 func NewLocalStorage(uploadDir, processedDir string) *LocalStorage {
@@ -27,8 +51,9 @@ func (s *LocalStorage) fullPath(rel string) string {
 
 	// Detectar si es un archivo procesado por el prefijo "processed/"
 	if filepath.HasPrefix(rel, "processed/") || filepath.HasPrefix(rel, "processed\\") {
-		// Remover el prefijo "processed/" y unir con ProcessedDir
-		relPath := filepath.Base(rel)
+		// Remover el prefijo "processed/" y unir con ProcessedDir conservando subdirectorios
+		// (Clean sobre una ruta absoluta impide salir de ProcessedDir con "..")
+		relPath := filepath.Clean(string(filepath.Separator) + rel[len("processed/"):])
 		return filepath.Join(s.ProcessedDir, relPath)
 	}
 
@@ -128,3 +153,225 @@ func (s *LocalStorage) GetPublicURL(processedPath string) (string, error) {
 	// Nginx la servirá desde /videos/
 	return processedPath, nil
 }
+
+// SignProcessedURL retorna la ruta tal cual; Nginx sirve /videos/ sin firma
+func (s *LocalStorage) SignProcessedURL(processedPath string, expiresIn time.Duration) (string, error) {
+	return processedPath, nil
+}
+
+// DeletePrefix elimina el directorio completo
+func (s *LocalStorage) DeletePrefix(prefix string) error {
+	full := s.fullPath(prefix)
+	if full == filepath.Clean(s.ProcessedDir) || full == filepath.Clean(s.UploadDir) {
+		return fmt.Errorf("refusing to delete storage root %q", prefix)
+	}
+	return os.RemoveAll(full)
+}
+
+// multipartDir retorna el directorio temporal donde se guardan las partes de una carga
+func (s *LocalStorage) multipartDir(uploadID string) (string, error) {
+	if uploadID == "" || filepath.Base(uploadID) != uploadID {
+		return "", fmt.Errorf("invalid upload id: %q", uploadID)
+	}
+	return filepath.Join(s.UploadDir, ".multipart", uploadID), nil
+}
+
+// InitMultipartUpload crea el directorio de partes para una nueva carga
+func (s *LocalStorage) InitMultipartUpload(destPath string) (string, error) {
+	uploadID := uuid.New().String()
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return uploadID, nil
+}
+
+// UploadPart escribe la parte en el directorio de la carga y retorna su MD5 como ETag
+func (s *LocalStorage) UploadPart(destPath, uploadID string, partNumber int, data io.Reader, size int64) (string, error) {
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("multipart upload %s not found: %w", uploadID, err)
+	}
+
+	partPath := filepath.Join(dir, fmt.Sprintf("%05d.part", partNumber))
+	out, err := os.Create(partPath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(out, hash), data)
+	if err != nil {
+		return "", err
+	}
+	if written != size {
+		return "", fmt.Errorf("part %d size mismatch: expected %d bytes, wrote %d", partNumber, size, written)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CompleteMultipartUpload concatena las partes en destPath y elimina el directorio temporal
+func (s *LocalStorage) CompleteMultipartUpload(destPath, uploadID string, parts []UploadedPart) error {
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	full := s.fullPath(destPath)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(full)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	for _, part := range parts {
+		in, err := os.Open(filepath.Join(dir, fmt.Sprintf("%05d.part", part.PartNumber)))
+		if err != nil {
+			return fmt.Errorf("missing part %d: %w", part.PartNumber, err)
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// AbortMultipartUpload elimina las partes subidas
+func (s *LocalStorage) AbortMultipartUpload(destPath, uploadID string) error {
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// PresignUpload genera una URL firmada con HMAC hacia el handler de subidas de la API,
+// de modo que el flujo de subida directa se pueda probar sin AWS
+func (s *LocalStorage) PresignUpload(destPath, contentType string, size int64, expiresIn time.Duration) (*PresignedUpload, error) {
+	if len(s.SigningKey) == 0 {
+		return nil, fmt.Errorf("local storage signing key not configured")
+	}
+
+	expiresAt := time.Now().UTC().Add(expiresIn)
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("path", destPath)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("content_type", contentType)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signUpload(destPath, contentType, size, expires))
+
+	return &PresignedUpload{
+		Method:    http.MethodPut,
+		URL:       LocalSignedUploadPath + "?" + query.Encode(),
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyUploadSignature valida la firma y vigencia de una URL generada por PresignUpload
+func (s *LocalStorage) VerifyUploadSignature(destPath, contentType string, size, expires int64, signature string) error {
+	if len(s.SigningKey) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := s.signUpload(destPath, contentType, size, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > expires {
+		return ErrSignatureExpired
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) signUpload(destPath, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, s.SigningKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d\n%d", http.MethodPut, destPath, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SaveStream guarda exactamente size bytes en destPath. Escribe a un archivo temporal y lo
+// renombra al final para no dejar archivos truncados si la conexión se corta
+func (s *LocalStorage) SaveStream(destPath string, data io.Reader, size int64) error {
+	if filepath.Base(destPath) != destPath {
+		return fmt.Errorf("invalid destination path: %q", destPath)
+	}
+
+	full := s.fullPath(destPath)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	tmp := full + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(out, io.LimitReader(data, size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written != size {
+		err = fmt.Errorf("size mismatch: expected %d bytes, received %d", size, written)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, full)
+}
+
+// StatFile retorna el tamaño del archivo. Como en S3, el content type corresponde al que
+// se firmó en la URL de subida (SignedUpload lo exige); el contenido real se valida aparte
+func (s *LocalStorage) StatFile(path string) (*ObjectInfo, error) {
+	info, err := os.Stat(s.fullPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Size:        info.Size(),
+		ContentType: ContentTypeFor(path),
+	}, nil
+}
+
+// ReadHeader lee los primeros n bytes del archivo
+func (s *LocalStorage) ReadHeader(path string, n int64) ([]byte, error) {
+	f, err := os.Open(s.fullPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, n))
+}
+
+// GetSourceURL retorna la ruta absoluta del archivo (ffprobe la lee directamente)
+func (s *LocalStorage) GetSourceURL(path string, expiresIn time.Duration) (string, error) {
+	return filepath.Abs(s.fullPath(path))
+}
@@ -3,11 +3,13 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -62,26 +64,12 @@ func (s *S3Storage) SaveFile(file multipart.File, destPath string) error {
 	// Determinar el key completo en S3
 	key := s.getS3Key(destPath)
 
-	// Detectar el content type
-	contentType := "application/octet-stream"
-	ext := filepath.Ext(destPath)
-	switch ext {
-	case ".mp4":
-		contentType = "video/mp4"
-	case ".avi":
-		contentType = "video/x-msvideo"
-	case ".mov":
-		contentType = "video/quicktime"
-	case ".mkv":
-		contentType = "video/x-matroska"
-	}
-
 	// Subir el archivo a S3
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucketName),
 		Key:         aws.String(key),
 		Body:        bytes.NewReader(buf.Bytes()),
-		ContentType: aws.String(contentType),
+		ContentType: aws.String(ContentTypeFor(destPath)),
 		Metadata: map[string]string{
 			"uploaded-at": time.Now().Format(time.RFC3339),
 		},
@@ -94,6 +82,33 @@ func (s *S3Storage) SaveFile(file multipart.File, destPath string) error {
 	return nil
 }
 
+// ContentTypeFor detecta el content type a partir de la extensión del archivo
+func ContentTypeFor(path string) string {
+	switch filepath.Ext(path) {
+	case ".mp4":
+		return "video/mp4"
+	case ".avi":
+		return "video/x-msvideo"
+	case ".mov":
+		return "video/quicktime"
+	case ".mkv":
+		return "video/x-matroska"
+	case ".webm":
+		return "video/webm"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".ts":
+		return "video/mp2t"
+	}
+	return "application/octet-stream"
+}
+
 // DeleteFile elimina un archivo de S3
 func (s *S3Storage) DeleteFile(path string) error {
 	ctx := context.TODO()
@@ -240,9 +255,10 @@ func (s *S3Storage) getS3Key(path string) string {
 
 	// Detectar si usa el prefijo genérico "processed/"
 	if filepath.HasPrefix(path, "processed/") || filepath.HasPrefix(path, "processed\\") {
-		// Remover "processed/" y construir con el prefijo S3 configurado
-		fileName := filepath.Base(path)
-		return filepath.Join(s.processedPrefix, fileName)
+		// Remover "processed/" y construir con el prefijo S3 configurado, conservando
+		// subdirectorios (ej: "processed/<id>/hls/360p/seg_000.ts")
+		relPath := filepath.Clean("/" + path[len("processed/"):])
+		return filepath.Join(s.processedPrefix, relPath)
 	}
 
 	// Detectar si usa el prefijo genérico "uploads/"
@@ -335,7 +351,7 @@ func (s *S3Storage) UploadFromFile(sourcePath string, destPath string) error {
 		Bucket:      aws.String(s.bucketName),
 		Key:         aws.String(key),
 		Body:        bytes.NewReader(data),
-		ContentType: aws.String("video/mp4"),
+		ContentType: aws.String(ContentTypeFor(destPath)),
 		Metadata: map[string]string{
 			"processed-at": time.Now().Format(time.RFC3339),
 		},
@@ -353,12 +369,9 @@ func (s *S3Storage) UploadFromFile(sourcePath string, destPath string) error {
 func (s *S3Storage) GetPublicURL(processedPath string) (string, error) {
 	ctx := context.TODO()
 
-	// Extraer el nombre del archivo desde la ruta
-	// Si viene como "/videos/video-123_processed.mp4", extraer "video-123_processed.mp4"
-	filename := filepath.Base(processedPath)
-
-	// Construir el key de S3
-	key := filepath.Join(s.processedPrefix, filename)
+	// Construir el key de S3 desde la ruta web
+	// Si viene como "/videos/<id>/hls/master.m3u8", el key es "<processedPrefix>/<id>/hls/master.m3u8"
+	key := s.getS3Key(ProcessedPathFromURL(processedPath))
 
 	// Verificar que el objeto existe
 	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
@@ -387,3 +400,234 @@ func (s *S3Storage) GetPublicURL(processedPath string) (string, error) {
 
 	return request.URL, nil
 }
+
+// SignProcessedURL genera una URL presignada para un archivo procesado sin consultar S3 antes
+func (s *S3Storage) SignProcessedURL(processedPath string, expiresIn time.Duration) (string, error) {
+	return s.GetFileURL(ProcessedPathFromURL(processedPath), expiresIn)
+}
+
+// DeletePrefix elimina todos los objetos bajo el prefijo, en lotes de hasta 1000 keys
+func (s *S3Storage) DeletePrefix(prefix string) error {
+	ctx := context.TODO()
+
+	keyPrefix := strings.TrimSuffix(s.getS3Key(prefix), "/") + "/"
+	if keyPrefix == "/" || keyPrefix == s.processedPrefix+"/" || keyPrefix == s.uploadPrefix+"/" {
+		return fmt.Errorf("refusing to delete storage root %q", prefix)
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(keyPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing objects (prefix=%s): %w", keyPrefix, err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		objects := make([]types.ObjectIdentifier, len(page.Contents))
+		for i, obj := range page.Contents {
+			objects[i] = types.ObjectIdentifier{Key: obj.Key}
+		}
+		if _, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucketName),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		}); err != nil {
+			return fmt.Errorf("error deleting objects (prefix=%s): %w", keyPrefix, err)
+		}
+	}
+
+	return nil
+}
+
+// InitMultipartUpload inicia una carga multiparte en S3 y retorna el UploadId asignado
+func (s *S3Storage) InitMultipartUpload(destPath string) (string, error) {
+	ctx := context.TODO()
+
+	key := s.getS3Key(destPath)
+
+	result, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(ContentTypeFor(destPath)),
+		Metadata: map[string]string{
+			"uploaded-at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating multipart upload (key=%s): %w", key, err)
+	}
+
+	return aws.ToString(result.UploadId), nil
+}
+
+// UploadPart sube una parte de la carga multiparte
+// S3 exige que todas las partes excepto la última tengan al menos 5MB
+func (s *S3Storage) UploadPart(destPath, uploadID string, partNumber int, data io.Reader, size int64) (string, error) {
+	ctx := context.TODO()
+
+	key := s.getS3Key(destPath)
+
+	// El SDK necesita un body con Seek para firmar la petición
+	body, ok := data.(io.ReadSeeker)
+	if !ok {
+		buf, err := io.ReadAll(data)
+		if err != nil {
+			return "", fmt.Errorf("error reading part %d: %w", partNumber, err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	result, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(partNumber)),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading part %d to S3 (key=%s): %w", partNumber, key, err)
+	}
+
+	return aws.ToString(result.ETag), nil
+}
+
+// CompleteMultipartUpload ensambla las partes subidas en el objeto final
+func (s *S3Storage) CompleteMultipartUpload(destPath, uploadID string, parts []UploadedPart) error {
+	ctx := context.TODO()
+
+	key := s.getS3Key(destPath)
+
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(int32(part.PartNumber)),
+		})
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucketName),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("error completing multipart upload (key=%s): %w", key, err)
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload cancela la carga multiparte y libera las partes almacenadas
+func (s *S3Storage) AbortMultipartUpload(destPath, uploadID string) error {
+	ctx := context.TODO()
+
+	key := s.getS3Key(destPath)
+
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("error aborting multipart upload (key=%s): %w", key, err)
+	}
+
+	return nil
+}
+
+// PresignUpload genera una URL presignada de PUT; el tamaño y el content type quedan firmados,
+// por lo que S3 rechaza subidas que no coincidan
+func (s *S3Storage) PresignUpload(destPath, contentType string, size int64, expiresIn time.Duration) (*PresignedUpload, error) {
+	ctx := context.TODO()
+
+	key := s.getS3Key(destPath)
+
+	presignClient := s3.NewPresignClient(s.client)
+
+	request, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiresIn
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating presigned upload URL (key=%s): %w", key, err)
+	}
+
+	// El cliente debe reenviar los headers firmados; Host lo pone el navegador
+	headers := make(map[string]string)
+	for name, values := range request.SignedHeader {
+		if strings.EqualFold(name, "Host") || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+
+	return &PresignedUpload{
+		Method:    request.Method,
+		URL:       request.URL,
+		Headers:   headers,
+		ExpiresAt: time.Now().UTC().Add(expiresIn),
+	}, nil
+}
+
+// StatFile consulta la metadata del objeto con HeadObject
+func (s *S3Storage) StatFile(path string) (*ObjectInfo, error) {
+	ctx := context.TODO()
+
+	key := s.getS3Key(path)
+
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &notFound) || errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("error reading object metadata (key=%s): %w", key, err)
+	}
+
+	return &ObjectInfo{
+		Size:        aws.ToInt64(result.ContentLength),
+		ContentType: aws.ToString(result.ContentType),
+	}, nil
+}
+
+// ReadHeader descarga solo los primeros n bytes del objeto con un GET por rango
+func (s *S3Storage) ReadHeader(path string, n int64) ([]byte, error) {
+	ctx := context.TODO()
+
+	key := s.getS3Key(path)
+
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", n-1)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("error reading object header (key=%s): %w", key, err)
+	}
+	defer result.Body.Close()
+
+	return io.ReadAll(io.LimitReader(result.Body, n))
+}
+
+// GetSourceURL retorna una URL presignada de lectura; ffprobe/ffmpeg leen solo los rangos que necesitan
+func (s *S3Storage) GetSourceURL(path string, expiresIn time.Duration) (string, error) {
+	return s.GetFileURL(path, expiresIn)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"database/sql"
+
+	"back/internal/database/models"
+)
+
+// TaskService registra el ciclo de vida de las tareas asíncronas en task_results
+type TaskService struct {
+	db *sql.DB
+}
+
+func NewTaskService(db *sql.DB) *TaskService {
+	return &TaskService{db: db}
+}
+
+// CreateTask registra una tarea recién encolada en estado 'pending'
+func (s *TaskService) CreateTask(taskID, videoID string) error {
+	query := `
+		INSERT INTO task_results (task_id, video_id, status, attempts, created_at, updated_at)
+		VALUES ($1, $2, $3, 0, NOW(), NOW())`
+
+	_, err := s.db.Exec(query, taskID, videoID, models.TaskStatusPending)
+	return err
+}
+
+// MarkTaskRunning pasa la tarea a 'running' e incrementa el contador de intentos.
+// Si la tarea no fue registrada al encolar (mensajes antiguos), se crea en este punto.
+func (s *TaskService) MarkTaskRunning(taskID, videoID string) error {
+	query := `
+		INSERT INTO task_results (task_id, video_id, status, attempts, created_at, started_at, updated_at)
+		VALUES ($1, $2, $3, 1, NOW(), NOW(), NOW())
+		ON CONFLICT (task_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			attempts = task_results.attempts + 1,
+			started_at = NOW(),
+			completed_at = NULL,
+			updated_at = NOW()`
+
+	_, err := s.db.Exec(query, taskID, videoID, models.TaskStatusRunning)
+	return err
+}
+
+// MarkTaskCompleted marca la tarea como completada
+func (s *TaskService) MarkTaskCompleted(taskID string) error {
+	query := `
+		UPDATE task_results
+		SET status = $1, error_message = NULL, completed_at = NOW(), updated_at = NOW()
+		WHERE task_id = $2`
+
+	_, err := s.db.Exec(query, models.TaskStatusCompleted, taskID)
+	return err
+}
+
+// MarkTaskFailed marca la tarea como fallida guardando el motivo del error
+func (s *TaskService) MarkTaskFailed(taskID, errorMessage string) error {
+	query := `
+		UPDATE task_results
+		SET status = $1, error_message = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE task_id = $3`
+
+	_, err := s.db.Exec(query, models.TaskStatusFailed, errorMessage, taskID)
+	return err
+}
+
+// MarkTaskRetrying registra el error de un intento que la cola volverá a entregar
+func (s *TaskService) MarkTaskRetrying(taskID, errorMessage string) error {
+	query := `
+		UPDATE task_results
+		SET status = $1, error_message = $2, updated_at = NOW()
+		WHERE task_id = $3`
+
+	_, err := s.db.Exec(query, models.TaskStatusRetrying, errorMessage, taskID)
+	return err
+}
+
+// MarkTaskInterrupted devuelve la tarea a 'pending' cuando el worker se apagó antes de terminarla
+func (s *TaskService) MarkTaskInterrupted(taskID string) error {
+	query := `
+		UPDATE task_results
+		SET status = $1, error_message = $2, updated_at = NOW()
+		WHERE task_id = $3`
+
+	_, err := s.db.Exec(query, models.TaskStatusPending, "interrupted by worker shutdown", taskID)
+	return err
+}
+
+// GetTasksByVideo lista las tareas de un video, de la más reciente a la más antigua
+func (s *TaskService) GetTasksByVideo(videoID string) ([]models.TaskResult, error) {
+	query := `
+		SELECT id, task_id, video_id, status, error_message, attempts, created_at, started_at, completed_at
+		FROM task_results
+		WHERE video_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, videoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.TaskResult{}
+	for rows.Next() {
+		var t models.TaskResult
+		if err := rows.Scan(&t.ID, &t.TaskID, &t.VideoID, &t.Status, &t.ErrorMessage, &t.Attempts, &t.CreatedAt, &t.StartedAt, &t.CompletedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+	"back/internal/services/storage"
+
+	"github.com/google/uuid"
+)
+
+// Errores de negocio de las subidas reanudables
+var (
+	ErrUploadNotFound    = errors.New("upload not found")
+	ErrUploadNotActive   = errors.New("upload is not active")
+	ErrUploadExpired     = errors.New("upload has expired")
+	ErrInvalidPartNumber = errors.New("invalid part number")
+	ErrChunkSizeMismatch = errors.New("chunk size does not match the expected size")
+	ErrChecksumMismatch  = errors.New("chunk checksum mismatch")
+	ErrUploadIncomplete  = errors.New("upload is missing parts")
+
+	ErrUploadTypeMismatch   = errors.New("operation not supported for this upload type")
+	ErrUploadObjectMissing  = errors.New("uploaded file not found in storage")
+	ErrUploadObjectMismatch = errors.New("uploaded file does not match the declared size or content type")
+)
+
+const (
+	// uploadCleanupInterval es el tiempo mínimo entre barridos de subidas vencidas
+	uploadCleanupInterval = time.Hour
+	// defaultUploadChunkSize se usa si UPLOAD_CHUNK_SIZE no es válido
+	defaultUploadChunkSize = 8 << 20
+	// minS3UploadChunkSize es el tamaño mínimo de cada parte (salvo la última) de una carga
+	// multiparte de S3; con partes menores CompleteMultipartUpload falla con EntityTooSmall
+	minS3UploadChunkSize = 5 << 20
+)
+
+// UploadService gestiona las subidas reanudables por partes (init/append/complete)
+// y las subidas directas al storage con URL firmada (init/confirm)
+type UploadService struct {
+	db           *sql.DB
+	cfg          *config.Config
+	storage      storage.Storage
+	videoService VideoServiceInterface
+	lastCleanup  atomic.Int64
+}
+
+func NewUploadService(db *sql.DB, cfg *config.Config, st storage.Storage, videoService VideoServiceInterface) *UploadService {
+	if cfg.StorageType == "s3" && cfg.UploadChunkSize > 0 && cfg.UploadChunkSize < minS3UploadChunkSize {
+		log.Printf("Warning: UPLOAD_CHUNK_SIZE=%d is below the 5MB minimum part size of S3; using %d", cfg.UploadChunkSize, int64(minS3UploadChunkSize))
+	}
+	return &UploadService{db: db, cfg: cfg, storage: st, videoService: videoService}
+}
+
+// chunkSize retorna el tamaño de parte de las subidas nuevas: UPLOAD_CHUNK_SIZE, o el valor por
+// defecto si no es válido. Con S3 no baja del mínimo de la carga multiparte.
+func (s *UploadService) chunkSize() int64 {
+	chunkSize := s.cfg.UploadChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	if s.cfg.StorageType == "s3" && chunkSize < minS3UploadChunkSize {
+		chunkSize = minS3UploadChunkSize
+	}
+	return chunkSize
+}
+
+// totalParts calcula la cantidad de partes en que se divide el archivo
+func totalParts(totalSize, chunkSize int64) int {
+	return int((totalSize + chunkSize - 1) / chunkSize)
+}
+
+// expectedPartSize retorna el tamaño esperado de una parte (la última puede ser menor)
+func expectedPartSize(session *models.UploadSession, partNumber int) int64 {
+	if partNumber < session.TotalParts {
+		return session.ChunkSize
+	}
+	return session.TotalSize - int64(session.TotalParts-1)*session.ChunkSize
+}
+
+// InitUpload crea una sesión de subida y la carga multiparte en el storage
+func (s *UploadService) InitUpload(userID int64, req models.UploadInit) (*models.UploadSession, error) {
+	s.maybeCleanupExpired()
+
+	videoID := uuid.New()
+	filename := filepath.Base(req.Filename)
+	storagePath := fmt.Sprintf("%s_%s", videoID, filename)
+
+	chunkSize := s.chunkSize()
+
+	storageUploadID, err := s.storage.InitMultipartUpload(storagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init multipart upload: %w", err)
+	}
+
+	var uploadID string
+	query := `
+		INSERT INTO upload_sessions (user_id, video_id, title, original_filename, is_public, total_size, chunk_size, storage_path, storage_upload_id, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	err = s.db.QueryRow(query,
+		userID, videoID, req.Title, filename, req.IsPublic, req.Size, chunkSize,
+		storagePath, storageUploadID, models.UploadStatusActive, time.Now().UTC().Add(s.cfg.UploadSessionTTL),
+	).Scan(&uploadID)
+	if err != nil {
+		_ = s.storage.AbortMultipartUpload(storagePath, storageUploadID)
+		return nil, err
+	}
+
+	return s.GetUpload(uploadID, userID)
+}
+
+// GetUpload obtiene la sesión con las partes recibidas; solo el dueño puede consultarla
+func (s *UploadService) GetUpload(uploadID string, userID int64) (*models.UploadSession, error) {
+	query := `
+		SELECT id, upload_type, user_id, video_id, title, original_filename, is_public, total_size, chunk_size,
+			storage_path, COALESCE(storage_upload_id, ''), COALESCE(content_type, ''), status, created_at, expires_at
+		FROM upload_sessions
+		WHERE id = $1`
+
+	var u models.UploadSession
+	err := s.db.QueryRow(query, uploadID).Scan(
+		&u.ID, &u.UploadType, &u.UserID, &u.VideoID, &u.Title, &u.OriginalFilename, &u.IsPublic, &u.TotalSize, &u.ChunkSize,
+		&u.StoragePath, &u.StorageUploadID, &u.ContentType, &u.Status, &u.CreatedAt, &u.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if int64(u.UserID) != userID {
+		return nil, ErrUploadNotFound
+	}
+	u.TotalParts = totalParts(u.TotalSize, u.ChunkSize)
+
+	rows, err := s.db.Query(`SELECT part_number, size FROM upload_session_parts WHERE upload_id = $1 ORDER BY part_number`, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	u.ReceivedParts = []int{}
+	for rows.Next() {
+		var partNumber int
+		var size int64
+		if err := rows.Scan(&partNumber, &size); err != nil {
+			return nil, err
+		}
+		u.ReceivedParts = append(u.ReceivedParts, partNumber)
+		u.ReceivedBytes += size
+	}
+
+	return &u, rows.Err()
+}
+
+// AppendChunk valida el tamaño y el checksum SHA-256 de una parte y la envía al storage.
+// Re-enviar una parte ya recibida la reemplaza, lo que permite reintentar tras un corte.
+func (s *UploadService) AppendChunk(uploadID string, userID int64, partNumber int, data io.Reader, checksum string) (*models.UploadPart, error) {
+	session, err := s.GetUpload(uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UploadType != models.UploadTypeChunked {
+		return nil, ErrUploadTypeMismatch
+	}
+	if session.Status != models.UploadStatusActive {
+		return nil, ErrUploadNotActive
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	if partNumber < 1 || partNumber > session.TotalParts {
+		return nil, ErrInvalidPartNumber
+	}
+
+	expected := expectedPartSize(session, partNumber)
+	buf, err := io.ReadAll(io.LimitReader(data, expected+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk: %w", err)
+	}
+	if int64(len(buf)) != expected {
+		return nil, ErrChunkSizeMismatch
+	}
+
+	sum := sha256.Sum256(buf)
+	digest := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(digest, strings.TrimSpace(checksum)) {
+		return nil, ErrChecksumMismatch
+	}
+
+	etag, err := s.storage.UploadPart(session.StoragePath, session.StorageUploadID, partNumber, bytes.NewReader(buf), expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	query := `
+		INSERT INTO upload_session_parts (upload_id, part_number, size, checksum, etag)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (upload_id, part_number) DO UPDATE
+		SET size = EXCLUDED.size, checksum = EXCLUDED.checksum, etag = EXCLUDED.etag, created_at = NOW()`
+	if _, err := s.db.Exec(query, uploadID, partNumber, expected, digest, etag); err != nil {
+		return nil, err
+	}
+	_, _ = s.db.Exec(`UPDATE upload_sessions SET updated_at = NOW() WHERE id = $1`, uploadID)
+
+	return &models.UploadPart{PartNumber: partNumber, Size: expected, Checksum: digest, ETag: etag}, nil
+}
+
+// CompleteUpload ensambla las partes y registra el video. Solo se invoca cuando todas
+// las partes llegaron; el encolado del procesamiento queda a cargo del llamador.
+func (s *UploadService) CompleteUpload(uploadID string, userID int64) (*models.UploadSession, error) {
+	session, err := s.GetUpload(uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UploadType != models.UploadTypeChunked {
+		return nil, ErrUploadTypeMismatch
+	}
+	if err := s.claimUpload(session); err != nil {
+		return nil, err
+	}
+
+	parts, err := s.getStoredParts(uploadID)
+	if err == nil && len(parts) != session.TotalParts {
+		err = ErrUploadIncomplete
+	}
+	if err != nil {
+		s.setUploadStatus(uploadID, models.UploadStatusActive)
+		return nil, err
+	}
+
+	if err := s.storage.CompleteMultipartUpload(session.StoragePath, session.StorageUploadID, parts); err != nil {
+		s.setUploadStatus(uploadID, models.UploadStatusActive)
+		return nil, fmt.Errorf("failed to assemble upload: %w", err)
+	}
+
+	// Las partes ya se ensamblaron, así que si el video se rechaza no hay nada que reintentar
+	videoID := session.VideoID.String()
+	if err := s.videoService.RegisterUploadedVideo(videoID, userID, session.Title, session.OriginalFilename, session.StoragePath, session.IsPublic); err != nil {
+		s.setUploadStatus(uploadID, models.UploadStatusAborted)
+		var validationErr *models.VideoValidationError
+		if errors.As(err, &validationErr) {
+			return nil, err
+		}
+		_ = s.storage.DeleteFile(session.StoragePath)
+		return nil, fmt.Errorf("failed to register video: %w", err)
+	}
+
+	s.setUploadStatus(uploadID, models.UploadStatusCompleted)
+	session.Status = models.UploadStatusCompleted
+	return session, nil
+}
+
+// AbortUpload cancela una subida activa y descarta las partes almacenadas
+func (s *UploadService) AbortUpload(uploadID string, userID int64) error {
+	session, err := s.GetUpload(uploadID, userID)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec(`UPDATE upload_sessions SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`,
+		models.UploadStatusAborted, uploadID, models.UploadStatusActive)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrUploadNotActive
+	}
+
+	return discardUploadData(s.storage, session.UploadType, session.StoragePath, session.StorageUploadID)
+}
+
+// CleanupExpiredUploads aborta las subidas activas que superaron su vigencia
+func (s *UploadService) CleanupExpiredUploads() error {
+	rows, err := s.db.Query(`
+		UPDATE upload_sessions SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND expires_at < NOW()
+		RETURNING upload_type, storage_path, COALESCE(storage_upload_id, '')`,
+		models.UploadStatusAborted, models.UploadStatusActive)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uploadType, storagePath, storageUploadID string
+		if err := rows.Scan(&uploadType, &storagePath, &storageUploadID); err != nil {
+			return err
+		}
+		if err := discardUploadData(s.storage, uploadType, storagePath, storageUploadID); err != nil {
+			log.Printf("Warning: failed to abort expired upload %s: %v", storagePath, err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// maybeCleanupExpired lanza el barrido de subidas vencidas como máximo una vez por intervalo
+func (s *UploadService) maybeCleanupExpired() {
+	now := time.Now().Unix()
+	last := s.lastCleanup.Load()
+	if now-last < int64(uploadCleanupInterval.Seconds()) || !s.lastCleanup.CompareAndSwap(last, now) {
+		return
+	}
+
+	go func() {
+		if err := s.CleanupExpiredUploads(); err != nil {
+			log.Printf("Warning: failed to cleanup expired uploads: %v", err)
+		}
+	}()
+}
+
+// InitDirectUpload registra una subida directa y retorna la URL firmada con la que el
+// cliente sube el archivo al storage sin pasar por la API
+func (s *UploadService) InitDirectUpload(userID int64, req models.UploadInit) (*models.DirectUpload, error) {
+	s.maybeCleanupExpired()
+
+	videoID := uuid.New()
+	filename := filepath.Base(req.Filename)
+	storagePath := fmt.Sprintf("%s_%s", videoID, filename)
+	contentType := storage.ContentTypeFor(filename)
+
+	presigned, err := s.storage.PresignUpload(storagePath, contentType, req.Size, s.cfg.PresignedUploadTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	var uploadID uuid.UUID
+	query := `
+		INSERT INTO upload_sessions (upload_type, user_id, video_id, title, original_filename, is_public, total_size, chunk_size, storage_path, content_type, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`
+
+	err = s.db.QueryRow(query,
+		models.UploadTypeDirect, userID, videoID, req.Title, filename, req.IsPublic, req.Size, req.Size,
+		storagePath, contentType, models.UploadStatusActive, time.Now().UTC().Add(s.cfg.UploadSessionTTL),
+	).Scan(&uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DirectUpload{
+		UploadID:  uploadID,
+		VideoID:   videoID,
+		Method:    presigned.Method,
+		URL:       presigned.URL,
+		Headers:   presigned.Headers,
+		ExpiresAt: presigned.ExpiresAt,
+	}, nil
+}
+
+// ConfirmDirectUpload verifica que el archivo exista en el storage con el tamaño y content
+// type declarados y registra el video. El encolado del procesamiento queda a cargo del llamador.
+func (s *UploadService) ConfirmDirectUpload(uploadID string, userID int64) (*models.UploadSession, error) {
+	session, err := s.GetUpload(uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UploadType != models.UploadTypeDirect {
+		return nil, ErrUploadTypeMismatch
+	}
+	if err := s.claimUpload(session); err != nil {
+		return nil, err
+	}
+
+	info, err := s.storage.StatFile(session.StoragePath)
+	if err != nil {
+		s.setUploadStatus(uploadID, models.UploadStatusActive)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrUploadObjectMissing
+		}
+		return nil, err
+	}
+	if info.Size != session.TotalSize || !strings.EqualFold(info.ContentType, session.ContentType) {
+		// Descartar el archivo para que el cliente pueda volver a subirlo con la misma URL
+		if err := s.storage.DeleteFile(session.StoragePath); err != nil {
+			log.Printf("Warning: failed to delete mismatched upload %s: %v", session.StoragePath, err)
+		}
+		s.setUploadStatus(uploadID, models.UploadStatusActive)
+		return nil, fmt.Errorf("%w: expected %d bytes (%s), got %d bytes (%s)",
+			ErrUploadObjectMismatch, session.TotalSize, session.ContentType, info.Size, info.ContentType)
+	}
+
+	// Si el video se rechaza, el archivo ya fue eliminado y el cliente puede volver a subirlo
+	videoID := session.VideoID.String()
+	if err := s.videoService.RegisterUploadedVideo(videoID, userID, session.Title, session.OriginalFilename, session.StoragePath, session.IsPublic); err != nil {
+		s.setUploadStatus(uploadID, models.UploadStatusActive)
+		var validationErr *models.VideoValidationError
+		if errors.As(err, &validationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to register video: %w", err)
+	}
+
+	s.setUploadStatus(uploadID, models.UploadStatusCompleted)
+	session.Status = models.UploadStatusCompleted
+	return session, nil
+}
+
+// claimUpload pasa la sesión de 'active' a 'completing' para evitar que dos peticiones
+// la completen a la vez
+func (s *UploadService) claimUpload(session *models.UploadSession) error {
+	if time.Now().After(session.ExpiresAt) {
+		return ErrUploadExpired
+	}
+
+	res, err := s.db.Exec(`UPDATE upload_sessions SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`,
+		models.UploadStatusCompleting, session.ID, models.UploadStatusActive)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrUploadNotActive
+	}
+
+	return nil
+}
+
+// discardUploadData libera lo que la subida dejó en el storage: las partes de una carga
+// multiparte o el archivo subido directamente (si llegó a subirse)
+func discardUploadData(st storage.Storage, uploadType, storagePath, storageUploadID string) error {
+	if uploadType == models.UploadTypeDirect {
+		if _, err := st.StatFile(storagePath); errors.Is(err, storage.ErrObjectNotFound) {
+			return nil
+		}
+		return st.DeleteFile(storagePath)
+	}
+	return st.AbortMultipartUpload(storagePath, storageUploadID)
+}
+
+func (s *UploadService) getStoredParts(uploadID string) ([]storage.UploadedPart, error) {
+	rows, err := s.db.Query(`SELECT part_number, etag FROM upload_session_parts WHERE upload_id = $1 ORDER BY part_number`, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []storage.UploadedPart
+	for rows.Next() {
+		var part storage.UploadedPart
+		if err := rows.Scan(&part.PartNumber, &part.ETag); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, rows.Err()
+}
+
+func (s *UploadService) setUploadStatus(uploadID, status string) {
+	if _, err := s.db.Exec(`UPDATE upload_sessions SET status = $1, updated_at = NOW() WHERE id = $2`, status, uploadID); err != nil {
+		log.Printf("Warning: failed to set upload %s status to %s: %v", uploadID, status, err)
+	}
+}
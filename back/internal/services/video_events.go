@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/lib/pq"
+)
+
+// videoEventsChannel es el canal de LISTEN/NOTIFY por el que se publican los eventos de video
+const videoEventsChannel = "video_events"
+
+// videoEventBuffer es cuántos eventos se encolan por suscriptor antes de descartar los nuevos
+const videoEventBuffer = 16
+
+// withVideoEvent agrega a un UPDATE de videos el NOTIFY con el estado resultante. El evento
+// sale al confirmarse la transacción y llega a todas las réplicas de la API, sin importar
+// si lo emitió el worker o la API.
+func withVideoEvent(update string) string {
+	return `WITH v AS (` + update + ` RETURNING id, user_id, status, progress, processing_stage, failure_reason)
+		SELECT pg_notify('` + videoEventsChannel + `', json_build_object(
+			'video_id', v.id, 'user_id', v.user_id, 'status', v.status, 'progress', v.progress,
+			'processing_stage', v.processing_stage, 'failure_reason', v.failure_reason, 'at', now()
+		)::text) FROM v`
+}
+
+// VideoEventBroker escucha los eventos de video en Postgres y los reparte entre las
+// conexiones abiertas de cada usuario
+type VideoEventBroker struct {
+	listener *pq.Listener
+
+	mu          sync.Mutex
+	subscribers map[int64]map[chan models.VideoEvent]struct{}
+}
+
+// NewVideoEventBroker abre la conexión de LISTEN; si se cae, pq reconecta sola
+func NewVideoEventBroker(cfg *config.Config) (*VideoEventBroker, error) {
+	listener := pq.NewListener(cfg.GetDatabaseDSN(), 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Warning: video events listener: %v", err)
+		}
+	})
+	if err := listener.Listen(videoEventsChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", videoEventsChannel, err)
+	}
+
+	b := &VideoEventBroker{
+		listener:    listener,
+		subscribers: make(map[int64]map[chan models.VideoEvent]struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+// Subscribe retorna los eventos de los videos de userID; la función retornada cancela la suscripción
+func (b *VideoEventBroker) Subscribe(userID int64) (<-chan models.VideoEvent, func()) {
+	ch := make(chan models.VideoEvent, videoEventBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.VideoEvent]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+}
+
+// Close deja de escuchar eventos
+func (b *VideoEventBroker) Close() error {
+	return b.listener.Close()
+}
+
+func (b *VideoEventBroker) run() {
+	for n := range b.listener.Notify {
+		// pq envía nil al reconectar: los eventos emitidos mientras tanto se pierden
+		if n == nil {
+			continue
+		}
+
+		var event models.VideoEvent
+		if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+			log.Printf("Warning: invalid video event payload: %v", err)
+			continue
+		}
+		b.publish(event)
+	}
+}
+
+// publish entrega el evento sin bloquear: un cliente lento pierde eventos en vez de frenar al resto
+func (b *VideoEventBroker) publish(event models.VideoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Warning: dropping event of video %s for a slow subscriber", event.VideoID)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"back/internal/services/storage"
+)
+
+const (
+	// hlsMasterPlaylist es el nombre del playlist maestro que genera el paso hls del pipeline
+	hlsMasterPlaylist = "master.m3u8"
+	// maxPlaylistSize limita la lectura de un playlist (un VOD de minutos ocupa pocos KB)
+	maxPlaylistSize = 1 << 20
+	// hlsSegmentURLTTL es la validez de las URLs firmadas de cada segmento
+	hlsSegmentURLTTL = time.Hour
+)
+
+// ErrPlaylistNotFound indica que el video no tiene salida HLS o el playlist no existe
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// isHLSPath indica si la ruta guardada en processed_url es un playlist HLS
+func isHLSPath(processedPath string) bool {
+	return strings.HasSuffix(processedPath, ".m3u8")
+}
+
+// hlsVideoID extrae el id del video de "/videos/<id>/hls/master.m3u8"
+func hlsVideoID(processedPath string) string {
+	rest := strings.TrimPrefix(processedPath, "/videos/")
+	if i := strings.Index(rest, "/"); i > 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+// GenerateFallbackURL retorna la URL del MP4 procesado cuando processed_url apunta a un playlist
+// HLS (para navegadores sin soporte HLS). Retorna nil si el video no tiene salida HLS.
+func (s *VideoService) GenerateFallbackURL(processedPath *string) *string {
+	if processedPath == nil || !isHLSPath(*processedPath) {
+		return nil
+	}
+	videoID := hlsVideoID(*processedPath)
+	if videoID == "" {
+		return nil
+	}
+
+	mp4Path := fmt.Sprintf("/videos/%s_processed.mp4", videoID)
+	return s.GeneratePublicURL(&mp4Path)
+}
+
+// hlsPlaylistURL retorna la URL del playlist maestro en la API, firmada por mediaURLTTL para que
+// el dueño de un video privado pueda reproducirlo
+func (s *VideoService) hlsPlaylistURL(videoID string) string {
+	return fmt.Sprintf("/api/public/videos/%s/hls/%s?token=%s", videoID, hlsMasterPlaylist,
+		s.hlsToken(videoID, time.Now().Add(mediaURLTTL).Unix()))
+}
+
+// hlsToken firma el acceso a los playlists de un video hasta expires: "<expires>.<firma>"
+func (s *VideoService) hlsToken(videoID string, expires int64) string {
+	key := s.cfg.StorageSigningKey
+	if key == "" {
+		key = s.cfg.JWTSecret
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "hls:%s:%d", videoID, expires)
+	return strconv.FormatInt(expires, 10) + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validHLSToken verifica la firma y la vigencia del token de los playlists del video
+func (s *VideoService) validHLSToken(videoID, token string) bool {
+	expiresStr, _, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(s.hlsToken(videoID, expires)))
+}
+
+// GetHLSPlaylist retorna un playlist HLS del video con las URLs de los segmentos firmadas.
+// name es relativo al directorio HLS ("master.m3u8" o "<variante>/index.m3u8"); las referencias
+// a otros playlists se dejan relativas para que el reproductor las pida a esta misma API. Los
+// videos privados solo se sirven con un token vigente, que se agrega a esas referencias.
+func (s *VideoService) GetHLSPlaylist(videoID, name, token string) ([]byte, error) {
+	var processedURL string
+	var isPublic bool
+	err := s.db.QueryRow(`SELECT COALESCE(processed_url, ''), COALESCE(is_public, false) FROM videos WHERE id=$1 AND status='processed'`,
+		videoID).Scan(&processedURL, &isPublic)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, err
+	}
+	if !isHLSPath(processedURL) {
+		return nil, ErrPlaylistNotFound
+	}
+
+	// Un video privado responde como inexistente sin token, para no revelar que existe
+	signed := token != "" && s.validHLSToken(videoID, token)
+	if !isPublic && !signed {
+		return nil, ErrPlaylistNotFound
+	}
+
+	name = path.Clean("/" + name)[1:]
+	hlsDir := fmt.Sprintf("/videos/%s/hls", videoID)
+
+	data, err := s.storage.ReadHeader(storage.ProcessedPathFromURL(hlsDir+"/"+name), maxPlaylistSize+1)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, err
+	}
+	if len(data) > maxPlaylistSize {
+		return nil, fmt.Errorf("playlist %s exceeds %d bytes", name, maxPlaylistSize)
+	}
+
+	// Las URIs de segmentos son relativas al directorio del playlist
+	baseDir := path.Dir(hlsDir + "/" + name)
+
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasSuffix(line, ".m3u8"):
+			if signed {
+				line += "?token=" + token
+			}
+		default:
+			segmentURL, err := s.storage.SignProcessedURL(path.Join(baseDir, line), hlsSegmentURLTTL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign segment %s: %w", line, err)
+			}
+			line = segmentURL
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid playlist %s: %w", name, err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -16,15 +16,25 @@ import (
 // VideoServiceInterface define el contrato para las operaciones de video
 type VideoServiceInterface interface {
 	CreateVideo(userID int64, title string, file multipart.File, filename string, isPublic bool) (string, error)
+	RegisterUploadedVideo(videoID string, userID int64, title, filename, origPath string, isPublic bool) error
 	GetVideosByUser(userID int64) ([]models.Video, error)
 	GetVideoByID(videoID string, userID int64) (*models.Video, error)
 	MarkProcessing(videoID string) error
+	UpdateProgress(videoID, stage string, percent int) error
 	MarkProcessed(videoID, processedPath string) error
 	MarkFailed(videoID, reason string) error
+	ResetProcessing(videoID string) error
 	DeleteVideo(videoID string, userID int64) error
 	GeneratePublicURL(processedPath *string) *string
+	GenerateFallbackURL(processedPath *string) *string
+	GetHLSPlaylist(videoID, name, token string) ([]byte, error)
+	SetMediaURLs(videoID, thumbnailPath, previewPath string) error
+	GenerateMediaURL(mediaPath *string) *string
 }
 
+// mediaURLTTL es la validez de las URLs firmadas de miniaturas y vistas previas
+const mediaURLTTL = time.Hour
+
 type VideoService struct {
 	db      *sql.DB
 	cfg     *config.Config
@@ -41,6 +51,15 @@ func (s *VideoService) GeneratePublicURL(processedPath *string) *string {
 		return nil
 	}
 
+	// Los playlists HLS se sirven desde la API, que firma cada segmento. La URL del maestro va
+	// firmada porque el video puede ser privado
+	if isHLSPath(*processedPath) {
+		if videoID := hlsVideoID(*processedPath); videoID != "" {
+			playlistURL := s.hlsPlaylistURL(videoID)
+			return &playlistURL
+		}
+	}
+
 	// Generar URL pública usando el storage
 	publicURL, err := s.storage.GetPublicURL(*processedPath)
 	if err != nil {
@@ -51,6 +70,20 @@ func (s *VideoService) GeneratePublicURL(processedPath *string) *string {
 	return &publicURL
 }
 
+// GenerateMediaURL firma la URL de la miniatura o la vista previa. No verifica que el archivo
+// exista (evita una consulta al storage por cada video de una grilla).
+func (s *VideoService) GenerateMediaURL(mediaPath *string) *string {
+	if mediaPath == nil || *mediaPath == "" {
+		return nil
+	}
+
+	signedURL, err := s.storage.SignProcessedURL(*mediaPath, mediaURLTTL)
+	if err != nil {
+		return mediaPath
+	}
+	return &signedURL
+}
+
 // CreateVideo guarda metadata y guarda archivo en storage local
 func (s *VideoService) CreateVideo(userID int64, title string, file multipart.File, filename string, isPublic bool) (string, error) {
 	id := uuid.New().String()
@@ -61,18 +94,38 @@ func (s *VideoService) CreateVideo(userID int64, title string, file multipart.Fi
 		return "", err
 	}
 
-	_, err := s.db.Exec(`INSERT INTO videos (id, user_id, title, original_filename, original_url, status, uploaded_at, is_public) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
-		id, userID, title, filename, origPath, "uploaded", uploadedAt, isPublic)
-	if err != nil {
+	if err := s.validateStoredVideo(origPath, filename); err != nil {
+		_ = s.storage.DeleteFile(origPath)
+		return "", err
+	}
+
+	if err := s.insertVideo(id, userID, title, filename, origPath, isPublic, uploadedAt); err != nil {
 		return "", err
 	}
 
 	return id, nil
 }
 
+// RegisterUploadedVideo valida y registra la metadata de un video cuyo archivo ya está en el
+// storage (subidas reanudables o directas). Si el archivo se rechaza, se elimina del storage.
+func (s *VideoService) RegisterUploadedVideo(videoID string, userID int64, title, filename, origPath string, isPublic bool) error {
+	if err := s.validateStoredVideo(origPath, filename); err != nil {
+		_ = s.storage.DeleteFile(origPath)
+		return err
+	}
+
+	return s.insertVideo(videoID, userID, title, filename, origPath, isPublic, time.Now().UTC())
+}
+
+func (s *VideoService) insertVideo(id string, userID int64, title, filename, origPath string, isPublic bool, uploadedAt time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO videos (id, user_id, title, original_filename, original_url, status, uploaded_at, is_public) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		id, userID, title, filename, origPath, "uploaded", uploadedAt, isPublic)
+	return err
+}
+
 // GetVideosByUser lista videos de un usuario
 func (s *VideoService) GetVideosByUser(userID int64) ([]models.Video, error) {
-	rows, err := s.db.Query(`SELECT id, title, original_filename, original_url, status, uploaded_at, processed_at, processed_url, COALESCE(votes_count, 0), COALESCE(is_public, false) FROM videos WHERE user_id=$1 ORDER BY uploaded_at DESC`, userID)
+	rows, err := s.db.Query(`SELECT id, title, original_filename, original_url, status, uploaded_at, processed_at, processed_url, thumbnail_url, preview_url, failure_reason, progress, processing_stage, COALESCE(votes_count, 0), COALESCE(is_public, false), reviewed_at, rejection_reason FROM videos WHERE user_id=$1 ORDER BY uploaded_at DESC`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +133,7 @@ func (s *VideoService) GetVideosByUser(userID int64) ([]models.Video, error) {
 	var videos []models.Video
 	for rows.Next() {
 		var v models.Video
-		err := rows.Scan(&v.ID, &v.Title, &v.OriginalFilename, &v.OriginalURL, &v.Status, &v.UploadedAt, &v.ProcessedAt, &v.ProcessedURL, &v.VotesCount, &v.IsPublic)
+		err := rows.Scan(&v.ID, &v.Title, &v.OriginalFilename, &v.OriginalURL, &v.Status, &v.UploadedAt, &v.ProcessedAt, &v.ProcessedURL, &v.ThumbnailURL, &v.PreviewURL, &v.FailureReason, &v.Progress, &v.ProcessingStage, &v.VotesCount, &v.IsPublic, &v.ReviewedAt, &v.RejectionReason)
 		if err != nil {
 			return nil, err
 		}
@@ -91,9 +144,9 @@ func (s *VideoService) GetVideosByUser(userID int64) ([]models.Video, error) {
 
 // GetVideoByID obtiene el video por id y user ownership check (userID 0 -> no check)
 func (s *VideoService) GetVideoByID(videoID string, userID int64) (*models.Video, error) {
-	row := s.db.QueryRow(`SELECT id, user_id, title, original_filename, original_url, status, uploaded_at, processed_at, processed_url, COALESCE(votes_count, 0), COALESCE(is_public, false) FROM videos WHERE id=$1`, videoID)
+	row := s.db.QueryRow(`SELECT id, user_id, title, original_filename, original_url, status, uploaded_at, processed_at, processed_url, thumbnail_url, preview_url, failure_reason, progress, processing_stage, COALESCE(votes_count, 0), COALESCE(is_public, false), reviewed_at, rejection_reason FROM videos WHERE id=$1`, videoID)
 	var v models.Video
-	if err := row.Scan(&v.ID, &v.UserID, &v.Title, &v.OriginalFilename, &v.OriginalURL, &v.Status, &v.UploadedAt, &v.ProcessedAt, &v.ProcessedURL, &v.VotesCount, &v.IsPublic); err != nil {
+	if err := row.Scan(&v.ID, &v.UserID, &v.Title, &v.OriginalFilename, &v.OriginalURL, &v.Status, &v.UploadedAt, &v.ProcessedAt, &v.ProcessedURL, &v.ThumbnailURL, &v.PreviewURL, &v.FailureReason, &v.Progress, &v.ProcessingStage, &v.VotesCount, &v.IsPublic, &v.ReviewedAt, &v.RejectionReason); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -108,30 +161,51 @@ func (s *VideoService) GetVideoByID(videoID string, userID int64) (*models.Video
 
 // MarkProcessing marca el video como 'en proceso'
 func (s *VideoService) MarkProcessing(videoID string) error {
-	_, err := s.db.Exec(`UPDATE videos SET status=$1 WHERE id=$2`, "processing", videoID)
+	_, err := s.db.Exec(withVideoEvent(`UPDATE videos SET status=$1, failure_reason=NULL, progress=0, processing_stage=NULL WHERE id=$2`), "processing", videoID)
+	return err
+}
+
+// UpdateProgress guarda el avance del procesamiento; no toca videos que ya salieron de 'processing'
+func (s *VideoService) UpdateProgress(videoID, stage string, percent int) error {
+	_, err := s.db.Exec(withVideoEvent(`UPDATE videos SET progress=$1, processing_stage=$2 WHERE id=$3 AND status=$4`), min(max(percent, 0), 100), stage, videoID, "processing")
 	return err
 }
 
-// MarkProcessed actualiza el estado y processed_url y processed_at
+// MarkProcessed actualiza el estado y processed_url y processed_at. Con la moderación activa, los
+// videos públicos quedan en 'pending_review' hasta que se aprueban.
 func (s *VideoService) MarkProcessed(videoID, processedPath string) error {
 	processedAt := time.Now().UTC()
-	_, err := s.db.Exec(`UPDATE videos SET status=$1, processed_url=$2, processed_at=$3 WHERE id=$4`, "processed", processedPath, processedAt, videoID)
+	_, err := s.db.Exec(withVideoEvent(`UPDATE videos SET status = CASE WHEN $1 AND is_public THEN $2 ELSE $3 END, processed_url=$4, processed_at=$5, failure_reason=NULL, progress=100, processing_stage=NULL WHERE id=$6`),
+		s.cfg.VideoModerationEnabled, models.VideoStatusPending, models.VideoStatusProcessed, processedPath, processedAt, videoID)
 	return err
 }
 
-// MarkFailed anota fallos
+// SetMediaURLs guarda las rutas web de la miniatura y la vista previa (vacías -> NULL)
+func (s *VideoService) SetMediaURLs(videoID, thumbnailPath, previewPath string) error {
+	_, err := s.db.Exec(`UPDATE videos SET thumbnail_url=NULLIF($1, ''), preview_url=NULLIF($2, '') WHERE id=$3`, thumbnailPath, previewPath, videoID)
+	return err
+}
+
+// ResetProcessing devuelve a 'uploaded' un video cuyo procesamiento se interrumpió (apagado del
+// worker), para que no quede en 'processing' mientras la tarea espera en la cola
+func (s *VideoService) ResetProcessing(videoID string) error {
+	_, err := s.db.Exec(withVideoEvent(`UPDATE videos SET status=$1, progress=NULL, processing_stage=NULL WHERE id=$2 AND status=$3`), "uploaded", videoID, "processing")
+	return err
+}
+
+// MarkFailed marca el fallo definitivo del procesamiento guardando el motivo
 func (s *VideoService) MarkFailed(videoID, reason string) error {
-	_, err := s.db.Exec(`UPDATE videos SET status=$1 WHERE id=$2`, "failed", videoID)
-	// opcional: insertar en tabla de logs
+	_, err := s.db.Exec(withVideoEvent(`UPDATE videos SET status=$1, failure_reason=$2, progress=NULL, processing_stage=NULL WHERE id=$3`), "failed", reason, videoID)
 	return err
 }
 
 // DeleteVideo borra registros y archivos (solo si estado permitido)
 func (s *VideoService) DeleteVideo(videoID string, userID int64) error {
-	row := s.db.QueryRow(`SELECT user_id, status, original_url, processed_url FROM videos WHERE id=$1`, videoID)
+	row := s.db.QueryRow(`SELECT user_id, status, hidden_at IS NOT NULL, original_url, processed_url, thumbnail_url, preview_url FROM videos WHERE id=$1`, videoID)
 	var owner int64
-	var status, orig, proc sql.NullString
-	if err := row.Scan(&owner, &status, &orig, &proc); err != nil {
+	var hidden bool
+	var status, orig, proc, thumb, preview sql.NullString
+	if err := row.Scan(&owner, &status, &hidden, &orig, &proc, &thumb, &preview); err != nil {
 		return err
 	}
 	if owner != userID {
@@ -140,12 +214,21 @@ func (s *VideoService) DeleteVideo(videoID string, userID int64) error {
 	if status.String == "processed" {
 		return fmt.Errorf("video processed or published; cannot delete")
 	}
+	// Los videos ocultos por denuncias o rechazados conservan sus archivos y denuncias para la revisión
+	if hidden || status.String == models.VideoStatusRejected {
+		return ErrVideoUnderReview
+	}
 	if orig.Valid && orig.String != "" {
 		_ = s.storage.DeleteFile(orig.String)
 	}
 	if proc.Valid && proc.String != "" {
 		_ = s.storage.DeleteFile(proc.String)
 	}
+	for _, media := range []sql.NullString{thumb, preview} {
+		if media.Valid && media.String != "" {
+			_ = s.storage.DeleteFile(storage.ProcessedPathFromURL(media.String))
+		}
+	}
 	_, err := s.db.Exec(`DELETE FROM videos WHERE id=$1`, videoID)
 	return err
 }
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"back/internal/database/models"
+	"back/internal/utils"
+)
+
+// Códigos de rechazo de la validación de videos subidos
+const (
+	ValidationUnsupportedContainer = "unsupported_container"
+	ValidationContainerMismatch    = "container_mismatch"
+	ValidationCorruptFile          = "corrupt_file"
+	ValidationProbeTimeout         = "probe_timeout"
+	ValidationNoVideoStream        = "no_video_stream"
+	ValidationInvalidDuration      = "invalid_duration"
+)
+
+// sniffHeaderSize es la cantidad de bytes leídos para identificar el contenedor
+const sniffHeaderSize = 4096
+
+// sourceURLTTL es la vigencia de la URL con la que ffprobe lee el archivo del storage
+const sourceURLTTL = 5 * time.Minute
+
+// sameContainerFamily indica si dos contenedores comparten formato (MP4/MOV son ISO BMFF,
+// WebM es un subconjunto de Matroska), por lo que ffmpeg los lee igual
+func sameContainerFamily(a, b string) bool {
+	family := func(c string) string {
+		switch c {
+		case utils.ContainerMP4, utils.ContainerMOV:
+			return "isobmff"
+		case utils.ContainerWebM, utils.ContainerMKV:
+			return "matroska"
+		}
+		return c
+	}
+	return family(a) == family(b)
+}
+
+// validateStoredVideo verifica que el archivo ya guardado en el storage sea un video que el
+// pipeline pueda procesar: revisa los bytes mágicos del contenedor y ejecuta un ffprobe acotado.
+// Retorna *models.VideoValidationError cuando el archivo se rechaza.
+func (s *VideoService) validateStoredVideo(storagePath, filename string) error {
+	header, err := s.storage.ReadHeader(storagePath, sniffHeaderSize)
+	if err != nil {
+		return fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	container := utils.SniffVideoContainer(header)
+	if container == "" {
+		return &models.VideoValidationError{
+			Code:    ValidationUnsupportedContainer,
+			Message: "The file is not an MP4, MOV, WebM or MKV video",
+		}
+	}
+	if expected := utils.VideoContainerFromFilename(filename); !sameContainerFamily(container, expected) {
+		return &models.VideoValidationError{
+			Code:      ValidationContainerMismatch,
+			Message:   fmt.Sprintf("The file content is %s but its extension is %s", container, expected),
+			Container: container,
+		}
+	}
+
+	source, err := s.storage.GetSourceURL(storagePath, sourceURLTTL)
+	if err != nil {
+		return fmt.Errorf("failed to resolve uploaded file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.UploadProbeTimeout)
+	defer cancel()
+
+	probe, err := utils.ProbeVideo(ctx, source)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &models.VideoValidationError{
+				Code:      ValidationProbeTimeout,
+				Message:   "The file could not be analyzed in time",
+				Container: container,
+			}
+		}
+		log.Printf("ffprobe rejected %s: %v", storagePath, err)
+		return &models.VideoValidationError{
+			Code:      ValidationCorruptFile,
+			Message:   "The file is corrupt or could not be read as a video",
+			Container: container,
+		}
+	}
+
+	if !probe.HasVideo || probe.Width <= 0 || probe.Height <= 0 {
+		return &models.VideoValidationError{
+			Code:      ValidationNoVideoStream,
+			Message:   "The file does not contain a video stream",
+			Container: container,
+			Duration:  probe.Duration,
+		}
+	}
+	if probe.Duration <= 0 {
+		return &models.VideoValidationError{
+			Code:      ValidationInvalidDuration,
+			Message:   "The video duration could not be determined",
+			Container: container,
+			Codec:     probe.VideoCodec,
+			Width:     probe.Width,
+			Height:    probe.Height,
+		}
+	}
+
+	return nil
+}
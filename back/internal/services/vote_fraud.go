@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Motivos por los que el análisis marca un voto como sospechoso
+const (
+	VoteFlagFreshAccounts = "fresh_accounts" // muchas cuentas recién creadas votando por el mismo video
+	VoteFlagSharedIP      = "shared_ip"      // muchos usuarios votando por el mismo video desde la misma IP
+)
+
+// VoteOrigin es desde dónde se emitió un voto
+type VoteOrigin struct {
+	IP        string
+	UserAgent string
+	DeviceID  string // header X-Device-ID generado por el cliente; puede venir vacío
+}
+
+// VoteLimitError indica que se superó un límite de votos por ventana
+type VoteLimitError struct {
+	Scope      string // user, ip o device
+	RetryAfter time.Duration
+}
+
+func (e *VoteLimitError) Error() string {
+	return fmt.Sprintf("vote limit per %s exceeded", e.Scope)
+}
+
+// clusterQuery marca los votos de un grupo: votos por el mismo video (y la misma partición)
+// separados a lo sumo por VoteFraudWindow, cuando el grupo llega a VoteFraudMinCluster votos.
+// Solo revisa los votos recientes: los anteriores ya se revisaron en pasadas previas.
+const clusterQuery = `
+	WITH recent AS (
+		SELECT vo.id, vo.flagged_at,
+			COUNT(*) OVER (PARTITION BY vo.video_id, %s ORDER BY vo.created_at
+				RANGE BETWEEN make_interval(secs => $1) PRECEDING AND make_interval(secs => $1) FOLLOWING) AS cluster_size
+		FROM votes vo
+		JOIN users u ON vo.user_id = u.id
+		WHERE vo.created_at > NOW() - 3 * make_interval(secs => $1) AND vo.voided_at IS NULL AND %s
+	)
+	UPDATE votes SET flagged_at = NOW(), flag_reason = $2
+	FROM recent
+	WHERE votes.id = recent.id AND recent.flagged_at IS NULL AND recent.cluster_size >= $3`
+
+// VoteFraudService aplica los límites de votos y busca y anula votos fraudulentos
+type VoteFraudService struct {
+	db             *sql.DB
+	cfg            *config.Config
+	rankingService *RankingService
+	stop           chan struct{}
+}
+
+func NewVoteFraudService(db *sql.DB, cfg *config.Config, rankingService *RankingService) *VoteFraudService {
+	return &VoteFraudService{
+		db:             db,
+		cfg:            cfg,
+		rankingService: rankingService,
+		stop:           make(chan struct{}),
+	}
+}
+
+// CheckRateLimit retorna un *VoteLimitError si el usuario, su IP o su dispositivo ya emitieron
+// el máximo de votos en la ventana VoteLimitWindow
+func (s *VoteFraudService) CheckRateLimit(userID int, origin VoteOrigin) error {
+	var counts [3]int
+	var oldest [3]sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT
+			COUNT(*) FILTER (WHERE user_id = $1), MIN(created_at) FILTER (WHERE user_id = $1),
+			COUNT(*) FILTER (WHERE ip_address = $2::inet), MIN(created_at) FILTER (WHERE ip_address = $2::inet),
+			COUNT(*) FILTER (WHERE device_id = $3), MIN(created_at) FILTER (WHERE device_id = $3)
+		FROM votes
+		WHERE created_at > NOW() - make_interval(secs => $4)
+		  AND (user_id = $1 OR ip_address = $2::inet OR device_id = $3)`,
+		userID, nullIfEmpty(origin.IP), nullIfEmpty(origin.DeviceID), s.cfg.VoteLimitWindow.Seconds(),
+	).Scan(&counts[0], &oldest[0], &counts[1], &oldest[1], &counts[2], &oldest[2])
+	if err != nil {
+		return err
+	}
+
+	limits := [3]int{s.cfg.VoteLimitPerUser, s.cfg.VoteLimitPerIP, s.cfg.VoteLimitPerDevice}
+	for i, scope := range [3]string{"user", "ip", "device"} {
+		if limits[i] <= 0 || counts[i] < limits[i] {
+			continue
+		}
+		// Al vencer el voto más antiguo de la ventana se libera un cupo
+		retryAfter := s.cfg.VoteLimitWindow
+		if oldest[i].Valid {
+			retryAfter = time.Until(oldest[i].Time.Add(s.cfg.VoteLimitWindow))
+		}
+		return &VoteLimitError{Scope: scope, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Scan marca como sospechosos los grupos de votos recientes y retorna cuántos votos marcó
+func (s *VoteFraudService) Scan() (int64, error) {
+	window := s.cfg.VoteFraudWindow.Seconds()
+	var flagged int64
+
+	res, err := s.db.Exec(fmt.Sprintf(clusterQuery, "true", "vo.created_at - u.created_at < make_interval(secs => $4)"),
+		window, VoteFlagFreshAccounts, s.cfg.VoteFraudMinCluster, s.cfg.VoteFraudAccountAge.Seconds())
+	if err != nil {
+		return flagged, err
+	}
+	n, _ := res.RowsAffected()
+	flagged += n
+
+	res, err = s.db.Exec(fmt.Sprintf(clusterQuery, "vo.ip_address", "vo.ip_address IS NOT NULL"),
+		window, VoteFlagSharedIP, s.cfg.VoteFraudMinCluster)
+	if err != nil {
+		return flagged, err
+	}
+	n, _ = res.RowsAffected()
+	return flagged + n, nil
+}
+
+// Start lanza el análisis periódico de votos
+func (s *VoteFraudService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.cfg.VoteFraudScanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				flagged, err := s.Scan()
+				if err != nil {
+					log.Printf("Warning: vote fraud scan failed: %v", err)
+				}
+				if flagged > 0 {
+					log.Printf("Vote fraud scan flagged %d votes", flagged)
+				}
+			}
+		}
+	}()
+}
+
+// Stop detiene el análisis periódico
+func (s *VoteFraudService) Stop() {
+	close(s.stop)
+}
+
+// ListFlagged obtiene los votos marcados que aún no se revisaron, opcionalmente de un video
+func (s *VoteFraudService) ListFlagged(videoID *uuid.UUID, limit int) ([]models.FlaggedVote, error) {
+	rows, err := s.db.Query(`
+		SELECT vo.id, vo.video_id, v.title, vo.user_id, u.email, u.created_at, vo.round_id,
+			COALESCE(host(vo.ip_address), ''), COALESCE(vo.user_agent, ''), COALESCE(vo.device_id, ''),
+			vo.flag_reason, vo.flagged_at, vo.created_at
+		FROM votes vo
+		JOIN videos v ON vo.video_id = v.id
+		JOIN users u ON vo.user_id = u.id
+		WHERE vo.flagged_at IS NOT NULL AND vo.reviewed_at IS NULL AND vo.voided_at IS NULL
+		  AND ($1::uuid IS NULL OR vo.video_id = $1)
+		ORDER BY vo.flagged_at DESC, vo.id
+		LIMIT $2`, videoID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := []models.FlaggedVote{}
+	for rows.Next() {
+		var vote models.FlaggedVote
+		var roundID sql.NullInt64
+		if err := rows.Scan(&vote.ID, &vote.VideoID, &vote.VideoTitle, &vote.UserID, &vote.UserEmail, &vote.AccountCreatedAt,
+			&roundID, &vote.IPAddress, &vote.UserAgent, &vote.DeviceID, &vote.FlagReason, &vote.FlaggedAt, &vote.CreatedAt); err != nil {
+			return nil, err
+		}
+		if roundID.Valid {
+			id := int(roundID.Int64)
+			vote.RoundID = &id
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+// VoidVotes anula los votos marcados indicados (por ID o los pendientes de un video) y recalcula los
+// votos de los videos afectados. Retorna cuántos votos anuló.
+func (s *VoteFraudService) VoidVotes(voteIDs []int64, videoID *uuid.UUID) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE votes SET voided_at = NOW()
+		WHERE flagged_at IS NOT NULL AND voided_at IS NULL AND (id = ANY($1) OR (video_id = $2 AND reviewed_at IS NULL))
+		RETURNING video_id`, pq.Array(voteIDs), videoID)
+	if err != nil {
+		return 0, err
+	}
+
+	voided := map[uuid.UUID]int{}
+	total := 0
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		voided[id]++
+		total++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	videoIDs := make([]string, 0, len(voided))
+	for id := range voided {
+		videoIDs = append(videoIDs, id.String())
+	}
+
+	// Los triggers solo cuentan inserciones y borrados: los contadores se recalculan desde votes
+	if _, err := tx.Exec(`
+		UPDATE videos v SET votes_count = (SELECT COUNT(*) FROM votes WHERE video_id = v.id AND voided_at IS NULL)
+		WHERE v.id = ANY($1::uuid[])`, pq.Array(videoIDs)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE round_tallies t SET votes_count = (
+			SELECT COUNT(*) FROM votes WHERE round_id = t.round_id AND video_id = t.video_id AND voided_at IS NULL)
+		WHERE t.video_id = ANY($1::uuid[])`, pq.Array(videoIDs)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`SELECT pg_notify('` + rankingEventsChannel + `', 'void')`); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for id, n := range voided {
+		if err := s.rankingService.RecordVote(context.Background(), id, -n); err != nil {
+			log.Printf("Warning: failed to remove voided votes of video %s from ranking cache: %v", id, err)
+		}
+	}
+	return total, nil
+}
+
+// DismissFlags da por revisados votos marcados que son legítimos: salen de la lista y el análisis
+// no vuelve a marcarlos. Retorna cuántos votos descartó.
+func (s *VoteFraudService) DismissFlags(voteIDs []int64) (int64, error) {
+	res, err := s.db.Exec(`
+		UPDATE votes SET reviewed_at = NOW()
+		WHERE id = ANY($1) AND flagged_at IS NOT NULL AND reviewed_at IS NULL AND voided_at IS NULL`, pq.Array(voteIDs))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Errores de negocio de la votación
+var (
+	ErrVideoNotVotable      = errors.New("video not found or not available for voting")
+	ErrOwnVideo             = errors.New("cannot vote for own video")
+	ErrAlreadyVoted         = errors.New("already voted for this video")
+	ErrVoteNotFound         = errors.New("vote not found")
+	ErrIdempotencyKeyReused = errors.New("idempotency key already used for another vote")
+)
+
+// insertVoteQuery registra el voto en una sola sentencia: las condiciones del SELECT (video
+// público y procesado, ajeno, ronda abierta y con cupo) y el ON CONFLICT reemplazan las
+// verificaciones previas, que con peticiones concurrentes dejaban pasar duplicados. Si no
+// inserta, no retorna filas y voteRejection averigua el motivo.
+const insertVoteQuery = `
+	INSERT INTO votes (user_id, video_id, round_id, ip_address, user_agent, device_id, idempotency_key, created_at)
+	SELECT $1, v.id, $3::int, NULLIF($4, '')::inet, $5, NULLIF($6, ''), NULLIF($7, ''), NOW()
+	FROM videos v
+	WHERE v.id = $2 AND v.status = 'processed' AND v.is_public = true AND v.user_id <> $1
+	  AND ($3::int IS NULL OR EXISTS(
+	      SELECT 1 FROM rounds r
+	      WHERE r.id = $3 AND ` + openVotingRound + `
+	        AND (r.votes_per_user IS NULL
+	             OR (SELECT COUNT(*) FROM votes WHERE user_id = $1 AND round_id = r.id) < r.votes_per_user)))
+	ON CONFLICT DO NOTHING
+	RETURNING id`
+
+// VoteRequest es un voto a registrar
+type VoteRequest struct {
+	UserID         int
+	VideoID        uuid.UUID
+	Origin         VoteOrigin
+	IdempotencyKey string // header Idempotency-Key; puede venir vacío
+}
+
+// VoteResult es el voto registrado
+type VoteResult struct {
+	VoteID   int64
+	RoundID  *int
+	Replayed bool // el voto ya existía con la misma Idempotency-Key
+}
+
+// VoteService registra y retira los votos
+type VoteService struct {
+	db             *sql.DB
+	rankingService *RankingService
+	roundService   *RoundService
+	voteFraud      *VoteFraudService
+}
+
+func NewVoteService(db *sql.DB, rankingService *RankingService, roundService *RoundService, voteFraud *VoteFraudService) *VoteService {
+	return &VoteService{
+		db:             db,
+		rankingService: rankingService,
+		roundService:   roundService,
+		voteFraud:      voteFraud,
+	}
+}
+
+// Cast registra el voto en la ronda abierta en la que compite el video (o como voto libre si no
+// hay rondas de votación). Un reintento con la misma Idempotency-Key retorna el voto ya registrado
+// con Replayed en true.
+func (s *VoteService) Cast(ctx context.Context, req VoteRequest) (*VoteResult, error) {
+	if req.IdempotencyKey != "" {
+		if result, err := s.replay(ctx, req); result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	roundID, err := s.roundService.VotingRound(req.VideoID)
+	if err != nil {
+		// Un video inexistente o propio no compite en ninguna ronda: se informa ese motivo
+		if errors.Is(err, ErrVideoNotInRound) || errors.Is(err, ErrVotingClosed) {
+			if videoErr := s.checkVideo(ctx, req); videoErr != nil {
+				return nil, videoErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.voteFraud.CheckRateLimit(req.UserID, req.Origin); err != nil {
+		return nil, err
+	}
+
+	voteID, err := s.insert(ctx, req, roundID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.voteRejection(ctx, req, roundID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// El voto ya está en Postgres: si el caché falla, la próxima reconciliación lo corrige
+	if err := s.rankingService.RecordVote(ctx, req.VideoID, 1); err != nil {
+		log.Printf("Warning: failed to record vote for video %s in ranking cache: %v", req.VideoID, err)
+	}
+	return &VoteResult{VoteID: voteID, RoundID: roundID}, nil
+}
+
+// insert ejecuta insertVoteQuery. En las rondas el lock por usuario y ronda serializa sus votos
+// para que el conteo del cupo no quede desactualizado con peticiones concurrentes.
+func (s *VoteService) insert(ctx context.Context, req VoteRequest, roundID *int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if roundID != nil {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, req.UserID, *roundID); err != nil {
+			return 0, err
+		}
+	}
+
+	var voteID int64
+	err = tx.QueryRowContext(ctx, insertVoteQuery, req.UserID, req.VideoID, roundID,
+		req.Origin.IP, req.Origin.UserAgent, req.Origin.DeviceID, req.IdempotencyKey).Scan(&voteID)
+	if err != nil {
+		return 0, err
+	}
+	return voteID, tx.Commit()
+}
+
+// replay retorna el voto registrado con la Idempotency-Key de la petición, o nil si no hay
+func (s *VoteService) replay(ctx context.Context, req VoteRequest) (*VoteResult, error) {
+	var result VoteResult
+	var videoID uuid.UUID
+	var roundID sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `SELECT id, video_id, round_id FROM votes WHERE user_id = $1 AND idempotency_key = $2`,
+		req.UserID, req.IdempotencyKey).Scan(&result.VoteID, &videoID, &roundID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if videoID != req.VideoID {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if roundID.Valid {
+		id := int(roundID.Int64)
+		result.RoundID = &id
+	}
+	result.Replayed = true
+	return &result, nil
+}
+
+// voteRejection averigua por qué insertVoteQuery no registró el voto
+func (s *VoteService) voteRejection(ctx context.Context, req VoteRequest, roundID *int) (*VoteResult, error) {
+	// Otra petición con la misma Idempotency-Key pudo registrar el voto en paralelo
+	if req.IdempotencyKey != "" {
+		if result, err := s.replay(ctx, req); result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	var voted bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM votes WHERE user_id = $1 AND video_id = $2 AND round_id IS NOT DISTINCT FROM $3)`,
+		req.UserID, req.VideoID, roundID).Scan(&voted)
+	if err != nil {
+		return nil, err
+	}
+	if voted {
+		return nil, ErrAlreadyVoted
+	}
+
+	if err := s.checkVideo(ctx, req); err != nil {
+		return nil, err
+	}
+	if roundID != nil {
+		if err := s.roundService.CheckVoteBudget(req.UserID, *roundID); err != nil {
+			return nil, err
+		}
+	}
+	// La ronda cerró entre la consulta y la inserción
+	return nil, ErrVotingClosed
+}
+
+// checkVideo retorna ErrVideoNotVotable si el video no existe o no es público y procesado, y
+// ErrOwnVideo si es del usuario que vota
+func (s *VoteService) checkVideo(ctx context.Context, req VoteRequest) error {
+	var ownerID int
+	err := s.db.QueryRowContext(ctx, `SELECT user_id FROM videos WHERE id = $1 AND status = 'processed' AND is_public = true`,
+		req.VideoID).Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVideoNotVotable
+	}
+	if err != nil {
+		return err
+	}
+	if ownerID == req.UserID {
+		return ErrOwnVideo
+	}
+	return nil
+}
+
+// Retract retira el voto del usuario por el video en la ronda abierta (o su voto libre). Los
+// votos de rondas cerradas ya cuentan para los ganadores y no se pueden retirar.
+func (s *VoteService) Retract(ctx context.Context, userID int, videoID uuid.UUID) error {
+	roundID, err := s.roundService.VotingRound(videoID)
+	if errors.Is(err, ErrVideoNotInRound) {
+		return ErrVoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Los triggers descuentan el voto de videos.votes_count y de la ronda. Los votos anulados se
+	// conservan como evidencia y siguen impidiendo votar de nuevo por el video.
+	var voteID int64
+	err = s.db.QueryRowContext(ctx, `
+		DELETE FROM votes
+		WHERE user_id = $1 AND video_id = $2 AND round_id IS NOT DISTINCT FROM $3 AND voided_at IS NULL
+		RETURNING id`, userID, videoID, roundID).Scan(&voteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// El voto ya se borró de Postgres: si el caché falla, la próxima reconciliación lo corrige
+	if err := s.rankingService.RecordVote(ctx, videoID, -1); err != nil {
+		log.Printf("Warning: failed to remove retracted vote for video %s from ranking cache: %v", videoID, err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"back/internal/config"
+	"back/internal/database/models"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// concurrentVotes es la cantidad de peticiones simultáneas de cada prueba
+const concurrentVotes = 20
+
+// openTestDB abre la base de TEST_DATABASE_URL en un schema propio de la prueba con las
+// migraciones de db/ aplicadas. La prueba se omite si la variable no está definida.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping Postgres test")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	// lib/pq envía los parámetros desconocidos del DSN como parámetros de la sesión
+	searchPath := "search_path=" + schema + ",public"
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " " + searchPath
+	case strings.Contains(dsn, "?"):
+		dsn += "&" + searchPath
+	default:
+		dsn += "?" + searchPath
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	db.SetMaxOpenConns(concurrentVotes + 5)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "*.up.sql"))
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("find migrations: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, migration := range migrations {
+		script, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("read %s: %v", migration, err)
+		}
+		if _, err := db.Exec(string(script)); err != nil {
+			t.Fatalf("apply %s: %v", filepath.Base(migration), err)
+		}
+	}
+	return db
+}
+
+func newTestVoteService(db *sql.DB) *VoteService {
+	cfg := &config.Config{}
+	rankingService := NewRankingService(db, cfg, nil)
+	return NewVoteService(db, rankingService, NewRoundService(db), NewVoteFraudService(db, cfg, rankingService))
+}
+
+func createTestUser(t *testing.T, db *sql.DB, name string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO users (first_name, last_name, email, password_hash, city, country)
+		VALUES ($1, 'Test', $1 || '@example.com', 'x', 'Bogotá', 'Colombia')
+		RETURNING id`, name).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	return id
+}
+
+func createTestVideo(t *testing.T, db *sql.DB, ownerID int) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	err := db.QueryRow(`
+		INSERT INTO videos (user_id, title, original_filename, status, is_public, processed_at)
+		VALUES ($1, 'Video de prueba', 'video.mp4', 'processed', true, NOW())
+		RETURNING id`, ownerID).Scan(&id)
+	if err != nil {
+		t.Fatalf("create video: %v", err)
+	}
+	return id
+}
+
+// castConcurrently lanza las peticiones a la vez y retorna el error de cada una
+func castConcurrently(s *VoteService, reqs []VoteRequest) []error {
+	errs := make([]error, len(reqs))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req VoteRequest) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = s.Cast(context.Background(), req)
+		}(i, req)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func countVotes(t *testing.T, db *sql.DB, userID int) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM votes WHERE user_id = $1`, userID).Scan(&n); err != nil {
+		t.Fatalf("count votes: %v", err)
+	}
+	return n
+}
+
+func TestCastConcurrentDuplicateVotes(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestVoteService(db)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+	video := createTestVideo(t, db, owner)
+
+	reqs := make([]VoteRequest, concurrentVotes)
+	for i := range reqs {
+		reqs[i] = VoteRequest{UserID: voter, VideoID: video}
+	}
+
+	var succeeded int
+	for _, err := range castConcurrently(s, reqs) {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrAlreadyVoted):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", succeeded)
+	}
+	if n := countVotes(t, db, voter); n != 1 {
+		t.Errorf("votes in database = %d, want 1", n)
+	}
+}
+
+func TestCastIdempotencyKey(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestVoteService(db)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+	video := createTestVideo(t, db, owner)
+	other := createTestVideo(t, db, owner)
+	ctx := context.Background()
+
+	first, err := s.Cast(ctx, VoteRequest{UserID: voter, VideoID: video, IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("first cast: %v", err)
+	}
+	if first.Replayed {
+		t.Error("first cast replayed, want a new vote")
+	}
+
+	again, err := s.Cast(ctx, VoteRequest{UserID: voter, VideoID: video, IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("repeated cast: %v", err)
+	}
+	if !again.Replayed || again.VoteID != first.VoteID {
+		t.Errorf("repeated cast = {VoteID: %d, Replayed: %t}, want {VoteID: %d, Replayed: true}",
+			again.VoteID, again.Replayed, first.VoteID)
+	}
+
+	if _, err := s.Cast(ctx, VoteRequest{UserID: voter, VideoID: other, IdempotencyKey: "key-1"}); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("cast on another video with the same key: err = %v, want %v", err, ErrIdempotencyKeyReused)
+	}
+	if n := countVotes(t, db, voter); n != 1 {
+		t.Errorf("votes in database = %d, want 1", n)
+	}
+}
+
+func TestCastConcurrentVoteBudget(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestVoteService(db)
+	owner := createTestUser(t, db, "owner")
+	voter := createTestUser(t, db, "voter")
+
+	competition, err := s.roundService.CreateCompetition(models.CompetitionCreate{Name: "Copa de prueba"})
+	if err != nil {
+		t.Fatalf("create competition: %v", err)
+	}
+	budget := 2
+	if _, err := s.roundService.CreateRound(competition.ID, models.RoundCreate{
+		Name:         "Regional",
+		Phase:        "regional",
+		StartsAt:     time.Now().Add(-24 * time.Hour),
+		EndsAt:       time.Now().Add(24 * time.Hour),
+		VotesPerUser: &budget,
+	}); err != nil {
+		t.Fatalf("create round: %v", err)
+	}
+
+	reqs := make([]VoteRequest, concurrentVotes)
+	for i := range reqs {
+		reqs[i] = VoteRequest{UserID: voter, VideoID: createTestVideo(t, db, owner)}
+	}
+
+	var succeeded int
+	for _, err := range castConcurrently(s, reqs) {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrVoteBudgetExhausted):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != budget {
+		t.Errorf("succeeded = %d, want %d", succeeded, budget)
+	}
+	if n := countVotes(t, db, voter); n > budget {
+		t.Errorf("votes in database = %d, over the budget of %d", n, budget)
+	}
+}
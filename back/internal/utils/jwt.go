@@ -11,22 +11,25 @@ import (
 type Claims struct {
 	UserID int    `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT genera un token JWT para un usuario
-func GenerateJWT(userID int, email, secretKey string, expiration time.Duration) (string, error) {
+// GenerateJWT genera un access token JWT para un usuario. El jti es el de la sesión: al revocarla,
+// AuthMiddleware rechaza sus tokens.
+func GenerateJWT(userID int, email, role, sessionID, secretKey string, expiration time.Duration) (string, error) {
 	// Crear claims
 	claims := &Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "anb-rising-stars",
 			Subject:   fmt.Sprintf("user:%d", userID),
-			ID:        fmt.Sprintf("%d_%d", userID, time.Now().Unix()),
+			ID:        sessionID,
 		},
 	}
 
@@ -86,7 +89,7 @@ func RefreshJWT(tokenString, secretKey string, expiration time.Duration) (string
 	}
 
 	// Generar nuevo token
-	return GenerateJWT(claims.UserID, claims.Email, secretKey, expiration)
+	return GenerateJWT(claims.UserID, claims.Email, claims.Role, claims.ID, secretKey, expiration)
 }
 
 // ExtractTokenFromHeader extrae el token del header Authorization
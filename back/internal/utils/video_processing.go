@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -18,20 +20,75 @@ type VideoInfo struct {
 	Height   int     `json:"height"`
 }
 
-// RunCmd helper
-func runCmd(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+// cmdKillGrace es cuánto espera runCmdContext tras el SIGTERM antes de matar el proceso
+const cmdKillGrace = 5 * time.Second
+
+// ProgressFunc recibe cuántos segundos del video lleva escritos ffmpeg
+type ProgressFunc func(processed float64)
+
+type progressKey struct{}
+
+// WithProgress asocia fn al contexto: los comandos ffmpeg que se ejecuten con él reportan su
+// avance (salida de -progress) a medida que procesan el video
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// runCmdContext ejecuta el comando y lo detiene si se cancela ctx: primero SIGTERM (ffmpeg
+// cierra sus archivos y termina) y, si sigue vivo después de cmdKillGrace, SIGKILL
+func runCmdContext(ctx context.Context, name string, args ...string) error {
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	if name == "ffmpeg" && progress != nil {
+		args = append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = cmdKillGrace
+	if name == "ffmpeg" && progress != nil {
+		cmd.Stdout = &progressWriter{fn: progress}
+	}
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s interrupted: %w", name, ctxErr)
+		}
 		return fmt.Errorf("%v: %s", err, stderr.String())
 	}
 	return nil
 }
 
+// progressWriter interpreta la salida de -progress de ffmpeg: bloques de líneas clave=valor donde
+// out_time_us es la posición (en microsegundos) hasta la que se escribió la salida
+type progressWriter struct {
+	fn  ProgressFunc
+	buf []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+
+		// Al inicio ffmpeg reporta N/A
+		if value, ok := strings.CutPrefix(line, "out_time_us="); ok {
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				w.fn(float64(us) / 1e6)
+			}
+		}
+	}
+	return len(p), nil
+}
+
 // GetVideoDuration usa ffprobe para obtener la duración en segundos
-func GetVideoDuration(path string) (float64, error) {
-	out, err := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration",
+func GetVideoDuration(ctx context.Context, path string) (float64, error) {
+	out, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration",
 		"-of", "default=noprint_wrappers=1:nokey=1", path).Output()
 	if err != nil {
 		return 0, fmt.Errorf("ffprobe error: %v", err)
@@ -48,8 +105,8 @@ func GetVideoDuration(path string) (float64, error) {
 }
 
 // GetVideoResolution usa ffprobe para ancho/alto
-func GetVideoResolution(path string) (int, int, error) {
-	out, err := exec.Command("ffprobe", "-v", "error", "-select_streams", "v:0",
+func GetVideoResolution(ctx context.Context, path string) (int, int, error) {
+	out, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-select_streams", "v:0",
 		"-show_entries", "stream=width,height", "-of", "json", path).Output()
 	if err != nil {
 		return 0, 0, fmt.Errorf("ffprobe error: %v", err)
@@ -68,54 +125,20 @@ func GetVideoResolution(path string) (int, int, error) {
 }
 
 // TrimVideo recorta a durationSeconds (desde 0)
-func TrimVideo(src, dst string, durationSeconds int) error {
+func TrimVideo(ctx context.Context, src, dst string, durationSeconds int) error {
 	// -y sobreescribe
 	args := []string{"-i", src, "-ss", "0", "-t", strconv.Itoa(durationSeconds), "-c", "copy", "-y", dst}
-	return runCmd("ffmpeg", args...)
+	return runCmdContext(ctx, "ffmpeg", args...)
 }
 
 // RemoveAudio remueve el audio track
-func RemoveAudio(src, dst string) error {
+func RemoveAudio(ctx context.Context, src, dst string) error {
 	args := []string{"-i", src, "-c", "copy", "-an", "-y", dst}
-	return runCmd("ffmpeg", args...)
-}
-
-// ConvertTo720p convierte video a 1280x720 manteniendo aspect ratio
-func ConvertTo720p(src, dst string) error {
-	args := []string{
-		"-i", src,
-		"-vf", "scale='min(1280,iw)':'min(720,ih)':force_original_aspect_ratio=decrease,pad=1280:720:(ow-iw)/2:(oh-ih)/2",
-		"-c:v", "libx264",
-		"-preset", "ultrafast", // Cambiado de "fast" a "ultrafast" para máxima velocidad
-		"-crf", "28", // Cambiado de "23" a "28" para reducir calidad pero ganar velocidad
-		"-threads", "0", // Usar todos los cores disponibles
-		"-tune", "fastdecode", // Optimizar para decodificación rápida
-		"-movflags", "+faststart", // Optimizar para streaming
-		"-an", // Sin audio
-		"-y", dst,
-	}
-	return runCmd("ffmpeg", args...)
-}
-
-// AddWatermark overlay watermarkImage at top-right with 10px padding
-func AddWatermark(src, dst, watermarkImage string) error {
-	args := []string{
-		"-i", src,
-		"-i", watermarkImage,
-		"-filter_complex", "overlay=main_w-overlay_w-10:10",
-		"-c:v", "libx264",
-		"-preset", "ultrafast", // Preset más rápido
-		"-crf", "28", // Calidad equilibrada para velocidad
-		"-threads", "0", // Usar todos los cores
-		"-movflags", "+faststart", // Optimizar para streaming
-		"-an", // Sin audio
-		"-y", dst,
-	}
-	return runCmd("ffmpeg", args...)
+	return runCmdContext(ctx, "ffmpeg", args...)
 }
 
 // AddOpeningClosing adds opening and closing clips (optional) concatenating them (they should be short mp4s)
-func AddOpeningClosing(src, dst, opening, closing string) error {
+func AddOpeningClosing(ctx context.Context, src, dst, opening, closing string) error {
 	// Create a txt file list for ffmpeg concat
 	tmpList := filepath.Join(os.TempDir(), fmt.Sprintf("concat_%d.txt", time.Now().UnixNano()))
 	f, err := os.Create(tmpList)
@@ -136,46 +159,167 @@ func AddOpeningClosing(src, dst, opening, closing string) error {
 	f.Sync()
 
 	// concat demuxer
-	if err := runCmd("ffmpeg", "-f", "concat", "-safe", "0", "-i", tmpList, "-c", "copy", "-y", dst); err != nil {
+	if err := runCmdContext(ctx, "ffmpeg", "-f", "concat", "-safe", "0", "-i", tmpList, "-c", "copy", "-y", dst); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		// As fallback, try re-encoding
-		return runCmd("ffmpeg", "-f", "concat", "-safe", "0", "-i", tmpList, "-c:v", "libx264", "-c:a", "aac", "-y", dst)
+		return runCmdContext(ctx, "ffmpeg", "-f", "concat", "-safe", "0", "-i", tmpList, "-c:v", "libx264", "-c:a", "aac", "-y", dst)
 	}
 	return nil
 }
 
-// OptimizedConvertAndWatermark combina conversión y watermark en un solo paso FFmpeg
-func OptimizedConvertAndWatermark(src, dst, watermarkImage string) error {
-	// Si no hay watermark, solo convertir
-	if !FileExists(watermarkImage) {
-		return ConvertTo720p(src, dst)
-	}
+// FileExists verifica si un archivo existe
+func FileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
 
-	// Combinar conversión y watermark en un solo paso para máxima eficiencia
-	args := []string{
-		"-i", src,
-		"-i", watermarkImage,
-		"-filter_complex",
-		"[0:v]scale='min(1280,iw)':'min(720,ih)':force_original_aspect_ratio=decrease,pad=1280:720:(ow-iw)/2:(oh-ih)/2[scaled];[scaled][1:v]overlay=main_w-overlay_w-10:10[out]",
+// EncodeOptions parametriza la recodificación H.264 de TranscodeVideo
+type EncodeOptions struct {
+	Preset    string // preset de libx264 (ej: "ultrafast")
+	CRF       int    // calidad constante de libx264
+	KeepAudio bool   // conservar el audio (AAC) si el video lo tiene
+}
+
+// TranscodeVideo recodifica src a MP4/H.264 aplicando filterGraph (sintaxis de -filter_complex,
+// con [0:v] como entrada y [out] como salida). overlays son entradas adicionales (ej: imágenes
+// de watermark) referenciadas como [1:v], [2:v], ...
+func TranscodeVideo(ctx context.Context, src, dst, filterGraph string, overlays []string, opts EncodeOptions) error {
+	args := []string{"-i", src}
+	for _, overlay := range overlays {
+		args = append(args, "-i", overlay)
+	}
+	args = append(args,
+		"-filter_complex", filterGraph,
 		"-map", "[out]",
 		"-c:v", "libx264",
-		"-preset", "ultrafast", // Preset más rápido
-		"-crf", "28", // Calidad equilibrada
+		"-preset", opts.Preset,
+		"-crf", strconv.Itoa(opts.CRF),
 		"-threads", "0", // Usar todos los cores disponibles
-		"-tune", "fastdecode", // Optimizar para decodificación rápida
 		"-movflags", "+faststart", // Optimizar para streaming
-		"-an", // Sin audio
+	)
+	if opts.KeepAudio {
+		// "?" evita fallar si el video no tiene audio
+		args = append(args, "-map", "0:a?", "-c:a", "aac")
+	} else {
+		args = append(args, "-an")
+	}
+	args = append(args, "-y", dst)
+	return runCmdContext(ctx, "ffmpeg", args...)
+}
+
+// ExtractThumbnail guarda un fotograma JPEG de src en el segundo at, escalado a width de ancho
+func ExtractThumbnail(ctx context.Context, src, dst string, at float64, width int) error {
+	args := []string{
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", src,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-q:v", "3",
 		"-y", dst,
 	}
-	return runCmd("ffmpeg", args...)
+	return runCmdContext(ctx, "ffmpeg", args...)
 }
 
-// FileExists verifica si un archivo existe
-func FileExists(path string) bool {
-	_, err := os.Stat(path)
-	return !os.IsNotExist(err)
+// HLSRendition es una variante de la escalera HLS: alto en px y bitrate de video en kbps
+type HLSRendition struct {
+	Name    string
+	Height  int
+	Bitrate int
+}
+
+// EncodeHLS genera en outDir un playlist maestro (master.m3u8) y, por cada variante, su playlist
+// en outDir/<nombre>/index.m3u8 con segmentos de segmentDuration segundos. Todas las variantes
+// se codifican en un solo ffmpeg con keyframes alineados para poder cambiar de calidad.
+func EncodeHLS(ctx context.Context, src, outDir string, renditions []HLSRendition, segmentDuration int, opts EncodeOptions) error {
+	if len(renditions) == 0 {
+		return fmt.Errorf("no HLS renditions configured")
+	}
+
+	// split reparte el video decodificado a un escalado por variante
+	outputs := make([]string, len(renditions))
+	for i := range renditions {
+		outputs[i] = fmt.Sprintf("[s%d]", i)
+	}
+	chain := []string{fmt.Sprintf("[0:v]split=%d%s", len(renditions), strings.Join(outputs, ""))}
+	for i, r := range renditions {
+		chain = append(chain, fmt.Sprintf("[s%d]scale=-2:%d[v%d]", i, r.Height, i))
+	}
+
+	args := []string{"-i", src, "-filter_complex", strings.Join(chain, ";")}
+	streamMap := make([]string, len(renditions))
+	for i, r := range renditions {
+		args = append(args,
+			"-map", fmt.Sprintf("[v%d]", i),
+			fmt.Sprintf("-b:v:%d", i), fmt.Sprintf("%dk", r.Bitrate),
+			fmt.Sprintf("-maxrate:v:%d", i), fmt.Sprintf("%dk", r.Bitrate*107/100),
+			fmt.Sprintf("-bufsize:v:%d", i), fmt.Sprintf("%dk", r.Bitrate*3/2),
+		)
+		streamMap[i] = fmt.Sprintf("v:%d", i)
+		if opts.KeepAudio {
+			args = append(args, "-map", "0:a:0")
+			streamMap[i] += fmt.Sprintf(",a:%d", i)
+		}
+		streamMap[i] += ",name:" + r.Name
+	}
+
+	args = append(args,
+		"-c:v", "libx264",
+		"-preset", opts.Preset,
+		"-threads", "0",
+		// Keyframe al inicio de cada segmento en todas las variantes
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", segmentDuration),
+		"-sc_threshold", "0",
+	)
+	if opts.KeepAudio {
+		args = append(args, "-c:a", "aac", "-b:a", "128k")
+	}
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(segmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outDir, "%v", "seg_%03d.ts"),
+		"-master_pl_name", "master.m3u8",
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-y", filepath.Join(outDir, "%v", "index.m3u8"),
+	)
+	return runCmdContext(ctx, "ffmpeg", args...)
+}
+
+// ExtractBestThumbnail elige el fotograma más representativo entre los primeros cuadros desde
+// el segundo at (filtro thumbnail de ffmpeg, que descarta fotogramas negros o de transición)
+func ExtractBestThumbnail(ctx context.Context, src, dst string, at float64, width int) error {
+	args := []string{
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", src,
+		"-vf", fmt.Sprintf("thumbnail=60,scale=%d:-2", width),
+		"-frames:v", "1",
+		"-q:v", "3",
+		"-y", dst,
+	}
+	return runCmdContext(ctx, "ffmpeg", args...)
 }
 
-// CopyFile copia un archivo de src a dst
-func CopyFile(src, dst string) error {
-	return runCmd("cp", src, dst)
+// CreateAnimatedPreview genera una animación sin audio de duration segundos desde at, escalada a
+// width de ancho. El formato se toma de la extensión de dst (.gif o .webp).
+func CreateAnimatedPreview(ctx context.Context, src, dst string, at, duration float64, width, fps int) error {
+	args := []string{
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-i", src,
+		"-an",
+	}
+	scale := fmt.Sprintf("fps=%d,scale=%d:-2:flags=lanczos", fps, width)
+	switch strings.ToLower(filepath.Ext(dst)) {
+	case ".gif":
+		// Paleta propia del clip para que el GIF no pierda color
+		args = append(args, "-filter_complex", scale+",split[a][b];[a]palettegen[p];[b][p]paletteuse")
+	case ".webp":
+		args = append(args, "-vf", scale, "-c:v", "libwebp", "-quality", "60")
+	default:
+		return fmt.Errorf("unsupported preview format %s", filepath.Ext(dst))
+	}
+	args = append(args, "-loop", "0", "-y", dst)
+	return runCmdContext(ctx, "ffmpeg", args...)
 }
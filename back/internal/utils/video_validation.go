@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Contenedores de video soportados por el pipeline de ffmpeg
+const (
+	ContainerMP4  = "mp4"
+	ContainerMOV  = "mov"
+	ContainerWebM = "webm"
+	ContainerMKV  = "mkv"
+)
+
+// VideoContainerFromFilename retorna el contenedor que corresponde a la extensión del archivo,
+// o "" si la extensión no está soportada
+func VideoContainerFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".mp4":
+		return ContainerMP4
+	case ".mov":
+		return ContainerMOV
+	case ".webm":
+		return ContainerWebM
+	case ".mkv":
+		return ContainerMKV
+	}
+	return ""
+}
+
+// ProbeResult resume la información que ffprobe reporta de un video
+type ProbeResult struct {
+	FormatName string  `json:"format_name"`
+	Duration   float64 `json:"duration"`
+	HasVideo   bool    `json:"has_video"`
+	VideoCodec string  `json:"video_codec,omitempty"`
+	Width      int     `json:"width,omitempty"`
+	Height     int     `json:"height,omitempty"`
+	HasAudio   bool    `json:"has_audio"`
+}
+
+// SniffVideoContainer identifica el contenedor a partir de los primeros bytes del archivo.
+// Retorna "" si no corresponde a ningún contenedor soportado.
+func SniffVideoContainer(header []byte) string {
+	// ISO BMFF (MP4/MOV): caja "ftyp" al inicio, seguida de la marca principal
+	if len(header) >= 12 && string(header[4:8]) == "ftyp" {
+		if string(header[8:12]) == "qt  " {
+			return ContainerMOV
+		}
+		return ContainerMP4
+	}
+
+	// EBML (Matroska/WebM): el DocType del encabezado distingue ambos
+	if len(header) >= 4 && bytes.Equal(header[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		if bytes.Contains(header, []byte("webm")) {
+			return ContainerWebM
+		}
+		return ContainerMKV
+	}
+
+	return ""
+}
+
+// ProbeVideo ejecuta ffprobe sobre source (ruta local o URL) con el límite de tiempo del contexto
+func ProbeVideo(ctx context.Context, source string) (*ProbeResult, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-show_entries", "format=format_name,duration:stream=codec_type,codec_name,width,height",
+		"-of", "json", source)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("ffprobe aborted: %w", ctxErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe error: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var data struct {
+		Format struct {
+			FormatName string `json:"format_name"`
+			Duration   string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &data); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+	if data.Format.FormatName == "" {
+		return nil, errors.New("ffprobe could not detect a container format")
+	}
+
+	result := &ProbeResult{FormatName: data.Format.FormatName}
+	if data.Format.Duration != "" {
+		result.Duration, _ = strconv.ParseFloat(data.Format.Duration, 64)
+	}
+
+	for _, stream := range data.Streams {
+		switch stream.CodecType {
+		case "video":
+			// Se toma el primer stream de video (ignora carátulas adjuntas posteriores)
+			if !result.HasVideo {
+				result.HasVideo = true
+				result.VideoCodec = stream.CodecName
+				result.Width = stream.Width
+				result.Height = stream.Height
+			}
+		case "audio":
+			result.HasAudio = true
+		}
+	}
+
+	return result, nil
+}
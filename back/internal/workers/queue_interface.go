@@ -2,6 +2,9 @@ package workers
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 )
 
 // QueueClient es la interfaz genérica para diferentes tipos de colas
@@ -17,7 +20,139 @@ type QueueClient interface {
 
 	// GetQueueDepth retorna la cantidad de mensajes pendientes en la cola
 	GetQueueDepth(ctx context.Context) (int64, error)
+
+	// ListDeadLetters lista hasta limit tareas de la dead-letter queue
+	ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+
+	// GetDeadLetter retorna una tarea de la dead-letter queue por su id
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+
+	// RedriveDeadLetter vuelve a encolar una tarea de la dead-letter queue con sus intentos
+	// reiniciados y la elimina de la dead-letter queue
+	RedriveDeadLetter(ctx context.Context, id string) error
 }
 
 // TaskHandler es la función que procesa una tarea
 type TaskHandler func(ctx context.Context, taskType string, payload []byte) error
+
+// ErrDeadLetterNotFound indica que la tarea no está en la dead-letter queue
+var ErrDeadLetterNotFound = errors.New("dead-lettered task not found")
+
+// DeadLetter es una tarea que agotó sus intentos o falló de forma permanente
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	TaskType  string    `json:"task_type"`
+	Payload   string    `json:"payload"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// Attempt describe la entrega actual de una tarea: Number empieza en 1 y Max es el total
+// de intentos permitidos antes de enviarla a la dead-letter queue
+type Attempt struct {
+	Number int
+	Max    int
+}
+
+// Final indica si es el último intento: si falla, la tarea no se vuelve a entregar
+func (a Attempt) Final() bool {
+	return a.Number >= a.Max
+}
+
+type attemptKey struct{}
+
+func withAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext retorna el intento que el cliente de cola asoció al contexto del handler.
+// Sin información se asume un único intento.
+func AttemptFromContext(ctx context.Context) Attempt {
+	if attempt, ok := ctx.Value(attemptKey{}).(Attempt); ok {
+		return attempt
+	}
+	return Attempt{Number: 1, Max: 1}
+}
+
+type shutdownKey struct{}
+
+// withShutdown asocia al contexto del handler la señal de apagado del worker, que se cierra
+// cuando el worker interrumpe los trabajos que no terminaron de drenar
+func withShutdown(ctx context.Context, shutdown <-chan struct{}) context.Context {
+	return context.WithValue(ctx, shutdownKey{}, shutdown)
+}
+
+// ShuttingDown indica si la tarea se interrumpió porque el worker se está apagando. A diferencia
+// de ctx.Err(), no se activa cuando vence WORKER_TASK_TIMEOUT: eso es un intento fallido.
+func ShuttingDown(ctx context.Context) bool {
+	shutdown, ok := ctx.Value(shutdownKey{}).(<-chan struct{})
+	if !ok {
+		return false
+	}
+	select {
+	case <-shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// permanentError marca un error que no se resuelve reintentando (payload inválido, video inexistente)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca err como permanente: la tarea va directo a la dead-letter queue sin reintentos
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica si err (o alguno de los errores que envuelve) es permanente
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// maxAttempts normaliza QUEUE_MAX_ATTEMPTS (al menos un intento)
+func maxAttempts(configured int) int {
+	if configured < 1 {
+		return 1
+	}
+	return configured
+}
+
+// taskTimeout normaliza WORKER_TASK_TIMEOUT (por defecto 30 minutos, como asynq)
+func taskTimeout(configured time.Duration) time.Duration {
+	if configured <= 0 {
+		return 30 * time.Minute
+	}
+	return configured
+}
+
+// interruptGrace es cuánto se espera, tras cancelar los trabajos que no terminaron de drenar, a
+// que sus handlers detengan ffmpeg y devuelvan el video a 'uploaded'
+const interruptGrace = 30 * time.Second
+
+// waitTimeout espera a wg hasta timeout; retorna false si se agotó el tiempo
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
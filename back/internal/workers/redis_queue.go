@@ -3,15 +3,21 @@ package workers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 
 	"back/internal/config"
 
 	"github.com/hibiken/asynq"
 )
 
-// RedisQueueClient implementa QueueClient usando Redis (Asynq)
+// redisQueueName es la cola de asynq donde se encolan las tareas
+const redisQueueName = "default"
+
+// RedisQueueClient implementa QueueClient usando Redis (Asynq).
+// Las tareas que agotan sus reintentos quedan archivadas: el archivo de asynq es la dead-letter queue.
 type RedisQueueClient struct {
 	client    *asynq.Client
 	server    *asynq.Server
@@ -38,47 +44,144 @@ func NewRedisQueueClient(cfg *config.Config) (*RedisQueueClient, error) {
 // Enqueue agrega una tarea a la cola Redis
 func (q *RedisQueueClient) Enqueue(ctx context.Context, taskType string, payload []byte) error {
 	task := asynq.NewTask(taskType, payload)
-	_, err := q.client.Enqueue(task)
+	_, err := q.client.EnqueueContext(ctx, task,
+		asynq.MaxRetry(maxAttempts(q.cfg.QueueMaxAttempts)-1),
+		asynq.Timeout(taskTimeout(q.cfg.WorkerTaskTimeout)))
 	if err != nil {
 		return fmt.Errorf("failed to enqueue task: %w", err)
 	}
 	return nil
 }
 
-// StartWorker inicia el worker de Redis
+// StartWorker inicia el worker de Redis. Al cancelarse ctx deja de tomar tareas y drena: asynq
+// espera WORKER_DRAIN_TIMEOUT a las tareas en curso y devuelve a la cola las que no terminaron.
 func (q *RedisQueueClient) StartWorker(ctx context.Context, handler TaskHandler) error {
 	redisOpt := asynq.RedisClientOpt{
 		Addr: q.cfg.RedisURL,
 	}
 
+	// Contexto base de las tareas: se cancela cuando asynq ya las devolvió a la cola, para
+	// detener ffmpeg y que el handler libere el video
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
 	q.server = asynq.NewServer(redisOpt, asynq.Config{
-		Concurrency: q.cfg.WorkerConcurrency,
+		Concurrency:     q.cfg.WorkerConcurrency,
+		ShutdownTimeout: q.cfg.WorkerDrainTimeout,
+		BaseContext:     func() context.Context { return jobCtx },
 	})
 
 	mux := asynq.NewServeMux()
 
 	// Wrapper para convertir TaskHandler a asynq.Handler
+	var inFlight sync.WaitGroup
 	mux.HandleFunc(TypeVideoProcessing, func(ctx context.Context, t *asynq.Task) error {
-		return handler(ctx, t.Type(), t.Payload())
+		inFlight.Add(1)
+		defer inFlight.Done()
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		ctx = withAttempt(ctx, Attempt{Number: retried + 1, Max: maxRetry + 1})
+		// asynq también cancela ctx al vencer el Timeout de la tarea; solo jobCtx indica el apagado
+		ctx = withShutdown(ctx, jobCtx.Done())
+
+		err := handler(ctx, t.Type(), t.Payload())
+		if IsPermanent(err) {
+			// SkipRetry archiva la tarea sin agotar los reintentos
+			return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+		}
+		return err
 	})
 
 	log.Println("Starting Redis worker with Asynq...")
-	if err := q.server.Run(mux); err != nil {
+	if err := q.server.Start(mux); err != nil {
 		return fmt.Errorf("asynq server error: %w", err)
 	}
 
+	<-ctx.Done()
+	log.Printf("Stopping Redis worker, draining in-flight tasks (up to %s)...", q.cfg.WorkerDrainTimeout)
+	q.server.Stop()
+	q.server.Shutdown()
+
+	// Las tareas que siguen corriendo ya volvieron a la cola sin contar como intento
+	cancelJobs()
+	if !waitTimeout(&inFlight, interruptGrace) {
+		log.Println("Warning: some handlers did not stop before exiting")
+	}
 	return nil
 }
 
 // GetQueueDepth retorna la cantidad de mensajes pendientes
 func (q *RedisQueueClient) GetQueueDepth(ctx context.Context) (int64, error) {
-	stats, err := q.inspector.GetQueueInfo("default")
+	stats, err := q.inspector.GetQueueInfo(redisQueueName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get queue stats: %w", err)
 	}
 	return int64(stats.Pending), nil
 }
 
+// ListDeadLetters lista las tareas archivadas
+func (q *RedisQueueClient) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	tasks, err := q.inspector.ListArchivedTasks(redisQueueName, asynq.PageSize(limit))
+	if err != nil {
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return []DeadLetter{}, nil
+		}
+		return nil, fmt.Errorf("failed to list archived tasks: %w", err)
+	}
+
+	deadLetters := make([]DeadLetter, 0, len(tasks))
+	for _, task := range tasks {
+		deadLetters = append(deadLetters, archivedToDeadLetter(task))
+	}
+	return deadLetters, nil
+}
+
+// GetDeadLetter retorna una tarea archivada
+func (q *RedisQueueClient) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	task, err := q.inspector.GetTaskInfo(redisQueueName, id)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.State != asynq.TaskStateArchived {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	deadLetter := archivedToDeadLetter(task)
+	return &deadLetter, nil
+}
+
+// RedriveDeadLetter encola una copia nueva de la tarea archivada (con los reintentos en cero) y
+// elimina la archivada
+func (q *RedisQueueClient) RedriveDeadLetter(ctx context.Context, id string) error {
+	deadLetter, err := q.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := q.Enqueue(ctx, deadLetter.TaskType, []byte(deadLetter.Payload)); err != nil {
+		return err
+	}
+	if err := q.inspector.DeleteTask(redisQueueName, id); err != nil {
+		return fmt.Errorf("task re-enqueued but failed to delete archived task %s: %w", id, err)
+	}
+	return nil
+}
+
+func archivedToDeadLetter(task *asynq.TaskInfo) DeadLetter {
+	return DeadLetter{
+		ID:        task.ID,
+		TaskType:  task.Type,
+		Payload:   string(task.Payload),
+		Attempts:  task.Retried + 1,
+		LastError: task.LastErr,
+		FailedAt:  task.LastFailedAt,
+	}
+}
+
 // Close cierra las conexiones
 func (q *RedisQueueClient) Close() error {
 	if q.client != nil {
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"back/internal/config"
@@ -21,6 +23,9 @@ type SQSQueueClient struct {
 	queueURL string
 	cfg      *config.Config
 	running  bool
+
+	visibilityTimeout time.Duration
+	heartbeatInterval time.Duration
 }
 
 // NewSQSQueueClient crea un nuevo cliente de cola SQS
@@ -38,10 +43,23 @@ func NewSQSQueueClient(cfg *config.Config) (*SQSQueueClient, error) {
 
 	client := sqs.NewFromConfig(awsCfg)
 
+	// SQS acepta visibilidad de hasta 12 horas
+	visibility := cfg.SQSVisibilityTimeout
+	if visibility < time.Minute || visibility > 12*time.Hour {
+		return nil, fmt.Errorf("SQS_VISIBILITY_TIMEOUT must be between 1m and 12h")
+	}
+	// El latido debe llegar antes de que expire la visibilidad, con margen para la llamada a SQS
+	heartbeat := cfg.SQSHeartbeatInterval
+	if heartbeat <= 0 || heartbeat > visibility/2 {
+		return nil, fmt.Errorf("SQS_HEARTBEAT_INTERVAL must be positive and at most half of SQS_VISIBILITY_TIMEOUT")
+	}
+
 	return &SQSQueueClient{
-		client:   client,
-		queueURL: cfg.SQSQueue,
-		cfg:      cfg,
+		client:            client,
+		queueURL:          cfg.SQSQueue,
+		cfg:               cfg,
+		visibilityTimeout: visibility,
+		heartbeatInterval: heartbeat,
 	}, nil
 }
 
@@ -68,61 +86,121 @@ func (q *SQSQueueClient) Enqueue(ctx context.Context, taskType string, payload [
 	return nil
 }
 
-// StartWorker inicia el worker de SQS con long polling
+// StartWorker inicia el worker de SQS con long polling. Al cancelarse ctx deja de recibir
+// mensajes y drena: los videos en curso tienen WORKER_DRAIN_TIMEOUT para terminar; después se
+// interrumpen y sus mensajes se devuelven a la cola.
 func (q *SQSQueueClient) StartWorker(ctx context.Context, handler TaskHandler) error {
 	q.running = true
 	log.Printf("Starting SQS worker with concurrency: %d", q.cfg.WorkerConcurrency)
 
-	// Canal para manejar mensajes
-	messageChan := make(chan *types.Message, q.cfg.WorkerConcurrency)
+	// Los trabajos no usan ctx: deben seguir corriendo mientras el worker drena
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
 
-	// Iniciar workers concurrentes
-	for i := 0; i < q.cfg.WorkerConcurrency; i++ {
-		go q.processMessages(ctx, messageChan, handler)
-	}
+	// Cada mensaje recibido ocupa un hueco de WorkerConcurrency desde que se recibe: solo se piden
+	// mensajes con huecos libres, así ninguno espera sin latido mientras su visibilidad corre
+	slots := make(chan struct{}, q.cfg.WorkerConcurrency)
+	var inFlight sync.WaitGroup
 
 	// Loop principal de polling
-	for q.running {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping SQS worker...")
-			q.running = false
-			close(messageChan)
-			return ctx.Err()
-		default:
-			// Long polling de SQS
-			result, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-				QueueUrl:              aws.String(q.queueURL),
-				MaxNumberOfMessages:   int32(q.cfg.WorkerConcurrency),
-				WaitTimeSeconds:       20, // Long polling
-				VisibilityTimeout:     300, // 5 minutos para procesar
-				MessageAttributeNames: []string{"All"},
-			})
+	for q.running && ctx.Err() == nil {
+		free := acquireSlots(ctx, slots, sqsMaxBatch)
+		if free == 0 {
+			break
+		}
 
-			if err != nil {
-				log.Printf("Error receiving messages from SQS: %v", err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
+		// Long polling de SQS
+		result, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(q.queueURL),
+			MaxNumberOfMessages:   int32(free),
+			WaitTimeSeconds:       20, // Long polling
+			VisibilityTimeout:     int32(q.visibilityTimeout.Seconds()),
+			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+				types.MessageSystemAttributeNameApproximateReceiveCount,
+			},
+		})
 
-			// Enviar mensajes al canal para procesamiento
-			for _, msg := range result.Messages {
-				messageChan <- &msg
+		var messages []types.Message
+		if err == nil {
+			messages = result.Messages
+		}
+		for i := len(messages); i < free; i++ {
+			<-slots
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("Error receiving messages from SQS: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
 			}
+			continue
 		}
+
+		// Cada mensaje ya tiene su hueco: se procesa de inmediato o, si el worker empezó a
+		// apagarse, se devuelve a la cola
+		for i := range messages {
+			msg := &messages[i]
+			inFlight.Add(1)
+			go func() {
+				defer inFlight.Done()
+				defer func() { <-slots }()
+				if ctx.Err() != nil {
+					q.releaseMessage(context.WithoutCancel(ctx), msg)
+					return
+				}
+				if err := q.processMessage(jobCtx, msg, handler); err != nil {
+					log.Printf("Error processing message: %v", err)
+				}
+			}()
+		}
+	}
+
+	if ctx.Err() == nil {
+		inFlight.Wait()
+		return nil
 	}
 
-	close(messageChan)
+	log.Printf("Stopping SQS worker, draining in-flight messages (up to %s)...", q.cfg.WorkerDrainTimeout)
+	if waitTimeout(&inFlight, q.cfg.WorkerDrainTimeout) {
+		log.Println("SQS worker drained")
+		return nil
+	}
+
+	log.Println("Drain timeout reached, interrupting in-flight messages...")
+	cancelJobs()
+	if !waitTimeout(&inFlight, interruptGrace) {
+		log.Println("Warning: some handlers did not stop; their messages will reappear when visibility expires")
+	}
 	return nil
 }
 
-// processMessages procesa mensajes del canal
-func (q *SQSQueueClient) processMessages(ctx context.Context, messageChan <-chan *types.Message, handler TaskHandler) {
-	for msg := range messageChan {
-		if err := q.processMessage(ctx, msg, handler); err != nil {
-			log.Printf("Error processing message: %v", err)
+// sqsMaxBatch es el máximo de mensajes que SQS entrega por ReceiveMessage
+const sqsMaxBatch = 10
+
+// acquireSlots espera un hueco libre y toma los demás que estén libres, hasta limit. Retorna 0 si
+// ctx se cancela mientras espera.
+func acquireSlots(ctx context.Context, slots chan struct{}, limit int) int {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+
+	free := 1
+	for free < limit {
+		select {
+		case slots <- struct{}{}:
+			free++
+		default:
+			return free
 		}
 	}
+	return free
 }
 
 // processMessage procesa un mensaje individual
@@ -133,13 +211,34 @@ func (q *SQSQueueClient) processMessage(ctx context.Context, msg *types.Message,
 		taskType = *attr.StringValue
 	}
 
-	// Procesar mensaje
-	log.Printf("Processing SQS message: %s", taskType)
-	err := handler(ctx, taskType, []byte(*msg.Body))
+	attempt := q.messageAttempt(msg)
+
+	// Procesar mensaje, extendiendo su visibilidad mientras el handler siga corriendo. Al vencer
+	// WORKER_TASK_TIMEOUT se cancela solo la tarea: ctx sigue indicando el apagado del worker
+	log.Printf("Processing SQS message: %s (attempt %d/%d)", taskType, attempt.Number, attempt.Max)
+	stopHeartbeat := q.startHeartbeat(ctx, msg)
+	taskCtx, cancelTask := context.WithTimeout(ctx, taskTimeout(q.cfg.WorkerTaskTimeout))
+	err := handler(withShutdown(withAttempt(taskCtx, attempt), ctx.Done()), taskType, []byte(*msg.Body))
+	cancelTask()
+	stopHeartbeat()
+
+	// Las operaciones sobre el mensaje deben completarse aunque el trabajo se haya interrumpido
+	interrupted := ctx.Err() != nil
+	ctx = context.WithoutCancel(ctx)
 
 	if err != nil {
 		log.Printf("Handler error for message %s: %v", *msg.MessageId, err)
-		// No eliminamos el mensaje si hubo error, SQS lo volverá a entregar
+		if interrupted {
+			// Apagado del worker: el mensaje vuelve a la cola de inmediato para otro worker
+			q.releaseMessage(ctx, msg)
+			return err
+		}
+		if IsPermanent(err) || attempt.Final() {
+			return q.deadLetter(ctx, msg, taskType, attempt, err)
+		}
+
+		// No eliminamos el mensaje: SQS lo volverá a entregar cuando termine la espera
+		q.delayRetry(ctx, msg, attempt)
 		return err
 	}
 
@@ -158,6 +257,298 @@ func (q *SQSQueueClient) processMessage(ctx context.Context, msg *types.Message,
 	return nil
 }
 
+// priorAttemptsAttribute lleva, en las copias que se encolan al apagarse el worker, los intentos
+// fallidos del mensaje original: el ApproximateReceiveCount de la copia empieza de nuevo
+const priorAttemptsAttribute = "PriorAttempts"
+
+// messageAttempt retorna el intento de la entrega actual: los intentos heredados más
+// ApproximateReceiveCount, que cuenta las entregas del mensaje incluida la actual
+func (q *SQSQueueClient) messageAttempt(msg *types.Message) Attempt {
+	attempt := Attempt{Number: 1, Max: maxAttempts(q.cfg.QueueMaxAttempts)}
+	if count, err := strconv.Atoi(msg.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil && count > 0 {
+		attempt.Number = count
+	}
+	if attr, ok := msg.MessageAttributes[priorAttemptsAttribute]; ok && attr.StringValue != nil {
+		if prior, err := strconv.Atoi(*attr.StringValue); err == nil && prior > 0 {
+			attempt.Number += prior
+		}
+	}
+	return attempt
+}
+
+// releaseMessage devuelve el mensaje a la cola de inmediato para que otro worker lo tome, sin
+// gastar un intento. SQS cuenta en ApproximateReceiveCount incluso las entregas liberadas con
+// visibilidad 0, así que se encola una copia con los intentos fallidos previos y se elimina el
+// original. Si la copia no se puede encolar, se libera el original y la entrega cuenta como intento.
+func (q *SQSQueueClient) releaseMessage(ctx context.Context, msg *types.Message) {
+	attributes := map[string]types.MessageAttributeValue{}
+	for name, value := range msg.MessageAttributes {
+		attributes[name] = value
+	}
+	attributes[priorAttemptsAttribute] = types.MessageAttributeValue{
+		DataType:    aws.String("Number"),
+		StringValue: aws.String(strconv.Itoa(q.messageAttempt(msg).Number - 1)),
+	}
+
+	if _, err := q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(q.queueURL),
+		MessageBody:       msg.Body,
+		MessageAttributes: attributes,
+	}); err != nil {
+		log.Printf("Warning: failed to re-enqueue message %s, releasing it instead: %v", *msg.MessageId, err)
+		q.makeVisible(ctx, msg)
+		return
+	}
+
+	if _, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(q.queueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	}); err != nil {
+		// La copia ya está en la cola: el original se entregará otra vez cuando expire su visibilidad
+		log.Printf("Warning: message %s re-enqueued but failed to delete the original: %v", *msg.MessageId, err)
+		return
+	}
+	log.Printf("Message %s returned to the queue", *msg.MessageId)
+}
+
+// makeVisible vuelve visible el mensaje de inmediato (visibilidad 0). Si falla, SQS lo
+// reentregará cuando expire su visibilidad.
+func (q *SQSQueueClient) makeVisible(ctx context.Context, msg *types.Message) {
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     msg.ReceiptHandle,
+		VisibilityTimeout: 0,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to release message %s: %v", *msg.MessageId, err)
+	}
+}
+
+// startHeartbeat extiende la visibilidad del mensaje cada heartbeatInterval para que SQS no lo
+// entregue a otro worker durante transcodificaciones largas. La función retornada detiene el
+// latido y espera a que termine, antes de eliminar o liberar el mensaje.
+func (q *SQSQueueClient) startHeartbeat(ctx context.Context, msg *types.Message) func() {
+	hbCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(q.heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-hbCtx.Done():
+				return
+			case <-ticker.C:
+				_, err := q.client.ChangeMessageVisibility(hbCtx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(q.queueURL),
+					ReceiptHandle:     msg.ReceiptHandle,
+					VisibilityTimeout: int32(q.visibilityTimeout.Seconds()),
+				})
+				if err != nil && hbCtx.Err() == nil {
+					// Se reintenta en el siguiente latido; si la visibilidad expira, SQS lo reentregará
+					log.Printf("Warning: failed to extend visibility of message %s: %v", *msg.MessageId, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// delayRetry espera más entre cada intento (30s, 60s, 120s... hasta 15 minutos)
+func (q *SQSQueueClient) delayRetry(ctx context.Context, msg *types.Message, attempt Attempt) {
+	delay := min(30*time.Second<<(attempt.Number-1), 15*time.Minute)
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     msg.ReceiptHandle,
+		VisibilityTimeout: int32(delay.Seconds()),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to delay retry of message %s: %v", *msg.MessageId, err)
+	}
+}
+
+// deadLetter mueve el mensaje a la dead-letter queue con el motivo del fallo y lo elimina de la
+// cola principal. Sin SQS_DLQ_URL el mensaje se descarta (el fallo queda registrado en task_results).
+func (q *SQSQueueClient) deadLetter(ctx context.Context, msg *types.Message, taskType string, attempt Attempt, cause error) error {
+	if q.cfg.SQSDLQURL != "" {
+		reason := cause.Error()
+		if len(reason) > 1000 {
+			reason = reason[:1000]
+		}
+
+		_, err := q.client.SendMessage(ctx, &sqs.SendMessageInput{
+			QueueUrl:    aws.String(q.cfg.SQSDLQURL),
+			MessageBody: msg.Body,
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"TaskType":      stringAttribute(taskType),
+				"FailureReason": stringAttribute(reason),
+				"Attempts":      {DataType: aws.String("Number"), StringValue: aws.String(strconv.Itoa(attempt.Number))},
+				"FailedAt":      stringAttribute(time.Now().UTC().Format(time.RFC3339)),
+			},
+		})
+		if err != nil {
+			// El mensaje se queda en la cola principal y se reintentará el envío en la próxima entrega
+			return fmt.Errorf("failed to move message %s to dead-letter queue: %w", *msg.MessageId, err)
+		}
+		log.Printf("Message %s moved to dead-letter queue after %d attempt(s): %v", *msg.MessageId, attempt.Number, cause)
+	} else {
+		log.Printf("Warning: SQS_DLQ_URL not configured, dropping message %s after %d attempt(s): %v", *msg.MessageId, attempt.Number, cause)
+	}
+
+	_, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(q.queueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete dead-lettered message %s: %w", *msg.MessageId, err)
+	}
+	return cause
+}
+
+func stringAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+}
+
+// ListDeadLetters lee mensajes de la dead-letter queue sin consumirlos. SQS no permite listar una
+// cola, así que se reciben con visibilidad 0 hasta completar limit o dejar de ver mensajes nuevos.
+func (q *SQSQueueClient) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	if q.cfg.SQSDLQURL == "" {
+		return nil, fmt.Errorf("SQS_DLQ_URL is not configured")
+	}
+
+	seen := make(map[string]bool)
+	deadLetters := []DeadLetter{}
+	for len(deadLetters) < limit {
+		result, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(q.cfg.SQSDLQURL),
+			MaxNumberOfMessages:   int32(min(limit-len(deadLetters), 10)),
+			WaitTimeSeconds:       1,
+			VisibilityTimeout:     0,
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+
+		added := 0
+		for _, msg := range result.Messages {
+			if seen[*msg.MessageId] {
+				continue
+			}
+			seen[*msg.MessageId] = true
+			deadLetters = append(deadLetters, sqsToDeadLetter(msg))
+			added++
+		}
+		if added == 0 {
+			break
+		}
+	}
+
+	return deadLetters, nil
+}
+
+// GetDeadLetter busca un mensaje de la dead-letter queue por su MessageId
+func (q *SQSQueueClient) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	deadLetters, err := q.ListDeadLetters(ctx, sqsDeadLetterScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, deadLetter := range deadLetters {
+		if deadLetter.ID == id {
+			return &deadLetter, nil
+		}
+	}
+	return nil, ErrDeadLetterNotFound
+}
+
+// sqsDeadLetterScanLimit es la cantidad máxima de mensajes que se revisan al buscar uno por id
+const sqsDeadLetterScanLimit = 1000
+
+// RedriveDeadLetter reenvía el mensaje a la cola principal (SQS reinicia su contador de entregas)
+// y lo elimina de la dead-letter queue
+func (q *SQSQueueClient) RedriveDeadLetter(ctx context.Context, id string) error {
+	if q.cfg.SQSDLQURL == "" {
+		return fmt.Errorf("SQS_DLQ_URL is not configured")
+	}
+
+	// Se reserva cada mensaje recibido; los que no coinciden se liberan de inmediato
+	for scanned := 0; scanned < sqsDeadLetterScanLimit; {
+		result, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(q.cfg.SQSDLQURL),
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       1,
+			VisibilityTimeout:     30,
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if len(result.Messages) == 0 {
+			break
+		}
+		scanned += len(result.Messages)
+
+		var target *types.Message
+		for i := range result.Messages {
+			msg := &result.Messages[i]
+			if *msg.MessageId == id {
+				target = msg
+				continue
+			}
+			_, _ = q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String(q.cfg.SQSDLQURL),
+				ReceiptHandle:     msg.ReceiptHandle,
+				VisibilityTimeout: 0,
+			})
+		}
+		if target == nil {
+			continue
+		}
+
+		deadLetter := sqsToDeadLetter(*target)
+		if err := q.Enqueue(ctx, deadLetter.TaskType, []byte(deadLetter.Payload)); err != nil {
+			return err
+		}
+		if _, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(q.cfg.SQSDLQURL),
+			ReceiptHandle: target.ReceiptHandle,
+		}); err != nil {
+			return fmt.Errorf("task re-enqueued but failed to delete dead-lettered message %s: %w", id, err)
+		}
+		return nil
+	}
+
+	return ErrDeadLetterNotFound
+}
+
+func sqsToDeadLetter(msg types.Message) DeadLetter {
+	attr := func(name string) string {
+		if value, ok := msg.MessageAttributes[name]; ok && value.StringValue != nil {
+			return *value.StringValue
+		}
+		return ""
+	}
+
+	deadLetter := DeadLetter{
+		ID:        *msg.MessageId,
+		TaskType:  attr("TaskType"),
+		Payload:   aws.ToString(msg.Body),
+		LastError: attr("FailureReason"),
+	}
+	if deadLetter.TaskType == "" {
+		deadLetter.TaskType = TypeVideoProcessing
+	}
+	deadLetter.Attempts, _ = strconv.Atoi(attr("Attempts"))
+	deadLetter.FailedAt, _ = time.Parse(time.RFC3339, attr("FailedAt"))
+	return deadLetter
+}
+
 // GetQueueDepth retorna la cantidad aproximada de mensajes en la cola
 func (q *SQSQueueClient) GetQueueDepth(ctx context.Context) (int64, error) {
 	result, err := q.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
@@ -2,10 +2,14 @@ package workers
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 
 	"back/internal/config"
+	"back/internal/services"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -15,35 +19,45 @@ const (
 // TaskQueue wraps el cliente de cola y provee helpers para encolar tareas
 // Compatible con Redis (Asynq) y SQS
 type TaskQueue struct {
-	client QueueClient
-	cfg    *config.Config
+	client      QueueClient
+	cfg         *config.Config
+	taskService *services.TaskService
 }
 
 // NewTaskQueue crea una nueva instancia de TaskQueue usando la configuración
-func NewTaskQueue(cfg *config.Config) (*TaskQueue, error) {
+func NewTaskQueue(cfg *config.Config, db *sql.DB) (*TaskQueue, error) {
 	client, err := NewQueueClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create queue client: %w", err)
 	}
 
 	return &TaskQueue{
-		client: client,
-		cfg:    cfg,
+		client:      client,
+		cfg:         cfg,
+		taskService: services.NewTaskService(db),
 	}, nil
 }
 
-// EnqueueVideoProcessing encola una tarea para procesar un video
-func (q *TaskQueue) EnqueueVideoProcessing(videoID string) error {
-	payload, err := json.Marshal(map[string]string{"video_id": videoID})
+// EnqueueVideoProcessing encola una tarea para procesar un video y retorna su task_id.
+// La tarea queda registrada en task_results como 'pending' antes de enviarse a la cola.
+func (q *TaskQueue) EnqueueVideoProcessing(videoID string) (string, error) {
+	taskID := uuid.New().String()
+
+	payload, err := json.Marshal(VideoProcessPayload{VideoID: videoID, TaskID: taskID})
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if err := q.taskService.CreateTask(taskID, videoID); err != nil {
+		return "", fmt.Errorf("failed to register task: %w", err)
 	}
 
 	if err := q.client.Enqueue(context.Background(), TypeVideoProcessing, payload); err != nil {
-		return fmt.Errorf("enqueue failed: %w", err)
+		_ = q.taskService.MarkTaskFailed(taskID, "enqueue failed: "+err.Error())
+		return taskID, fmt.Errorf("enqueue failed: %w", err)
 	}
 
-	return nil
+	return taskID, nil
 }
 
 // GetQueueDepth retorna la cantidad de mensajes pendientes en la cola
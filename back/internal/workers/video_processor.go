@@ -4,20 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
-	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"back/internal/config"
+	"back/internal/pipeline"
 	"back/internal/services"
 	"back/internal/services/storage"
-	"back/internal/utils"
 )
 
 // VideoProcessPayload corresponde al payload de la tarea
 type VideoProcessPayload struct {
 	VideoID string `json:"video_id"`
+	TaskID  string `json:"task_id,omitempty"`
 }
 
 // VideoProcessor gestiona el worker y el procesamiento de videos
@@ -26,17 +30,21 @@ type VideoProcessor struct {
 	db           *sql.DB
 	config       *config.Config
 	videoService services.VideoServiceInterface
+	taskService  *services.TaskService
 	storage      storage.Storage
+	pipeline     *pipeline.Pipeline
 }
 
 // NewVideoProcessor crea un procesador listo para Start()
-func NewVideoProcessor(taskQueue *TaskQueue, db *sql.DB, videoService services.VideoServiceInterface, fileStorage storage.Storage) *VideoProcessor {
+func NewVideoProcessor(taskQueue *TaskQueue, db *sql.DB, videoService services.VideoServiceInterface, fileStorage storage.Storage, videoPipeline *pipeline.Pipeline) *VideoProcessor {
 	return &VideoProcessor{
 		queueClient:  taskQueue.GetClient(),
 		db:           db,
 		config:       taskQueue.cfg,
 		videoService: videoService,
+		taskService:  services.NewTaskService(db),
 		storage:      fileStorage,
+		pipeline:     videoPipeline,
 	}
 }
 
@@ -62,121 +70,265 @@ func (vp *VideoProcessor) Start(ctx context.Context) error {
 	return nil
 }
 
-// HandleVideoProcessing procesa una tarea de video
+// HandleVideoProcessing procesa una tarea de video y registra su ciclo de vida en task_results
 func (vp *VideoProcessor) HandleVideoProcessing(ctx context.Context, payload []byte) error {
 	var videoPayload VideoProcessPayload
 	if err := json.Unmarshal(payload, &videoPayload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %v", err)
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
 	}
 
-	log.Printf("Processing video: %s", videoPayload.VideoID)
+	// Los mensajes encolados antes de registrar tareas no traen task_id
+	if videoPayload.TaskID == "" {
+		videoPayload.TaskID = videoPayload.VideoID
+	}
 
-	// Marcar como "en proceso" al inicio
-	if err := vp.videoService.MarkProcessing(videoPayload.VideoID); err != nil {
-		return fmt.Errorf("failed to mark as processing: %v", err)
+	if err := vp.taskService.MarkTaskRunning(videoPayload.TaskID, videoPayload.VideoID); err != nil {
+		log.Printf("Warning: failed to mark task %s as running: %v", videoPayload.TaskID, err)
 	}
 
-	// Obtener el video de la base de datos para obtener su ruta original
-	video, err := vp.videoService.GetVideoByID(videoPayload.VideoID, 0) // El 0 indica que no se verifica el usuario
-	if err != nil || video == nil {
-		_ = vp.videoService.MarkFailed(videoPayload.VideoID, "video not found")
-		return fmt.Errorf("video not found or database error: %v", err)
+	attempt := AttemptFromContext(ctx)
+	if err := vp.processVideo(ctx, videoPayload); err != nil {
+		if ShuttingDown(ctx) {
+			// El worker se está apagando: la cola devuelve la tarea sin contarla como fallo
+			log.Printf("Video %s interrupted by worker shutdown, returning it to the queue: %v", videoPayload.VideoID, err)
+			if resetErr := vp.videoService.ResetProcessing(videoPayload.VideoID); resetErr != nil {
+				log.Printf("Warning: failed to reset video %s to uploaded: %v", videoPayload.VideoID, resetErr)
+			}
+			if markErr := vp.taskService.MarkTaskInterrupted(videoPayload.TaskID); markErr != nil {
+				log.Printf("Warning: failed to mark task %s as interrupted: %v", videoPayload.TaskID, markErr)
+			}
+			return err
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// Venció WORKER_TASK_TIMEOUT: es un intento fallido más
+			err = processingFailure("processing timed out", fmt.Errorf("processing exceeded %s: %w", taskTimeout(vp.config.WorkerTaskTimeout), err))
+		}
+		if !IsPermanent(err) && !attempt.Final() {
+			// La cola volverá a entregar la tarea; el video sigue 'processing'
+			log.Printf("Video %s failed on attempt %d/%d, will retry: %v", videoPayload.VideoID, attempt.Number, attempt.Max, err)
+			if markErr := vp.taskService.MarkTaskRetrying(videoPayload.TaskID, fmt.Sprintf("attempt %d/%d: %v", attempt.Number, attempt.Max, err)); markErr != nil {
+				log.Printf("Warning: failed to mark task %s as retrying: %v", videoPayload.TaskID, markErr)
+			}
+			return err
+		}
+
+		// Fallo definitivo: la cola envía la tarea a la dead-letter queue
+		reason := failureReason(err)
+		if !IsPermanent(err) && attempt.Max > 1 {
+			reason = fmt.Sprintf("%s (after %d attempts)", reason, attempt.Number)
+		}
+		if markErr := vp.videoService.MarkFailed(videoPayload.VideoID, reason); markErr != nil {
+			log.Printf("Warning: failed to mark video %s as failed: %v", videoPayload.VideoID, markErr)
+		}
+		if markErr := vp.taskService.MarkTaskFailed(videoPayload.TaskID, err.Error()); markErr != nil {
+			log.Printf("Warning: failed to mark task %s as failed: %v", videoPayload.TaskID, markErr)
+		}
+		return err
 	}
 
-	// === Descargar video desde storage (S3 o local) a /tmp ===
-	tmpInputPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s_input.mp4", videoPayload.VideoID))
-	tmpOutputPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s_output.mp4", videoPayload.VideoID))
+	if err := vp.taskService.MarkTaskCompleted(videoPayload.TaskID); err != nil {
+		log.Printf("Warning: failed to mark task %s as completed: %v", videoPayload.TaskID, err)
+	}
 
-	// Limpiar archivos temporales al final
-	defer os.Remove(tmpInputPath)
-	defer os.Remove(tmpOutputPath)
+	return nil
+}
 
-	log.Printf("Downloading video from storage: %s", *video.OriginalURL)
-	if err := vp.storage.DownloadToFile(*video.OriginalURL, tmpInputPath); err != nil {
-		_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to download video from storage")
-		return fmt.Errorf("failed to download video: %v", err)
+// Etapas del procesamiento fuera del pipeline; las del pipeline llevan el nombre del paso
+const (
+	stageDownloading = "downloading"
+	stageUploading   = "uploading"
+)
+
+// El pipeline ocupa este tramo del avance total: antes va la descarga y después la subida
+const (
+	pipelineProgressStart = 5
+	pipelineProgressEnd   = 90
+)
+
+// progressInterval limita cada cuánto se guarda el avance en la base de datos
+const progressInterval = 2 * time.Second
+
+// progressReporter guarda el avance de un video. Los cambios de etapa se guardan siempre; dentro
+// de una etapa, como mucho uno cada progressInterval.
+type progressReporter struct {
+	videoService services.VideoServiceInterface
+	videoID      string
+	stage        string
+	percent      int
+	savedAt      time.Time
+}
+
+func (r *progressReporter) report(stage string, percent int) {
+	if stage == r.stage && (percent == r.percent || time.Since(r.savedAt) < progressInterval) {
+		return
+	}
+	r.stage, r.percent, r.savedAt = stage, percent, time.Now()
+
+	if err := r.videoService.UpdateProgress(r.videoID, stage, percent); err != nil {
+		log.Printf("Warning: failed to save progress of video %s: %v", r.videoID, err)
+	}
+}
+
+// processingError conserva el motivo que se guarda en el video si el fallo es definitivo
+type processingError struct {
+	reason string
+	err    error
+}
+
+func (e *processingError) Error() string { return e.err.Error() }
+
+func (e *processingError) Unwrap() error { return e.err }
+
+func processingFailure(reason string, err error) error {
+	return &processingError{reason: reason, err: err}
+}
+
+// failureReason retorna el motivo legible de un fallo de processVideo
+func failureReason(err error) string {
+	var procErr *processingError
+	if errors.As(err, &procErr) {
+		return procErr.reason
+	}
+	return "processing failed"
+}
+
+// uploadMedia sube un archivo auxiliar del video como processed/<id>_<label><ext> y retorna su
+// ruta web ("/videos/<id>_<label><ext>"), o "" si no se generó o no se pudo subir
+func (vp *VideoProcessor) uploadMedia(videoID, localPath, label string) string {
+	if localPath == "" {
+		return ""
 	}
 
-	// Ahora usamos el archivo temporal descargado
-	srcPath := tmpInputPath
-	dstPath := tmpOutputPath
+	fileName := fmt.Sprintf("%s_%s%s", videoID, label, filepath.Ext(localPath))
+	if err := vp.storage.UploadFromFile(localPath, "processed/"+fileName); err != nil {
+		log.Printf("Warning: failed to upload %s for video %s: %v", label, videoID, err)
+		return ""
+	}
+	return "/videos/" + fileName
+}
 
-	// 1. Validar duración del video
-	duration, err := utils.GetVideoDuration(srcPath)
+// uploadHLS sube el directorio HLS conservando su estructura. Los segmentos se suben antes que
+// los playlists para que un playlist publicado nunca apunte a segmentos faltantes.
+func (vp *VideoProcessor) uploadHLS(localDir, destPrefix string) error {
+	var segments, playlists []string
+	err := filepath.WalkDir(localDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(rel) == ".m3u8" {
+			playlists = append(playlists, rel)
+		} else {
+			segments = append(segments, rel)
+		}
+		return nil
+	})
 	if err != nil {
-		_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to get video duration")
-		return fmt.Errorf("failed to get video duration: %v", err)
-	}
-	if duration > float64(vp.config.MaxVideoDuration) {
-		log.Printf("Trimming video %s from %.2f seconds to %d seconds", videoPayload.VideoID, duration, vp.config.MaxVideoDuration)
-		tmpPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s_trimmed.mp4", videoPayload.VideoID))
-		if err := utils.TrimVideo(srcPath, tmpPath, vp.config.MaxVideoDuration); err != nil {
-			_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to trim video")
-			return fmt.Errorf("failed to trim video: %v", err)
+		return err
+	}
+
+	// El maestro va al final, después de los playlists de cada variante
+	sort.SliceStable(playlists, func(i, j int) bool {
+		return filepath.Dir(playlists[i]) != "." && filepath.Dir(playlists[j]) == "."
+	})
+
+	for _, rel := range append(segments, playlists...) {
+		dest := destPrefix + "/" + filepath.ToSlash(rel)
+		if err := vp.storage.UploadFromFile(filepath.Join(localDir, rel), dest); err != nil {
+			return fmt.Errorf("upload %s: %w", dest, err)
 		}
-		srcPath = tmpPath
-		defer os.Remove(tmpPath) // Elimina el archivo temporal
 	}
 
-	// 2. Eliminar audio
-	log.Printf("Removing audio from video %s", videoPayload.VideoID)
-	tmpNoAudio := filepath.Join(os.TempDir(), fmt.Sprintf("%s_noaudio.mp4", videoPayload.VideoID))
-	if err := utils.RemoveAudio(srcPath, tmpNoAudio); err != nil {
-		_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to remove audio")
-		return fmt.Errorf("failed to remove audio: %v", err)
+	log.Printf("Uploaded %d HLS segments and %d playlists to %s", len(segments), len(playlists), destPrefix)
+	return nil
+}
+
+// processVideo ejecuta el procesamiento del video: descarga, pasos del perfil y subida
+func (vp *VideoProcessor) processVideo(ctx context.Context, videoPayload VideoProcessPayload) error {
+	log.Printf("Processing video: %s (task %s)", videoPayload.VideoID, videoPayload.TaskID)
+
+	// Marcar como "en proceso" al inicio
+	if err := vp.videoService.MarkProcessing(videoPayload.VideoID); err != nil {
+		return processingFailure("failed to update video status", fmt.Errorf("failed to mark as processing: %v", err))
 	}
-	defer os.Remove(tmpNoAudio)
 
-	// 3. Conversión optimizada a 720p + watermark en un solo paso
-	log.Printf("Converting video %s to 720p with watermark ", videoPayload.VideoID)
-	watermarkPath := "/app/assets/anb_watermark.png"
+	// Obtener el video de la base de datos para obtener su ruta original
+	video, err := vp.videoService.GetVideoByID(videoPayload.VideoID, 0) // El 0 indica que no se verifica el usuario
+	if err != nil {
+		return processingFailure("failed to load video", fmt.Errorf("database error: %v", err))
+	}
+	if video == nil {
+		return Permanent(processingFailure("video not found", fmt.Errorf("video %s not found", videoPayload.VideoID)))
+	}
 
-	// Usar la función optimizada que combina conversión y watermark
-	if err := utils.OptimizedConvertAndWatermark(tmpNoAudio, dstPath, watermarkPath); err != nil {
-		log.Printf("Optimized processing failed, falling back to step-by-step: %v", err)
+	// === Descargar video desde storage (S3 o local) al directorio temporal del trabajo ===
+	job, err := pipeline.NewJob(videoPayload.VideoID, filepath.Ext(*video.OriginalURL))
+	if err != nil {
+		return processingFailure("failed to prepare processing job", err)
+	}
+	defer job.Cleanup()
 
-		// Fallback: procesamiento por pasos si falla el optimizado
-		tmpConverted := filepath.Join(os.TempDir(), fmt.Sprintf("%s_converted.mp4", videoPayload.VideoID))
-		if err := utils.ConvertTo720p(tmpNoAudio, tmpConverted); err != nil {
-			_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to convert video to 720p")
-			return fmt.Errorf("failed to convert video: %v", err)
-		}
-		defer os.Remove(tmpConverted)
-
-		// Intentar watermark como paso separado
-		if utils.FileExists(watermarkPath) {
-			if err := utils.AddWatermark(tmpConverted, dstPath, watermarkPath); err != nil {
-				log.Printf("Warning: failed to add watermark, continuing without it: %v", err)
-				if err := utils.CopyFile(tmpConverted, dstPath); err != nil {
-					_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to copy final video")
-					return fmt.Errorf("failed to copy video: %v", err)
-				}
-			}
-		} else {
-			if err := utils.CopyFile(tmpConverted, dstPath); err != nil {
-				_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to copy final video")
-				return fmt.Errorf("failed to copy video: %v", err)
-			}
+	progress := &progressReporter{videoService: vp.videoService, videoID: videoPayload.VideoID}
+	progress.report(stageDownloading, 0)
+
+	log.Printf("Downloading video from storage: %s", *video.OriginalURL)
+	if err := vp.storage.DownloadToFile(*video.OriginalURL, job.InputPath()); err != nil {
+		return processingFailure("failed to download video from storage", fmt.Errorf("failed to download video: %v", err))
+	}
+
+	// === Ejecutar los pasos del perfil de procesamiento ===
+	log.Printf("Running pipeline %q on video %s: %s", vp.pipeline.Name(), videoPayload.VideoID, vp.pipeline.Describe())
+	result, err := vp.pipeline.Run(ctx, job, func(p pipeline.Progress) {
+		progress.report(p.Stage, pipelineProgressStart+p.Percent*(pipelineProgressEnd-pipelineProgressStart)/100)
+	})
+	if err != nil {
+		reason := "processing pipeline failed"
+		var stepErr *pipeline.StepError
+		if errors.As(err, &stepErr) {
+			reason = fmt.Sprintf("failed at %s step", stepErr.Step)
 		}
+		return processingFailure(reason, err)
 	}
 
 	// === Subir video procesado al storage (S3 o local) ===
 	// Usar el prefijo "processed/" para que el storage detecte automáticamente
 	// dónde guardar según la configuración (PROCESSED_PATH o S3_PROCESSED_PREFIX)
 	processedRelativePath := fmt.Sprintf("processed/%s_processed.mp4", videoPayload.VideoID)
+	progress.report(stageUploading, pipelineProgressEnd)
 	log.Printf("Uploading processed video to storage: %s", processedRelativePath)
 
-	if err := vp.storage.UploadFromFile(dstPath, processedRelativePath); err != nil {
-		_ = vp.videoService.MarkFailed(videoPayload.VideoID, "failed to upload processed video to storage")
-		return fmt.Errorf("failed to upload processed video: %v", err)
+	if err := vp.storage.UploadFromFile(result.OutputPath, processedRelativePath); err != nil {
+		return processingFailure("failed to upload processed video to storage", fmt.Errorf("failed to upload processed video: %v", err))
+	}
+
+	// Miniatura y vista previa junto al video procesado; si fallan, el video se publica sin ellas
+	thumbnailURL := vp.uploadMedia(videoPayload.VideoID, result.ThumbnailPath, "thumb")
+	previewURL := vp.uploadMedia(videoPayload.VideoID, result.PreviewPath, "preview")
+	if err := vp.videoService.SetMediaURLs(videoPayload.VideoID, thumbnailURL, previewURL); err != nil {
+		log.Printf("Warning: failed to save thumbnail/preview for video %s: %v", videoPayload.VideoID, err)
 	}
 
 	// Marcar video como procesado con la ruta web relativa
 	// Para local: nginx sirve /videos/ desde /app/processed/
 	// Para S3: se generará presigned URL en el handler
 	webURL := fmt.Sprintf("/videos/%s_processed.mp4", videoPayload.VideoID)
+
+	// Con HLS, processed_url apunta al playlist maestro y el MP4 queda como respaldo
+	if result.HLSDir != "" {
+		hlsPrefix := fmt.Sprintf("processed/%s/hls", videoPayload.VideoID)
+		if err := vp.uploadHLS(result.HLSDir, hlsPrefix); err != nil {
+			if delErr := vp.storage.DeletePrefix(hlsPrefix); delErr != nil {
+				log.Printf("Warning: failed to clean up HLS files for video %s: %v", videoPayload.VideoID, delErr)
+			}
+			return processingFailure("failed to upload HLS renditions to storage", fmt.Errorf("failed to upload HLS renditions: %v", err))
+		}
+		webURL = fmt.Sprintf("/videos/%s/hls/master.m3u8", videoPayload.VideoID)
+	}
 	if err := vp.videoService.MarkProcessed(videoPayload.VideoID, webURL); err != nil {
-		return fmt.Errorf("failed to mark processed: %v", err)
+		return processingFailure("failed to update video status", fmt.Errorf("failed to mark processed: %v", err))
 	}
 
 	log.Printf("Successfully processed video: %s", videoPayload.VideoID)
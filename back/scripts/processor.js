@@ -29,6 +29,12 @@ module.exports = {
     return done();
   },
 
+  // Idempotency-Key compartida por los reintentos de un mismo voto
+  generateIdempotencyKey: function(context, events, done) {
+    context.vars.idempotencyKey = require('crypto').randomUUID();
+    return done();
+  },
+
   // Función auxiliar para generar datos aleatorios
   generateRandomData: function(context, events, done) {
     context.vars.randomFirstName = 'User' + Math.floor(Math.random() * 10000);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_results_video_created;
+ALTER TABLE IF EXISTS task_results DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE IF EXISTS task_results DROP COLUMN IF EXISTS started_at;
+ALTER TABLE IF EXISTS task_results DROP COLUMN IF EXISTS attempts;
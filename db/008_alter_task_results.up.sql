@@ -0,0 +1,7 @@
+-- Ciclo de vida de tareas: intentos y marcas de tiempo de ejecución
+ALTER TABLE task_results ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE task_results ADD COLUMN IF NOT EXISTS started_at TIMESTAMP;
+ALTER TABLE task_results ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Índice para consultar el historial de tareas de un video
+CREATE INDEX IF NOT EXISTS idx_task_results_video_created ON task_results(video_id, created_at DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_upload_sessions_status_expires;
+DROP INDEX IF EXISTS idx_upload_sessions_user_id;
+DROP TABLE IF EXISTS upload_session_parts;
+DROP TABLE IF EXISTS upload_sessions;
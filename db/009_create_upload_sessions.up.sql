@@ -0,0 +1,31 @@
+-- Subidas reanudables por partes
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    video_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    original_filename VARCHAR(255) NOT NULL,
+    is_public BOOLEAN DEFAULT false,
+    total_size BIGINT NOT NULL,
+    chunk_size BIGINT NOT NULL,
+    storage_path VARCHAR(500) NOT NULL,
+    storage_upload_id VARCHAR(1024),
+    status VARCHAR(20) DEFAULT 'active' CHECK (status IN ('active', 'completing', 'completed', 'aborted')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS upload_session_parts (
+    upload_id UUID REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    etag VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (upload_id, part_number)
+);
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_user_id ON upload_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_status_expires ON upload_sessions(status, expires_at);
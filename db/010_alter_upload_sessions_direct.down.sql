@@ -0,0 +1,3 @@
+ALTER TABLE upload_sessions
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS upload_type;
@@ -0,0 +1,4 @@
+-- Subidas directas al storage con URL firmada (sin pasar el archivo por la API)
+ALTER TABLE upload_sessions
+    ADD COLUMN IF NOT EXISTS upload_type VARCHAR(20) NOT NULL DEFAULT 'chunked' CHECK (upload_type IN ('chunked', 'direct')),
+    ADD COLUMN IF NOT EXISTS content_type VARCHAR(100);
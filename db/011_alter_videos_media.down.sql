@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS preview_url;
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS thumbnail_url;
@@ -0,0 +1,3 @@
+-- Miniatura (poster) y vista previa animada generadas por el worker
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS thumbnail_url VARCHAR(500);
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS preview_url VARCHAR(500);
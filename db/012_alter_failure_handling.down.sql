@@ -0,0 +1,6 @@
+UPDATE task_results SET status = 'failed' WHERE status = 'retrying';
+ALTER TABLE IF EXISTS task_results DROP CONSTRAINT IF EXISTS task_results_status_check;
+ALTER TABLE IF EXISTS task_results ADD CONSTRAINT task_results_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed'));
+
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS failure_reason;
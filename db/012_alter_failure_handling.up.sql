@@ -0,0 +1,7 @@
+-- Motivo del fallo definitivo del procesamiento de un video
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+-- Estado 'retrying': el intento falló y la cola lo volverá a entregar
+ALTER TABLE task_results DROP CONSTRAINT IF EXISTS task_results_status_check;
+ALTER TABLE task_results ADD CONSTRAINT task_results_status_check
+    CHECK (status IN ('pending', 'running', 'retrying', 'completed', 'failed'));
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS processing_stage;
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS progress;
@@ -0,0 +1,3 @@
+-- Avance del procesamiento mientras el video está 'processing': porcentaje y etapa en curso
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS progress SMALLINT CHECK (progress BETWEEN 0 AND 100);
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS processing_stage VARCHAR(64);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notify_votes_ranking_change ON votes;
+DROP FUNCTION IF EXISTS notify_ranking_change();
@@ -0,0 +1,13 @@
+-- Avisa a las réplicas de la API que cambiaron los votos para actualizar el ranking en vivo.
+-- Una notificación por sentencia: la API recalcula el ranking completo, no voto por voto.
+CREATE OR REPLACE FUNCTION notify_ranking_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('ranking_events', TG_OP);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_votes_ranking_change
+    AFTER INSERT OR DELETE ON votes
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_ranking_change();
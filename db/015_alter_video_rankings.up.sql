@@ -0,0 +1,33 @@
+-- La vista de rankings incluye miniatura y vista previa para servir el ranking sin consultar videos
+DROP MATERIALIZED VIEW IF EXISTS video_rankings;
+CREATE MATERIALIZED VIEW video_rankings AS
+SELECT
+    v.id,
+    v.title,
+    v.processed_url,
+    v.thumbnail_url,
+    v.preview_url,
+    v.votes_count,
+    v.uploaded_at,
+    u.first_name || ' ' || u.last_name as username,
+    u.city,
+    u.country,
+    ROW_NUMBER() OVER (ORDER BY v.votes_count DESC, v.uploaded_at ASC) as global_position,
+    ROW_NUMBER() OVER (PARTITION BY u.city ORDER BY v.votes_count DESC, v.uploaded_at ASC) as city_position
+FROM videos v
+JOIN users u ON v.user_id = u.id
+WHERE v.is_public = true AND v.status = 'processed'
+ORDER BY v.votes_count DESC, v.uploaded_at ASC;
+
+-- REFRESH ... CONCURRENTLY requiere un índice único
+CREATE UNIQUE INDEX IF NOT EXISTS idx_video_rankings_id ON video_rankings(id);
+CREATE INDEX IF NOT EXISTS idx_video_rankings_global_position ON video_rankings(global_position);
+CREATE INDEX IF NOT EXISTS idx_video_rankings_city_position ON video_rankings(city, city_position);
+
+-- Última actualización de cada vista materializada (compartida entre réplicas de la API)
+CREATE TABLE IF NOT EXISTS materialized_view_refreshes (
+    view_name VARCHAR(64) PRIMARY KEY,
+    refreshed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO materialized_view_refreshes (view_name) VALUES ('video_rankings') ON CONFLICT (view_name) DO NOTHING;
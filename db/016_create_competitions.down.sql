@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS update_round_tally ON votes;
+DROP FUNCTION IF EXISTS update_round_tally();
+
+-- Vuelve a un voto por usuario y video: se conserva el primero
+DELETE FROM votes a USING votes b WHERE a.user_id = b.user_id AND a.video_id = b.video_id AND a.id > b.id;
+ALTER TABLE IF EXISTS votes DROP CONSTRAINT IF EXISTS votes_user_id_video_id_round_id_key;
+ALTER TABLE IF EXISTS votes DROP CONSTRAINT IF EXISTS votes_user_id_video_id_key;
+ALTER TABLE IF EXISTS votes ADD CONSTRAINT votes_user_id_video_id_key UNIQUE (user_id, video_id);
+DROP INDEX IF EXISTS idx_votes_round_id;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS round_id;
+
+DROP TABLE IF EXISTS round_winners;
+DROP TABLE IF EXISTS round_tallies;
+DROP TABLE IF EXISTS rounds;
+DROP TABLE IF EXISTS competitions;
@@ -0,0 +1,74 @@
+-- Competencias (p. ej. una edición del tryout) divididas en rondas: inscripciones abiertas,
+-- votación regional y final nacional
+CREATE TABLE IF NOT EXISTS competitions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Las ventanas se guardan con zona horaria: la API las recibe en RFC 3339 y las compara con NOW()
+CREATE TABLE IF NOT EXISTS rounds (
+    id SERIAL PRIMARY KEY,
+    competition_id INTEGER NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    phase VARCHAR(20) NOT NULL CHECK (phase IN ('submissions', 'regional', 'national')),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    eligible_cities TEXT[] NOT NULL DEFAULT '{}', -- vacío = todas las ciudades
+    qualifying_round_id INTEGER REFERENCES rounds(id), -- solo compiten los ganadores de esa ronda
+    winners_per_city INTEGER NOT NULL DEFAULT 3 CHECK (winners_per_city > 0),
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+-- Votos de cada video en cada ronda (los mantiene un trigger sobre votes)
+CREATE TABLE IF NOT EXISTS round_tallies (
+    round_id INTEGER REFERENCES rounds(id) ON DELETE CASCADE,
+    video_id UUID REFERENCES videos(id) ON DELETE CASCADE,
+    votes_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (round_id, video_id)
+);
+
+-- Ganadores de cada ronda, guardados al cerrarla
+CREATE TABLE IF NOT EXISTS round_winners (
+    round_id INTEGER REFERENCES rounds(id) ON DELETE CASCADE,
+    video_id UUID REFERENCES videos(id) ON DELETE CASCADE,
+    city VARCHAR(100) NOT NULL,
+    votes_count INTEGER NOT NULL,
+    city_position INTEGER NOT NULL,
+    global_position INTEGER NOT NULL,
+    PRIMARY KEY (round_id, video_id)
+);
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_rounds_competition_id ON rounds(competition_id);
+CREATE INDEX IF NOT EXISTS idx_rounds_window ON rounds(starts_at, ends_at) WHERE closed_at IS NULL;
+
+-- Los votos emitidos durante una ronda quedan asociados a ella: un usuario puede votar por el
+-- mismo video una vez por ronda (y una vez fuera de rondas)
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS round_id INTEGER REFERENCES rounds(id);
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_user_id_video_id_key;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_user_id_video_id_round_id_key;
+ALTER TABLE votes ADD CONSTRAINT votes_user_id_video_id_round_id_key UNIQUE NULLS NOT DISTINCT (user_id, video_id, round_id);
+CREATE INDEX IF NOT EXISTS idx_votes_round_id ON votes(round_id);
+
+-- Función para actualizar los votos de cada ronda
+CREATE OR REPLACE FUNCTION update_round_tally()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.round_id IS NOT NULL THEN
+        INSERT INTO round_tallies (round_id, video_id, votes_count) VALUES (NEW.round_id, NEW.video_id, 1)
+        ON CONFLICT (round_id, video_id) DO UPDATE SET votes_count = round_tallies.votes_count + 1;
+    ELSIF TG_OP = 'DELETE' AND OLD.round_id IS NOT NULL THEN
+        UPDATE round_tallies SET votes_count = votes_count - 1
+        WHERE round_id = OLD.round_id AND video_id = OLD.video_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_round_tally
+    AFTER INSERT OR DELETE ON votes
+    FOR EACH ROW EXECUTE FUNCTION update_round_tally();
@@ -0,0 +1,43 @@
+-- Los votos anulados vuelven a contar
+CREATE OR REPLACE FUNCTION update_round_tally()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.round_id IS NOT NULL THEN
+        INSERT INTO round_tallies (round_id, video_id, votes_count) VALUES (NEW.round_id, NEW.video_id, 1)
+        ON CONFLICT (round_id, video_id) DO UPDATE SET votes_count = round_tallies.votes_count + 1;
+    ELSIF TG_OP = 'DELETE' AND OLD.round_id IS NOT NULL THEN
+        UPDATE round_tallies SET votes_count = votes_count - 1
+        WHERE round_id = OLD.round_id AND video_id = OLD.video_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION update_vote_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE videos SET votes_count = votes_count + 1 WHERE id = NEW.video_id;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE videos SET votes_count = votes_count - 1 WHERE id = OLD.video_id;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP INDEX IF EXISTS idx_votes_flagged;
+DROP INDEX IF EXISTS idx_votes_device_created_at;
+DROP INDEX IF EXISTS idx_votes_ip_created_at;
+
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS voided_at;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS flag_reason;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS flagged_at;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS device_id;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE IF EXISTS votes DROP COLUMN IF EXISTS ip_address;
+
+UPDATE videos v SET votes_count = (SELECT COUNT(*) FROM votes WHERE video_id = v.id);
+UPDATE round_tallies t SET votes_count = (SELECT COUNT(*) FROM votes WHERE round_id = t.round_id AND video_id = t.video_id);
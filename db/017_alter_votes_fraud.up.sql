@@ -0,0 +1,47 @@
+-- Origen de cada voto, para los límites por IP y dispositivo y la detección de fraude
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS ip_address INET;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS device_id VARCHAR(64);
+
+-- Votos marcados como sospechosos por el análisis periódico, revisados (legítimos) o anulados por
+-- un administrador. Un voto anulado no cuenta en votes_count ni en round_tallies
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS flagged_at TIMESTAMP;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS flag_reason VARCHAR(50);
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_votes_ip_created_at ON votes(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_votes_device_created_at ON votes(device_id, created_at) WHERE device_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_votes_flagged ON votes(flagged_at) WHERE flagged_at IS NOT NULL AND reviewed_at IS NULL AND voided_at IS NULL;
+
+-- Al borrar un voto anulado no hay que descontarlo otra vez
+CREATE OR REPLACE FUNCTION update_vote_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE videos SET votes_count = votes_count + 1 WHERE id = NEW.video_id;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.voided_at IS NULL THEN
+            UPDATE videos SET votes_count = votes_count - 1 WHERE id = OLD.video_id;
+        END IF;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION update_round_tally()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.round_id IS NOT NULL THEN
+        INSERT INTO round_tallies (round_id, video_id, votes_count) VALUES (NEW.round_id, NEW.video_id, 1)
+        ON CONFLICT (round_id, video_id) DO UPDATE SET votes_count = round_tallies.votes_count + 1;
+    ELSIF TG_OP = 'DELETE' AND OLD.round_id IS NOT NULL AND OLD.voided_at IS NULL THEN
+        UPDATE round_tallies SET votes_count = votes_count - 1
+        WHERE round_id = OLD.round_id AND video_id = OLD.video_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
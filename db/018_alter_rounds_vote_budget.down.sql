@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_votes_user_round;
+
+ALTER TABLE rounds DROP COLUMN IF EXISTS votes_per_user;
@@ -0,0 +1,5 @@
+-- Presupuesto de votos por usuario en cada ronda (NULL = sin límite): un usuario puede votar por
+-- a lo sumo votes_per_user videos de la ronda; retirar un voto libera el cupo para otro video
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS votes_per_user INTEGER CHECK (votes_per_user > 0);
+
+CREATE INDEX IF NOT EXISTS idx_votes_user_round ON votes(user_id, round_id);
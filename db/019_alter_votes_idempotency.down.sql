@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_votes_user_idempotency_key;
+
+ALTER TABLE votes DROP COLUMN IF EXISTS idempotency_key;
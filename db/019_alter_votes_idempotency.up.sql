@@ -0,0 +1,6 @@
+-- Idempotency-Key con la que el cliente emitió el voto: un reintento con la misma clave
+-- responde el voto ya registrado en lugar de un conflicto
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_votes_user_idempotency_key
+    ON votes(user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_sessions_previous_token;
+DROP INDEX IF EXISTS idx_user_sessions_jti;
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS previous_token;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS jti;
@@ -0,0 +1,9 @@
+-- Sesiones con refresh token rotativo. session_token guarda el hash SHA-256 del refresh token
+-- vigente y previous_token el del anterior, para detectar la reutilización de un token ya rotado.
+-- jti identifica la sesión en los access tokens: al revocarla dejan de ser válidos.
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS jti UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS previous_token VARCHAR(255);
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sessions_jti ON user_sessions(jti);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_previous_token ON user_sessions(previous_token);
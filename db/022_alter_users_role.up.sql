@@ -0,0 +1,7 @@
+-- Rol del usuario: player (sube videos y vota), voter (solo vota), jury (revisa la competencia)
+-- y admin (opera la competencia por /api/admin)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'player'
+    CHECK (role IN ('player', 'voter', 'jury', 'admin'));
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
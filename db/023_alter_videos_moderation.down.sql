@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_videos_pending_review;
+
+-- Los videos sin aprobar vuelven a 'processed' pero privados, para no publicarlos sin revisión
+UPDATE videos SET status = 'processed', is_public = false WHERE status IN ('pending_review', 'rejected');
+
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_status_check;
+ALTER TABLE videos ADD CONSTRAINT videos_status_check
+    CHECK (status IN ('uploaded', 'processing', 'processed', 'failed'));
+
+ALTER TABLE videos DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE videos DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE videos DROP COLUMN IF EXISTS reviewed_at;
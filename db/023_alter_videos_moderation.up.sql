@@ -0,0 +1,13 @@
+-- Moderación de videos: con VIDEO_MODERATION_ENABLED los videos públicos quedan en
+-- 'pending_review' al terminar el procesamiento hasta que un jurado o admin los aprueba
+-- ('processed') o los rechaza ('rejected') con un motivo visible para quien lo subió
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_status_check;
+ALTER TABLE videos ADD CONSTRAINT videos_status_check
+    CHECK (status IN ('uploaded', 'processing', 'processed', 'failed', 'pending_review', 'rejected'));
+
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_videos_pending_review ON videos(processed_at) WHERE status = 'pending_review';
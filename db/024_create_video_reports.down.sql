@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_video_reports_open;
+DROP TABLE IF EXISTS video_reports;
+
+ALTER TABLE IF EXISTS videos DROP COLUMN IF EXISTS hidden_at;
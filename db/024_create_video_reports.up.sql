@@ -0,0 +1,21 @@
+-- Denuncias de usuarios sobre videos públicos: una por usuario y video. Al llegar a
+-- VIDEO_REPORT_THRESHOLD denuncias sin resolver, el video se oculta (vuelve a 'pending_review')
+-- hasta que un jurado o admin resuelve las denuncias.
+CREATE TABLE IF NOT EXISTS video_reports (
+    id SERIAL PRIMARY KEY,
+    video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('spam', 'inappropriate', 'violence', 'harassment', 'copyright', 'other')),
+    details TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolution VARCHAR(20) CHECK (resolution IN ('dismissed', 'removed')),
+    UNIQUE(video_id, user_id)
+);
+
+-- Cuándo se ocultó el video por denuncias (NULL = no está oculto por denuncias)
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+-- Índices para optimizar consultas
+CREATE INDEX IF NOT EXISTS idx_video_reports_open ON video_reports(video_id, created_at) WHERE resolved_at IS NULL;
@@ -1,5 +1,5 @@
 import React, { useState, useEffect } from 'react';
-import { Upload, Play, Trophy, User, Home, LogOut, ThumbsUp, Video, Star, TrendingUp, CheckCircle, Clock, XCircle, Loader2, ChevronRight, Award, Users, MapPin, Calendar, Eye, Filter, BarChart3, Shield } from 'lucide-react';
+import { Upload, Play, Trophy, User, Home, LogOut, ThumbsUp, Video, Star, TrendingUp, CheckCircle, Clock, XCircle, Loader2, ChevronRight, Award, Users, MapPin, Calendar, Eye, Filter, BarChart3, Shield, Flag } from 'lucide-react';
 
 // Componente UploadVideo separado para evitar re-renders
 const UploadVideo = ({
@@ -113,7 +113,7 @@ const UploadVideo = ({
             <div className="flex items-center justify-between">
               <div>
                 <h2 className="text-2xl font-bold text-white mb-1">Nueva Subida</h2>
-                <p className="text-orange-100">Formatos soportados: MP4, AVI, MOV (máx. 100MB)</p>
+                <p className="text-orange-100">Formatos soportados: MP4, MOV, WebM, MKV (máx. 100MB)</p>
               </div>
               <Video className="w-16 h-16 text-white opacity-80" />
             </div>
@@ -208,11 +208,11 @@ const UploadVideo = ({
                       <p className="text-lg font-semibold text-gray-700 mb-2">
                         Arrastra tu video aquí o haz clic para seleccionar
                       </p>
-                      <p className="text-gray-500">Formatos soportados: MP4, AVI, MOV</p>
+                      <p className="text-gray-500">Formatos soportados: MP4, MOV, WebM, MKV</p>
                     </div>
                     <input
                       type="file"
-                      accept=".mp4,.avi,.mov,video/mp4,video/avi,video/mov,video/quicktime"
+                      accept=".mp4,.mov,.webm,.mkv,video/mp4,video/quicktime,video/webm,video/x-matroska"
                       onChange={(e) => e.target.files[0] && handleFileSelect(e.target.files[0])}
                       className="hidden"
                       id="file-upload"
@@ -315,6 +315,56 @@ const UploadVideoView = ({
   apiService,
   setCurrentView
 }) => {
+  const [processingVideoId, setProcessingVideoId] = useState(null);
+  const [processingProgress, setProcessingProgress] = useState({ percent: 0, stage: null });
+  const [pendingReview, setPendingReview] = useState(false);
+
+  // Sigue el procesamiento por el stream de eventos hasta que el worker termina
+  useEffect(() => {
+    if (!processingVideoId) return;
+
+    const controller = new AbortController();
+    const applyVideo = (video) => {
+      if (controller.signal.aborted) return;
+
+      // Con la moderación activa, el video público queda esperando aprobación
+      if (video.status === 'processed' || video.status === 'pending_review') {
+        setProcessingVideoId(null);
+        setPendingReview(video.status === 'pending_review');
+        setProcessingStatus('completed');
+        setUploading(false);
+      } else if (video.status === 'failed') {
+        setProcessingVideoId(null);
+        setUploadError(`Error al procesar el video: ${video.failure_reason || 'Error desconocido'}`);
+        setUploading(false);
+        setProcessingStatus(null);
+        setUploadProgress(0);
+      } else {
+        setProcessingProgress({ percent: video.progress || 0, stage: video.processing_stage || null });
+      }
+    };
+
+    const listen = async () => {
+      while (!controller.signal.aborted) {
+        try {
+          await apiService.streamVideoEvents((event) => {
+            if (event.video_id === processingVideoId) applyVideo(event);
+          }, {
+            signal: controller.signal,
+            // Los eventos emitidos sin conexión no se reenvían: consultar el estado al conectar
+            onOpen: () => apiService.getVideoDetail(processingVideoId).then(applyVideo).catch(() => {}),
+          });
+        } catch (err) {
+          if (controller.signal.aborted) return;
+        }
+        await new Promise((resolve) => setTimeout(resolve, 3000));
+      }
+    };
+
+    listen();
+    return () => controller.abort();
+  }, [processingVideoId]);
+
   const handleDrag = (e) => {
     e.preventDefault();
     e.stopPropagation();
@@ -376,15 +426,12 @@ const UploadVideoView = ({
     }, 500);
 
     try {
-      await apiService.uploadVideo(videoTitle.trim(), selectedFile, videoIsPublic);
+      const result = await apiService.uploadVideo(videoTitle.trim(), selectedFile, videoIsPublic);
       clearInterval(interval);
       setUploadProgress(100);
+      setProcessingProgress({ percent: 0, stage: null });
       setProcessingStatus('processing');
-
-      setTimeout(() => {
-        setProcessingStatus('completed');
-        setUploading(false);
-      }, 3000);
+      setProcessingVideoId(result.video_id);
     } catch (err) {
       clearInterval(interval);
       setUploadError(`Error al subir el video: ${err.message || 'Error desconocido'}`);
@@ -405,7 +452,7 @@ const UploadVideoView = ({
             <div className="grid md:grid-cols-2 gap-4 text-sm">
               <div className="flex items-start space-x-2">
                 <CheckCircle size={16} className="mt-0.5 flex-shrink-0" />
-                <span>Formato: MP4, MOV, AVI</span>
+                <span>Formato: MP4, MOV, WebM, MKV</span>
               </div>
               <div className="flex items-start space-x-2">
                 <CheckCircle size={16} className="mt-0.5 flex-shrink-0" />
@@ -483,7 +530,7 @@ const UploadVideoView = ({
                 <label className="cursor-pointer">
                   <input
                     type="file"
-                    accept=".mp4,.avi,.mov,video/mp4,video/avi,video/mov,video/quicktime"
+                    accept=".mp4,.mov,.webm,.mkv,video/mp4,video/quicktime,video/webm,video/x-matroska"
                     onChange={(e) => handleFileSelect(e.target.files[0])}
                     className="hidden"
                   />
@@ -556,26 +603,16 @@ const UploadVideoView = ({
                     <p className="text-2xl font-semibold text-gray-700 mb-4">Procesando tu video...</p>
                     <p className="text-sm text-gray-500 mb-6">Este proceso puede tomar unos minutos. No cierres la ventana.</p>
 
-                    <div className="space-y-3 text-left max-w-md mx-auto">
-                      <div className="flex items-center p-3 bg-green-50 rounded-lg">
-                        <CheckCircle className="text-green-500 mr-3 flex-shrink-0" size={20} />
-                        <span className="text-sm text-green-800">Archivo recibido y validado</span>
-                      </div>
-                      <div className="flex items-center p-3 bg-green-50 rounded-lg">
-                        <CheckCircle className="text-green-500 mr-3 flex-shrink-0" size={20} />
-                        <span className="text-sm text-green-800">Ajustando duración máxima a 30 segundos</span>
+                    <div className="max-w-md mx-auto">
+                      <div className="flex justify-between items-center mb-2">
+                        <span className="text-sm text-gray-600">{processingStageLabel(processingProgress.stage)}</span>
+                        <span className="text-2xl font-bold text-orange-600">{processingProgress.percent}%</span>
                       </div>
-                      <div className="flex items-center p-3 bg-green-50 rounded-lg">
-                        <CheckCircle className="text-green-500 mr-3 flex-shrink-0" size={20} />
-                        <span className="text-sm text-green-800">Configurando resolución 16:9 a 720p</span>
-                      </div>
-                      <div className="flex items-center p-3 bg-blue-50 rounded-lg">
-                        <Loader2 className="animate-spin text-blue-500 mr-3 flex-shrink-0" size={20} />
-                        <span className="text-sm text-blue-800">Aplicando marca de agua ANB</span>
-                      </div>
-                      <div className="flex items-center p-3 bg-gray-50 rounded-lg">
-                        <Clock className="text-gray-400 mr-3 flex-shrink-0" size={20} />
-                        <span className="text-sm text-gray-600">Optimizando para streaming</span>
+                      <div className="w-full bg-gray-200 rounded-full h-4 overflow-hidden">
+                        <div
+                          className="bg-gradient-to-r from-orange-500 to-red-500 h-4 rounded-full transition-all duration-500 ease-out"
+                          style={{ width: `${processingProgress.percent}%` }}
+                        />
                       </div>
                     </div>
 
@@ -591,7 +628,11 @@ const UploadVideoView = ({
                   <div className="text-center">
                     <CheckCircle className="w-20 h-20 mx-auto mb-4 text-green-500" />
                     <p className="text-3xl font-bold text-gray-800 mb-2">¡Video procesado con éxito!</p>
-                    <p className="text-gray-600 mb-6">{videoIsPublic ? 'Tu video ya está disponible para votación pública' : 'Tu video privado ha sido guardado'}</p>
+                    <p className="text-gray-600 mb-6">
+                      {pendingReview
+                        ? 'Tu video se publicará para votación cuando la moderación lo apruebe'
+                        : videoIsPublic ? 'Tu video ya está disponible para votación pública' : 'Tu video privado ha sido guardado'}
+                    </p>
 
                     <div className={`${videoIsPublic ? 'bg-green-50 border-green-200' : 'bg-blue-50 border-blue-200'} border rounded-xl p-4 mb-6 max-w-md mx-auto`}>
                       <p className={`${videoIsPublic ? 'text-green-800' : 'text-blue-800'} text-sm`}>
@@ -642,11 +683,42 @@ const UploadVideoView = ({
   );
 };
 
+// Aplica un evento "update" del ranking en vivo: reemplaza las entradas que cambiaron y quita las que salieron
+const applyRankingUpdate = (rankings, update) => {
+  const removed = new Set(update.removed || []);
+  const changes = update.changes || [];
+  const changed = new Set(changes.map((entry) => entry.video_id));
+
+  return rankings
+    .filter((entry) => !removed.has(entry.video_id) && !changed.has(entry.video_id))
+    .concat(changes)
+    .sort((a, b) => a.position - b.position);
+};
+
+// Etapas que reporta el worker en processing_stage; los filtros combinados llegan como "scale_pad+watermark"
+const PROCESSING_STAGE_LABELS = {
+  downloading: 'Preparando el video',
+  trim: 'Ajustando la duración',
+  strip_audio: 'Quitando el audio',
+  scale_pad: 'Ajustando la resolución',
+  watermark: 'Aplicando marca de agua ANB',
+  intro_outro: 'Agregando cortinillas',
+  thumbnail: 'Generando miniatura',
+  preview: 'Generando vista previa',
+  hls: 'Optimizando para streaming',
+  uploading: 'Publicando el video',
+};
+
+const processingStageLabel = (stage) => {
+  if (!stage) return 'En cola para procesamiento';
+  return stage.split('+').map((s) => PROCESSING_STAGE_LABELS[s] || s).join(' y ');
+};
+
 // Video Validation Utilities
 const VIDEO_VALIDATIONS = {
   MAX_SIZE: 100 * 1024 * 1024, // 100MB in bytes
-  ALLOWED_TYPES: ['video/mp4', 'video/avi', 'video/mov', 'video/quicktime'],
-  ALLOWED_EXTENSIONS: ['.mp4', '.avi', '.mov']
+  ALLOWED_TYPES: ['video/mp4', 'video/mov', 'video/quicktime', 'video/webm', 'video/x-matroska'],
+  ALLOWED_EXTENSIONS: ['.mp4', '.mov', '.webm', '.mkv']
 };
 
 const validateVideoFile = (file) => {
@@ -669,7 +741,7 @@ const validateVideoFile = (file) => {
   const hasValidType = VIDEO_VALIDATIONS.ALLOWED_TYPES.includes(file.type);
 
   if (!hasValidExtension && !hasValidType) {
-    errors.push('Formato de archivo no válido. Solo se permiten archivos MP4, AVI y MOV');
+    errors.push('Formato de archivo no válido. Solo se permiten archivos MP4, MOV, WebM y MKV');
   }
 
   // Additional checks for file integrity
@@ -704,29 +776,104 @@ const getVideoURL = (processedUrl) => {
   return `${BASE_URL}${processedUrl}`;
 };
 
+// Con salida HLS, processed_url es un playlist (.m3u8) y fallback_url el MP4.
+// El navegador usa la primera fuente que soporta (Safari/móviles reproducen HLS nativo).
+const renderVideoSources = (video) => {
+  const url = video.processed_url || '';
+  if (!url.split('?')[0].endsWith('.m3u8')) {
+    return <source src={getVideoURL(url)} type="video/mp4" />;
+  }
+  return (
+    <>
+      <source src={getVideoURL(url)} type="application/vnd.apple.mpegurl" />
+      {video.fallback_url && <source src={getVideoURL(video.fallback_url)} type="video/mp4" />}
+    </>
+  );
+};
+
+// Miniatura del video y, al pasar el mouse, la vista previa animada (evita cargar el video en grillas)
+const renderVideoPoster = (video) => video.thumbnail_url && (
+  <>
+    <img src={getVideoURL(video.thumbnail_url)} alt="" loading="lazy" className="absolute inset-0 w-full h-full object-cover" />
+    {video.preview_url && (
+      <img src={getVideoURL(video.preview_url)} alt="" loading="lazy" className="absolute inset-0 w-full h-full object-cover opacity-0 group-hover:opacity-100 transition-opacity" />
+    )}
+  </>
+);
+
 class ApiService {
   constructor() {
     this.baseURL = BASE_URL;
     this.token = localStorage.getItem('access_token');
+    this.refreshToken = localStorage.getItem('refresh_token');
+    this.refreshing = null;
+  }
+
+  setSession(tokens) {
+    this.token = tokens.access_token;
+    this.refreshToken = tokens.refresh_token;
+    localStorage.setItem('access_token', tokens.access_token);
+    localStorage.setItem('refresh_token', tokens.refresh_token);
+  }
+
+  clearSession() {
+    this.token = null;
+    this.refreshToken = null;
+    localStorage.removeItem('access_token');
+    localStorage.removeItem('refresh_token');
+  }
+
+  // Rota el refresh token; las peticiones que fallan a la vez comparten la misma renovación
+  refreshSession() {
+    if (!this.refreshing) {
+      this.refreshing = fetch(`${this.baseURL}/api/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: this.refreshToken }),
+      })
+        .then(async (response) => {
+          if (!response.ok) {
+            this.clearSession();
+            return false;
+          }
+          this.setSession(await response.json());
+          return true;
+        })
+        .catch(() => false)
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
+  // fetch con el access token; si venció, lo renueva con el refresh token y reintenta una vez
+  async authorizedFetch(url, init = {}) {
+    const withToken = () => ({
+      ...init,
+      headers: this.token ? { ...init.headers, Authorization: `Bearer ${this.token}` } : init.headers,
+    });
+
+    let response = await fetch(url, withToken());
+    if (response.status === 401 && this.refreshToken && await this.refreshSession()) {
+      response = await fetch(url, withToken());
+    }
+    return response;
   }
 
   async request(endpoint, options = {}) {
     const url = `${this.baseURL}${endpoint}`;
 
     const config = {
+      ...options,
       headers: {
         'Content-Type': 'application/json',
         ...options.headers,
       },
-      ...options,
     };
 
-    if (this.token) {
-      config.headers.Authorization = `Bearer ${this.token}`;
-    }
-
     try {
-      const response = await fetch(url, config);
+      const response = await this.authorizedFetch(url, config);
 
       if (response.status === 204) {
         return {};
@@ -756,6 +903,7 @@ class ApiService {
         password2: userData.confirmPassword,
         city: userData.city,
         country: userData.country || 'Colombia',
+        role: userData.role || 'player',
       }),
     });
     return response;
@@ -771,8 +919,7 @@ class ApiService {
     });
 
     if (response.access_token) {
-      this.token = response.access_token;
-      localStorage.setItem('access_token', response.access_token);
+      this.setSession(response);
     }
 
     return response;
@@ -783,17 +930,40 @@ class ApiService {
     return response;
   }
 
+  // Verificación de correo y restablecimiento de contraseña con los tokens de los enlaces
+  async sendVerificationEmail() {
+    return this.request('/api/auth/verify-email/send', { method: 'POST' });
+  }
+
+  async verifyEmail(token) {
+    return this.request('/api/auth/verify-email', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
+  async forgotPassword(email) {
+    return this.request('/api/auth/password/forgot', {
+      method: 'POST',
+      body: JSON.stringify({ email }),
+    });
+  }
+
+  async resetPassword(token, password, confirmPassword) {
+    return this.request('/api/auth/password/reset', {
+      method: 'POST',
+      body: JSON.stringify({ token, password1: password, password2: confirmPassword }),
+    });
+  }
+
   async uploadVideo(title, file, isPublic = false) {
     const formData = new FormData();
     formData.append('title', title);
     formData.append('video_file', file);
     formData.append('is_public', isPublic.toString());
 
-    const response = await fetch(`${this.baseURL}/api/videos/upload`, {
+    const response = await this.authorizedFetch(`${this.baseURL}/api/videos/upload`, {
       method: 'POST',
-      headers: {
-        Authorization: `Bearer ${this.token}`,
-      },
       body: formData,
     });
 
@@ -809,13 +979,82 @@ class ApiService {
     return await this.request('/api/videos');
   }
 
+  async getVideoDetail(videoId) {
+    return await this.request(`/api/videos/${videoId}`);
+  }
+
+  // Lee un stream SSE con fetch, porque EventSource no envía el header Authorization.
+  // onEvent recibe el nombre del evento y su data en JSON.
+  async streamEvents(endpoint, onEvent, { signal, onOpen } = {}) {
+    const response = await this.authorizedFetch(`${this.baseURL}${endpoint}`, { signal });
+
+    if (!response.ok) {
+      throw new Error('Event stream failed');
+    }
+    if (onOpen) onOpen();
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = '';
+    while (true) {
+      const { value, done } = await reader.read();
+      if (done) return;
+
+      buffer += value;
+      let boundary;
+      while ((boundary = buffer.indexOf('\n\n')) >= 0) {
+        const message = buffer.slice(0, boundary);
+        buffer = buffer.slice(boundary + 2);
+
+        let event = 'message';
+        const data = [];
+        message.split('\n').forEach((line) => {
+          if (line.startsWith('event:')) event = line.slice(6).trim();
+          if (line.startsWith('data:')) data.push(line.slice(5).trim());
+        });
+        if (data.length > 0) onEvent(event, JSON.parse(data.join('\n')));
+      }
+    }
+  }
+
+  async streamVideoEvents(onEvent, options) {
+    return await this.streamEvents('/api/videos/events', (event, data) => onEvent(data), options);
+  }
+
+  async streamRankings(city, onEvent, options) {
+    const query = city && city !== 'todas' ? `?city=${encodeURIComponent(city)}` : '';
+    return await this.streamEvents(`/api/public/rankings/stream${query}`, onEvent, options);
+  }
+
   async getPublicVideos() {
     return await this.request('/api/public/videos');
   }
 
+  getDeviceId() {
+    let deviceId = localStorage.getItem('device_id');
+    if (!deviceId) {
+      deviceId = crypto.randomUUID();
+      localStorage.setItem('device_id', deviceId);
+    }
+    return deviceId;
+  }
+
   async voteVideo(videoId) {
     return await this.request(`/api/public/videos/${videoId}/vote`, {
       method: 'POST',
+      headers: { 'X-Device-ID': this.getDeviceId() },
+    });
+  }
+
+  async retractVote(videoId) {
+    return await this.request(`/api/public/videos/${videoId}/vote`, {
+      method: 'DELETE',
+    });
+  }
+
+  async reportVideo(videoId, reason, details = '') {
+    return await this.request(`/api/public/videos/${videoId}/report`, {
+      method: 'POST',
+      body: JSON.stringify({ reason, details }),
     });
   }
 
@@ -832,9 +1071,52 @@ class ApiService {
     return await this.request('/api/user/votes');
   }
 
+  async getUserStats() {
+    return await this.request('/api/user/stats');
+  }
+
+  async updateProfile(profile) {
+    return await this.request('/api/auth/profile', {
+      method: 'PUT',
+      body: JSON.stringify({
+        first_name: profile.firstName,
+        last_name: profile.lastName,
+        city: profile.city,
+        country: profile.country,
+      }),
+    });
+  }
+
+  async changePassword(currentPassword, password, confirmPassword) {
+    return await this.request('/api/auth/change-password', {
+      method: 'POST',
+      body: JSON.stringify({
+        current_password: currentPassword,
+        password1: password,
+        password2: confirmPassword,
+      }),
+    });
+  }
+
+  // Borra la cuenta y sus datos; la sesión deja de existir
+  async deleteAccount(password) {
+    const response = await this.request('/api/auth/account', {
+      method: 'DELETE',
+      body: JSON.stringify({ password }),
+    });
+    this.clearSession();
+    return response;
+  }
+
+  // Revoca la sesión en el servidor sin esperar la respuesta
   logout() {
-    this.token = null;
-    localStorage.removeItem('access_token');
+    if (this.token) {
+      fetch(`${this.baseURL}/api/auth/logout`, {
+        method: 'POST',
+        headers: { Authorization: `Bearer ${this.token}` },
+      }).catch(() => {});
+    }
+    this.clearSession();
   }
 
   isAuthenticated() {
@@ -856,6 +1138,8 @@ const App = () => {
   const [isPrivate, setIsPrivate] = useState(false);
   const [loading, setLoading] = useState(false);
   const [votedVideos, setVotedVideos] = useState(new Set());
+  const [accountNotice, setAccountNotice] = useState('');
+  const [resetToken, setResetToken] = useState(null);
   const [videoIsPublic, setVideoIsPublic] = useState(false);
 
   // Estados para upload de video
@@ -894,6 +1178,30 @@ const App = () => {
     checkAuth();
   }, []);
 
+  // Enlaces de los correos: ?verify_email=<token> y ?reset_password=<token>
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    const verifyToken = params.get('verify_email');
+    const passwordToken = params.get('reset_password');
+    if (!verifyToken && !passwordToken) {
+      return;
+    }
+    window.history.replaceState(null, '', window.location.pathname);
+
+    if (passwordToken) {
+      setResetToken(passwordToken);
+      setCurrentView('login');
+      return;
+    }
+
+    apiService.verifyEmail(verifyToken)
+      .then(() => {
+        setAccountNotice('Tu correo fue verificado');
+        setUser(prev => prev ? { ...prev, email_verified: true } : prev);
+      })
+      .catch(() => setAccountNotice('El enlace de verificación no es válido o ya venció'));
+  }, []);
+
   // Load data based on current view
   useEffect(() => {
     const loadData = async () => {
@@ -930,7 +1238,7 @@ const App = () => {
         if (user && (currentView === 'videos' || currentView === 'dashboard')) {
           try {
             const userVotes = await apiService.getUserVotes();
-            setVotedVideos(new Set(Array.isArray(userVotes) ? userVotes : []));
+            setVotedVideos(new Set((userVotes.votes || []).map(vote => vote.video_id)));
           } catch (error) {
             setVotedVideos(new Set());
           }
@@ -949,6 +1257,32 @@ const App = () => {
     }
   }, [currentView, selectedCity, user]);
 
+  // Ranking en vivo: el stream envía el ranking completo al conectar y luego solo los cambios
+  useEffect(() => {
+    if (currentView !== 'rankings') return;
+
+    const controller = new AbortController();
+    const listen = async () => {
+      while (!controller.signal.aborted) {
+        try {
+          await apiService.streamRankings(selectedCity, (event, data) => {
+            if (event === 'snapshot') {
+              setRankings(Array.isArray(data) ? data : []);
+            } else if (event === 'update') {
+              setRankings((current) => applyRankingUpdate(current, data));
+            }
+          }, { signal: controller.signal });
+        } catch (err) {
+          if (controller.signal.aborted) return;
+        }
+        await new Promise((resolve) => setTimeout(resolve, 3000));
+      }
+    };
+
+    listen();
+    return () => controller.abort();
+  }, [currentView, selectedCity]);
+
   // Componente de navegación principal
   const Navigation = () => (
     <nav className="bg-gradient-to-r from-orange-600 via-red-600 to-orange-600 text-white shadow-2xl sticky top-0 z-50">
@@ -977,13 +1311,15 @@ const App = () => {
                   <Home size={20} />
                   <span className="hidden md:inline">Inicio</span>
                 </button>
-                <button
-                  onClick={() => setCurrentView('upload')}
-                  className="hover:text-orange-200 transition-colors flex items-center space-x-1"
-                >
-                  <Upload size={20} />
-                  <span className="hidden md:inline">Subir</span>
-                </button>
+                {user.role !== 'voter' && (
+                  <button
+                    onClick={() => setCurrentView('upload')}
+                    className="hover:text-orange-200 transition-colors flex items-center space-x-1"
+                  >
+                    <Upload size={20} />
+                    <span className="hidden md:inline">Subir</span>
+                  </button>
+                )}
                 <button
                   onClick={() => setCurrentView('videos')}
                   className="hover:text-orange-200 transition-colors flex items-center space-x-1"
@@ -1140,15 +1476,43 @@ const App = () => {
       lastName: '',
       city: '',
       country: 'Colombia',
+      role: 'player',
       confirmPassword: ''
     });
 
+    const handleForgotPassword = async () => {
+      if (!formData.email) {
+        setError('Ingresa tu correo para restablecer la contraseña');
+        return;
+      }
+      setFormLoading(true);
+      setError('');
+      try {
+        await apiService.forgotPassword(formData.email);
+        setAccountNotice('Si el correo está registrado, te enviamos un enlace para restablecer la contraseña');
+      } catch (err) {
+        setError(err.message || 'An error occurred');
+      } finally {
+        setFormLoading(false);
+      }
+    };
+
     const handleSubmit = async () => {
       setFormLoading(true);
       setError('');
 
       try {
-        if (isLogin) {
+        if (resetToken) {
+          if (formData.password !== formData.confirmPassword) {
+            setError('Passwords do not match');
+            return;
+          }
+
+          await apiService.resetPassword(resetToken, formData.password, formData.confirmPassword);
+          setResetToken(null);
+          setFormData({ ...formData, password: '', confirmPassword: '' });
+          setAccountNotice('Contraseña actualizada, inicia sesión con la nueva contraseña');
+        } else if (isLogin) {
           await apiService.login(formData.email, formData.password);
           const profile = await apiService.getProfile();
           setUser(profile);
@@ -1177,7 +1541,7 @@ const App = () => {
         <div className="bg-white rounded-3xl shadow-2xl w-full max-w-md overflow-hidden">
           <div className="bg-gradient-to-r from-orange-500 to-red-500 p-6 text-white">
             <h2 className="text-3xl font-bold text-center">
-              {isLogin ? 'Bienvenido de vuelta' : 'Únete a Rising Stars'}
+              {resetToken ? 'Nueva contraseña' : (isLogin ? 'Bienvenido de vuelta' : 'Únete a Rising Stars')}
             </h2>
             <p className="text-center mt-2 opacity-90">
               {isLogin ? 'Ingresa para ver tu progreso' : 'Comienza tu camino al estrellato'}
@@ -1191,7 +1555,13 @@ const App = () => {
               </div>
             )}
 
-            {!isLogin && (
+            {accountNotice && !error && (
+              <div className="bg-green-50 border border-green-200 text-green-700 px-4 py-3 rounded-xl">
+                {accountNotice}
+              </div>
+            )}
+
+            {!isLogin && !resetToken && (
               <>
                 <div className="grid grid-cols-2 gap-3">
                   <input
@@ -1223,42 +1593,55 @@ const App = () => {
                     <option key={city} value={city}>{city}</option>
                   ))}
                 </select>
+
+                <select
+                  className="w-full p-3 border-2 rounded-xl focus:ring-2 focus:ring-orange-500 transition-all"
+                  value={formData.role}
+                  onChange={(e) => setFormData({ ...formData, role: e.target.value })}
+                >
+                  <option value="player">Quiero competir (subir videos y votar)</option>
+                  <option value="voter">Solo quiero votar</option>
+                </select>
               </>
             )}
 
-            <input
-              type="email"
-              placeholder="Correo electrónico *"
-              required
-              className="w-full p-3 border-2 rounded-xl focus:ring-2 focus:ring-orange-500 focus:border-transparent transition-all"
-              value={formData.email}
-              onChange={(e) => setFormData({ ...formData, email: e.target.value })}
-            />
+            {!resetToken && (
+              <input
+                type="email"
+                placeholder="Correo electrónico *"
+                required
+                className="w-full p-3 border-2 rounded-xl focus:ring-2 focus:ring-orange-500 focus:border-transparent transition-all"
+                value={formData.email}
+                onChange={(e) => setFormData({ ...formData, email: e.target.value })}
+              />
+            )}
 
             <input
               type="password"
-              placeholder="Contraseña *"
+              placeholder={resetToken ? 'Nueva contraseña *' : 'Contraseña *'}
               required
               className="w-full p-3 border-2 rounded-xl focus:ring-2 focus:ring-orange-500 focus:border-transparent transition-all"
               value={formData.password}
               onChange={(e) => setFormData({ ...formData, password: e.target.value })}
             />
 
-            {!isLogin && (
+            {(!isLogin || resetToken) && (
               <>
                 <input
                   type="password"
                   placeholder="Confirmar contraseña *"
-                  required={!isLogin}
+                  required
                   className="w-full p-3 border-2 rounded-xl focus:ring-2 focus:ring-orange-500 focus:border-transparent transition-all"
                   value={formData.confirmPassword}
                   onChange={(e) => setFormData({ ...formData, confirmPassword: e.target.value })}
                 />
 
-                <div className="flex items-start space-x-2 text-sm text-gray-600">
-                  <input type="checkbox" className="mt-1" />
-                  <p>Acepto los términos y condiciones y autorizo el uso de mi imagen para fines promocionales del torneo</p>
-                </div>
+                {!resetToken && (
+                  <div className="flex items-start space-x-2 text-sm text-gray-600">
+                    <input type="checkbox" className="mt-1" />
+                    <p>Acepto los términos y condiciones y autorizo el uso de mi imagen para fines promocionales del torneo</p>
+                  </div>
+                )}
               </>
             )}
 
@@ -1267,8 +1650,18 @@ const App = () => {
               disabled={formLoading}
               className="w-full bg-gradient-to-r from-orange-500 to-red-500 text-white py-3 rounded-xl font-bold shadow-lg transform transition-all hover:scale-105 hover:shadow-xl disabled:opacity-50 disabled:cursor-not-allowed"
             >
-              {formLoading ? 'Cargando...' : (isLogin ? 'Ingresar' : 'Crear Cuenta')}
+              {formLoading ? 'Cargando...' : (resetToken ? 'Cambiar Contraseña' : (isLogin ? 'Ingresar' : 'Crear Cuenta'))}
             </button>
+
+            {isLogin && !resetToken && (
+              <button
+                onClick={handleForgotPassword}
+                disabled={formLoading}
+                className="w-full text-sm text-orange-600 hover:underline disabled:opacity-50"
+              >
+                ¿Olvidaste tu contraseña?
+              </button>
+            )}
           </div>
 
           <div className="pb-6 text-center">
@@ -1353,11 +1746,14 @@ const App = () => {
                           <h4 className="text-lg font-semibold truncate">{video.title}</h4>
                           <span className={`px-3 py-1 rounded-full text-xs font-bold ${video.status === 'processed' ? 'bg-green-500' :
                             video.status === 'processing' ? 'bg-yellow-500' :
-                              video.status === 'uploaded' ? 'bg-blue-500' : 'bg-red-500'
+                              video.status === 'pending_review' ? 'bg-purple-500' :
+                                video.status === 'uploaded' ? 'bg-blue-500' : 'bg-red-500'
                             }`}>
                             {video.status === 'processed' ? 'COMPLETADO' :
                               video.status === 'processing' ? 'PROCESANDO' :
-                                video.status === 'uploaded' ? 'CARGADO' : 'ERROR'}
+                                video.status === 'pending_review' ? 'EN REVISIÓN' :
+                                  video.status === 'rejected' ? 'RECHAZADO' :
+                                    video.status === 'uploaded' ? 'CARGADO' : 'ERROR'}
                           </span>
                         </div>
                         <div className="flex items-center justify-between text-sm">
@@ -1602,6 +1998,8 @@ const App = () => {
         votes: player.votes,
         status: 'processed', // Asumimos que están procesados si están en ranking
         processed_url: videoUrl,
+        fallback_url: player.fallback_url,
+        thumbnail_url: player.thumbnail_url,
         video_id: player.video_id,
         position: rankings.findIndex(r => r.video_id === player.video_id) + 1,
         is_public: true // Los videos en ranking son públicos
@@ -1767,11 +2165,25 @@ const App = () => {
                   <span className="bg-blue-100 text-blue-700 px-3 py-1 rounded-full text-sm font-semibold">
                     {user?.country}
                   </span>
-                  <span className="bg-green-100 text-green-700 px-3 py-1 rounded-full text-sm font-semibold flex items-center">
-                    <CheckCircle size={14} className="mr-1" />
-                    Verificado
-                  </span>
+                  {user?.email_verified ? (
+                    <span className="bg-green-100 text-green-700 px-3 py-1 rounded-full text-sm font-semibold flex items-center">
+                      <CheckCircle size={14} className="mr-1" />
+                      Verificado
+                    </span>
+                  ) : (
+                    <button
+                      onClick={() => apiService.sendVerificationEmail()
+                        .then(() => setAccountNotice('Te enviamos un enlace para verificar tu correo'))
+                        .catch((err) => setAccountNotice(err.message || 'No se pudo enviar el correo de verificación'))}
+                      className="bg-yellow-100 text-yellow-700 px-3 py-1 rounded-full text-sm font-semibold hover:bg-yellow-200"
+                    >
+                      Verificar correo
+                    </button>
+                  )}
                 </div>
+                {accountNotice && (
+                  <p className="text-sm text-gray-600 mt-2">{accountNotice}</p>
+                )}
               </div>
             </div>
 
@@ -1827,6 +2239,28 @@ const App = () => {
                 )}
               </div>
 
+              <div className="border border-red-200 rounded-xl p-6">
+                <h3 className="text-xl font-bold mb-2 text-red-700">Eliminar cuenta</h3>
+                <p className="text-gray-600 mb-4">Se borrarán tu cuenta, tus videos y tus votos. Esta acción no se puede deshacer.</p>
+                <button
+                  onClick={async () => {
+                    const password = window.prompt('Ingresa tu contraseña para eliminar la cuenta');
+                    if (!password) return;
+                    try {
+                      await apiService.deleteAccount(password);
+                      setUser(null);
+                      setVotedVideos(new Set());
+                      setCurrentView('landing');
+                    } catch (err) {
+                      setAccountNotice(err.message.includes('Incorrect password') ? 'Contraseña incorrecta' : 'No se pudo eliminar la cuenta');
+                    }
+                  }}
+                  className="border-2 border-red-500 text-red-600 px-6 py-2 rounded-full font-bold hover:bg-red-50 transition-all"
+                >
+                  Eliminar mi cuenta
+                </button>
+              </div>
+
 
             </div>
           </div>
@@ -1897,6 +2331,7 @@ const App = () => {
                 isPlaying ? (
                   <video
                     className="w-full h-full object-cover"
+                    poster={video.thumbnail_url ? getVideoURL(video.thumbnail_url) : undefined}
                     controls
                     autoPlay
                     onEnded={() => setIsPlaying(false)}
@@ -1911,7 +2346,7 @@ const App = () => {
                     onLoadedData={() => {
                     }}
                   >
-                    <source src={getVideoURL(video.processed_url)} type="video/mp4" />
+                    {renderVideoSources(video)}
                     Tu navegador no soporta el elemento de video.
                   </video>
                 ) : (
@@ -1978,22 +2413,24 @@ const App = () => {
     const [videoError, setVideoError] = useState('');
 
     return (
-      <div className="bg-white rounded-xl shadow-lg overflow-hidden transform hover:scale-105 transition-all duration-300">
+      <div className="bg-white rounded-xl shadow-lg overflow-hidden transform hover:scale-105 transition-all duration-300 group">
         <div className="relative h-48 bg-gradient-to-br from-gray-800 to-gray-900 flex items-center justify-center">
           {video.status === 'processed' && video.processed_url ? (
             isPlaying ? (
               <video
                 className="w-full h-full object-cover"
+                poster={video.thumbnail_url ? getVideoURL(video.thumbnail_url) : undefined}
                 controls
                 autoPlay
                 onEnded={() => setIsPlaying(false)}
                 onError={() => setVideoError('Error al cargar el video')}
               >
-                <source src={getVideoURL(video.processed_url)} type="video/mp4" />
+                {renderVideoSources(video)}
                 Tu navegador no soporta el elemento de video.
               </video>
             ) : (
               <>
+                {renderVideoPoster(video)}
                 <div className="absolute inset-0 bg-black/30"></div>
                 <button
                   onClick={() => setIsPlaying(true)}
@@ -2021,11 +2458,14 @@ const App = () => {
           <div className="absolute top-2 right-2 flex space-x-2">
             <span className={`px-3 py-1 rounded-full text-xs font-bold ${video.status === 'processed' ? 'bg-green-500 text-white' :
               video.status === 'processing' ? 'bg-yellow-500 text-white' :
-                video.status === 'uploaded' ? 'bg-blue-500 text-white' : 'bg-red-500 text-white'
+                video.status === 'pending_review' ? 'bg-purple-500 text-white' :
+                  video.status === 'uploaded' ? 'bg-blue-500 text-white' : 'bg-red-500 text-white'
               }`}>
               {video.status === 'processed' ? 'COMPLETADO' :
                 video.status === 'processing' ? 'PROCESANDO' :
-                  video.status === 'uploaded' ? 'SUBIDO' : 'ERROR'}
+                  video.status === 'pending_review' ? 'EN REVISIÓN' :
+                    video.status === 'rejected' ? 'RECHAZADO' :
+                      video.status === 'uploaded' ? 'SUBIDO' : 'ERROR'}
             </span>
             {video.is_public && (
               <span className="bg-orange-500 text-white px-2 py-1 rounded-full text-xs font-bold">
@@ -2044,6 +2484,12 @@ const App = () => {
             </div>
           )}
 
+          {video.status === 'rejected' && video.rejection_reason && (
+            <div className="bg-red-50 border border-red-200 text-red-700 text-sm px-3 py-2 rounded-lg mb-3">
+              Rechazado por la moderación: {video.rejection_reason}
+            </div>
+          )}
+
           <div className="grid grid-cols-2 gap-4 text-sm mb-4">
             <div>
               <p className="text-gray-500">Votos recibidos:</p>
@@ -2061,12 +2507,15 @@ const App = () => {
             <div className="flex items-center space-x-2">
               <span className={`w-3 h-3 rounded-full ${video.status === 'processed' ? 'bg-green-500' :
                 video.status === 'processing' ? 'bg-yellow-500 animate-pulse' :
-                  'bg-red-500'
+                  video.status === 'pending_review' ? 'bg-purple-500' :
+                    'bg-red-500'
                 }`}></span>
               <span className="text-sm text-gray-600">
                 {video.status === 'processed' ?
                   (video.is_public ? 'Listo para votar' : 'Privado') :
-                  video.status === 'processing' ? 'En proceso...' : 'Error en procesamiento'}
+                  video.status === 'processing' ? 'En proceso...' :
+                    video.status === 'pending_review' ? 'Esperando aprobación' :
+                      video.status === 'rejected' ? 'Rechazado' : 'Error en procesamiento'}
               </span>
             </div>
 
@@ -2086,6 +2535,9 @@ const App = () => {
     const [voteError, setVoteError] = useState('');
     const [localVoteCount, setLocalVoteCount] = useState(video.votes || 0);
     const [isVoting, setIsVoting] = useState(false);
+    const [showReport, setShowReport] = useState(false);
+    const [reportReason, setReportReason] = useState('inappropriate');
+    const [reportMessage, setReportMessage] = useState('');
 
     // Actualizar estado de videos votados
     useEffect(() => {
@@ -2098,8 +2550,12 @@ const App = () => {
         return;
       }
 
-      if (voted || isVoting) {
-        setVoteError('Ya has votado por este video');
+      if (isVoting) {
+        return;
+      }
+
+      if (voted) {
+        await handleRetract();
         return;
       }
 
@@ -2126,7 +2582,13 @@ const App = () => {
         setVotedVideos(newVotedSet);
         setLocalVoteCount(prev => Math.max(0, prev - 1));
 
-        if (error.message.includes('400')) {
+        if (error.message.includes('No votes left')) {
+          setVoteError('No te quedan votos en esta ronda. Retira un voto para votar por otro video');
+        } else if (error.message.includes('Email not verified')) {
+          setVoteError('Verifica tu correo para poder votar');
+        } else if (error.message.includes('Too many votes')) {
+          setVoteError('Demasiados votos, intenta más tarde');
+        } else if (error.message.includes('already voted')) {
           setVoteError('Ya has votado por este video');
         } else if (error.message.includes('404')) {
           setVoteError('Video no encontrado');
@@ -2140,6 +2602,47 @@ const App = () => {
       }
     };
 
+    // Retirar el voto libera el cupo de la ronda para votar por otro video
+    const handleRetract = async () => {
+      setIsVoting(true);
+      try {
+        setVoteError('');
+        await apiService.retractVote(video.video_id);
+
+        setVoted(false);
+        const newVotedSet = new Set(votedVideos);
+        newVotedSet.delete(video.video_id);
+        setVotedVideos(newVotedSet);
+        setLocalVoteCount(prev => Math.max(0, prev - 1));
+      } catch (error) {
+        if (error.message.includes('Voting is closed')) {
+          setVoteError('La votación está cerrada');
+        } else {
+          setVoteError('Error al retirar el voto. Intenta de nuevo.');
+        }
+      } finally {
+        setIsVoting(false);
+      }
+    };
+
+    // Cada usuario denuncia un video una sola vez
+    const handleReport = async () => {
+      try {
+        await apiService.reportVideo(video.video_id, reportReason);
+        setReportMessage('Gracias, revisaremos el video');
+      } catch (error) {
+        if (error.message.includes('already reported')) {
+          setReportMessage('Ya denunciaste este video');
+        } else if (error.message.includes('own video')) {
+          setReportMessage('No puedes denunciar tu propio video');
+        } else {
+          setReportMessage('Error al enviar la denuncia. Intenta de nuevo.');
+        }
+      } finally {
+        setShowReport(false);
+      }
+    };
+
     return (
       <div className="bg-white rounded-xl shadow-lg overflow-hidden transform hover:scale-105 transition-all duration-300 group">
         <div className="relative h-48 bg-gradient-to-br from-gray-800 to-gray-900 flex items-center justify-center">
@@ -2147,15 +2650,17 @@ const App = () => {
             isPlaying ? (
               <video
                 className="w-full h-full object-cover"
+                poster={video.thumbnail_url ? getVideoURL(video.thumbnail_url) : undefined}
                 controls
                 autoPlay
                 onEnded={() => setIsPlaying(false)}
               >
-                <source src={getVideoURL(video.processed_url)} type="video/mp4" />
+                {renderVideoSources(video)}
                 Tu navegador no soporta el elemento de video.
               </video>
             ) : (
               <>
+                {renderVideoPoster(video)}
                 <div className="absolute inset-0 bg-black/30"></div>
                 <button
                   onClick={() => setIsPlaying(true)}
@@ -2208,7 +2713,8 @@ const App = () => {
               )}
               <button
                 onClick={handleVote}
-                disabled={voted || isVoting || video.status !== 'processed' || !user}
+                disabled={isVoting || video.status !== 'processed' || !user}
+                title={voted ? 'Retirar voto' : undefined}
                 className={`px-4 py-2 rounded-full font-semibold transition-all transform ${voted
                   ? 'bg-green-500 text-white'
                   : isVoting
@@ -2243,6 +2749,36 @@ const App = () => {
               </button>
             </div>
           </div>
+
+          {user && (
+            <div className="mt-3 pt-3 border-t border-gray-100 text-xs">
+              {showReport ? (
+                <div className="flex items-center gap-2">
+                  <select
+                    value={reportReason}
+                    onChange={(e) => setReportReason(e.target.value)}
+                    className="flex-1 border border-gray-200 rounded-lg px-2 py-1"
+                  >
+                    <option value="inappropriate">Contenido inapropiado</option>
+                    <option value="violence">Violencia</option>
+                    <option value="harassment">Acoso</option>
+                    <option value="spam">Spam</option>
+                    <option value="copyright">Derechos de autor</option>
+                    <option value="other">Otro</option>
+                  </select>
+                  <button onClick={handleReport} className="text-red-600 font-semibold">Enviar</button>
+                  <button onClick={() => setShowReport(false)} className="text-gray-500">Cancelar</button>
+                </div>
+              ) : reportMessage ? (
+                <span className="text-gray-500">{reportMessage}</span>
+              ) : (
+                <button onClick={() => setShowReport(true)} className="text-gray-400 hover:text-red-600 flex items-center">
+                  <Flag className="mr-1" size={12} />
+                  Denunciar
+                </button>
+              )}
+            </div>
+          )}
         </div>
       </div>
     );